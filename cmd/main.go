@@ -3,8 +3,12 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"net/http"
+	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -16,14 +20,20 @@ import (
 
 	"github.com/duynhne/auth-service/config"
 	database "github.com/duynhne/auth-service/internal/core"
+	"github.com/duynhne/auth-service/internal/core/notify"
 	"github.com/duynhne/auth-service/internal/core/repository"
 	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	logicv2 "github.com/duynhne/auth-service/internal/logic/v2"
 	webv1 "github.com/duynhne/auth-service/internal/web/v1"
+	webv2 "github.com/duynhne/auth-service/internal/web/v2"
 	"github.com/duynhne/auth-service/middleware"
 	"github.com/duynhne/pkg/logger/zerolog"
 )
 
 func main() {
+	check := flag.Bool("check", false, "run the startup self-test and exit (same as SELF_TEST=true)")
+	flag.Parse()
+
 	// Load configuration
 	cfg := config.Load()
 	if err := cfg.Validate(); err != nil {
@@ -32,6 +42,12 @@ func main() {
 
 	// Initialize Zerolog with LOG_LEVEL from config
 	zerolog.Setup(cfg.Logging.Level)
+	middleware.AddSensitiveKeys(cfg.Logging.RedactFields...)
+
+	// Self-test mode: verify dependencies and exit (deployment readiness gate)
+	if *check || cfg.SelfTest {
+		os.Exit(runSelfTest(cfg))
+	}
 
 	log.Info().
 		Str("service", cfg.Service.Name).
@@ -77,24 +93,234 @@ func main() {
 		log.Error().Err(err).Msg("Failed to connect to database")
 		return
 	}
-	// pool.Close() is called explicitly during graceful shutdown (step 2).
+	// pool.Close() is called explicitly during graceful shutdown (step 3).
 	log.Info().Msg("Database connection pool established")
 
+	// Repositories share a guarded pool: a saturated pool answers 503 instead of hanging
+	db := database.NewGuardedPool(pool, cfg.Database.AcquireTimeout)
+
 	// Wire dependencies: Core repositories -> Logic service -> Web handler
-	userRepo := repository.NewUserRepository(pool)
-	sessionRepo := repository.NewSessionRepository(pool)
-	authSvc := logicv1.NewAuthService(userRepo, sessionRepo)
-	handler := webv1.NewHandler(authSvc)
+	userRepo := repository.NewUserRepository(db, repository.UserRepositoryOptions{
+		CaseInsensitiveUsernames: !cfg.Username.CaseSensitive,
+	})
+	sessionRepo := repository.NewSessionRepository(db)
+	inviteRepo := repository.NewInviteRepository(db)
+	resetRepo := repository.NewPasswordResetRepository(db)
+	verificationRepo := repository.NewEmailVerificationRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	emailChangeRepo := repository.NewEmailChangeRepository(db)
+	totpRepo := repository.NewTOTPRepository(db)
+	backupCodeRepo := repository.NewBackupCodeRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	tokenIssuer := logicv1.NewTokenIssuer(cfg.Token.Secret, cfg.Token.TTL, cfg.Token.PreviousSecret)
+	authSvc := logicv1.NewAuthService(logicv1.Repositories{
+		Users:         userRepo,
+		Sessions:      sessionRepo,
+		Invites:       inviteRepo,
+		Resets:        resetRepo,
+		Verifications: verificationRepo,
+		Tokens:        userTokenRepo,
+		EmailChanges:  emailChangeRepo,
+		TOTP:          totpRepo,
+		BackupCodes:   backupCodeRepo,
+		RefreshTokens: refreshTokenRepo,
+	}, tokenIssuer, notify.NewLogNotifier(), logicv1.Options{
+		PasswordPolicy: logicv1.PasswordPolicy{
+			MinLength:          cfg.Password.MinLength,
+			MinUppercase:       cfg.Password.MinUppercase,
+			MinLowercase:       cfg.Password.MinLowercase,
+			MinDigits:          cfg.Password.MinDigits,
+			MinSymbols:         cfg.Password.MinSymbols,
+			RejectPersonalInfo: cfg.Password.RejectPersonalInfo,
+		},
+		PasswordHasher:                  logicv1.HashAlgorithm(strings.ToLower(cfg.Password.Hasher)),
+		BcryptCost:                      bcryptCost(cfg),
+		PasswordMaxAge:                  time.Duration(cfg.Password.MaxAgeDays) * 24 * time.Hour,
+		SessionBinding:                  logicv1.BindingMode(strings.ToLower(cfg.Session.Binding)),
+		SessionSubnetBinding:            cfg.Session.SubnetBinding,
+		SessionTouchInterval:            cfg.Session.TouchInterval,
+		LastLoginInterval:               cfg.Login.LastLoginInterval,
+		SessionIdleTimeout:              cfg.Session.IdleTimeout,
+		SessionLazyCleanup:              cfg.Session.LazyCleanup,
+		RegistrationMode:                logicv1.RegistrationMode(strings.ToLower(cfg.Registration.Mode)),
+		InviteTTL:                       cfg.Registration.InviteTTL,
+		PasswordResetTTL:                cfg.Password.ResetTTL,
+		PasswordResetNotify:             cfg.Password.ResetNotify,
+		PasswordResetBackupEmail:        cfg.Password.ResetBackupEmail,
+		EmailVerificationTTL:            cfg.Registration.VerificationTTL,
+		EmailVerificationResendInterval: cfg.Registration.VerificationResendInterval,
+		RegistrationDedupWindow:         cfg.Registration.DedupWindow,
+		SessionTTLMin:                   cfg.Token.MinTTL,
+		SessionTTLMax:                   cfg.Token.MaxTTL,
+		RefreshTokenTTL:                 refreshTokenTTL(cfg),
+		RefreshTokenBinding:             refreshTokenBinding(cfg),
+		LoginChecks:                     loginChecks(cfg.Login.Checks),
+		LockoutThreshold:                cfg.Lockout.Threshold,
+		LockoutDuration:                 cfg.Lockout.Duration,
+		LoginBackoffBase:                cfg.Login.BackoffBase,
+		LoginBackoffMax:                 cfg.Login.BackoffMax,
+		UsernameChangeInterval:          cfg.Username.ChangeInterval,
+		TOTPIssuer:                      cfg.TwoFactor.Issuer,
+		TOTPEncryptionKey:               twoFactorKey(cfg),
+	})
+	handler := webv1.NewHandler(authSvc, webv1.Options{
+		ResponseEnvelope:    cfg.Features().ResponseEnvelope,
+		RateLimit:           rateLimit(cfg),
+		RateLimitWindow:     cfg.RateLimit.Window,
+		UserRateLimit:       userRateLimit(cfg),
+		UserRateLimitWindow: cfg.RateLimit.UserWindow,
+		IntrospectionAPIKey: cfg.Token.IntrospectionAPIKey,
+
+		IntrospectionRateLimit:       introspectionRateLimit(cfg),
+		IntrospectionRateLimitWindow: cfg.RateLimit.IntrospectionWindow,
+	})
+	// v2 reshapes responses on top of the v1 service, sharing its repositories
+	handlerV2 := webv2.NewHandler(logicv2.NewAuthService(authSvc, tokenIssuer), webv2.Options{
+		RateLimit:       rateLimit(cfg),
+		RateLimitWindow: cfg.RateLimit.Window,
+	})
+
+	// Background jobs run until shutdown, and are stopped before the pool closes
+	stopJobs := startBackgroundJobs(cfg, authSvc)
 
 	// Setup router and server, then run with graceful shutdown
 	var isShuttingDown atomic.Bool
-	srv := setupServer(cfg, handler, &isShuttingDown)
-	runGracefulShutdown(cfg, srv, pool, tp, &isShuttingDown)
+	srv := setupServer(cfg, handler, handlerV2, newHealthChecker(pool, readyCheckTimeout), &isShuttingDown)
+	runGracefulShutdown(cfg, srv, pool, tp, &isShuttingDown, stopJobs)
+}
+
+// readyCheckTimeout bounds each dependency check of the readiness probe, below
+// the kubelet's default 1s probe timeout.
+const readyCheckTimeout = 800 * time.Millisecond
+
+// newHealthChecker registers the dependency checks shared by the readiness probe
+// and the startup self-test, each bounded by timeout.
+func newHealthChecker(pool *pgxpool.Pool, timeout time.Duration) *middleware.HealthChecker {
+	health := middleware.NewHealthChecker(timeout)
+	health.Register("database", func(ctx context.Context) error { return database.SelfCheck(ctx, pool) })
+	return health
+}
+
+// startBackgroundJobs starts the periodic maintenance jobs and returns a function
+// that stops them and waits for any run in progress to finish.
+func startBackgroundJobs(cfg *config.Config, authSvc *logicv1.AuthService) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	if cfg.Session.CleanupInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			authSvc.RunSessionCleanup(ctx, cfg.Session.CleanupInterval)
+		}()
+		log.Info().Dur("interval", cfg.Session.CleanupInterval).Msg("Session cleanup job started")
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// loginChecks converts the validated LOGIN_CHECKS names to logic-layer checks.
+// An empty list keeps logicv1.DefaultLoginChecks.
+func loginChecks(names []string) []logicv1.LoginCheck {
+	checks := make([]logicv1.LoginCheck, 0, len(names))
+	for _, name := range names {
+		checks = append(checks, logicv1.LoginCheck(strings.ToLower(name)))
+	}
+	return checks
+}
+
+// rateLimit returns the per-IP request budget, or 0 when the feature is switched off.
+func rateLimit(cfg *config.Config) int {
+	if !cfg.Features().RateLimit {
+		return 0
+	}
+	return cfg.RateLimit.Requests
+}
+
+// userRateLimit returns the per-user request budget, or 0 when it is switched off.
+func userRateLimit(cfg *config.Config) int {
+	if !cfg.Features().UserRateLimit {
+		return 0
+	}
+	return cfg.RateLimit.UserRequests
+}
+
+// introspectionRateLimit returns the per-API-key introspection budget, or 0 when
+// it is switched off.
+func introspectionRateLimit(cfg *config.Config) int {
+	if !cfg.Features().IntrospectionLimit {
+		return 0
+	}
+	return cfg.RateLimit.IntrospectionRequests
+}
+
+// refreshTokenTTL returns the refresh token lifetime, or 0 (no refresh tokens)
+// when the feature is switched off.
+func refreshTokenTTL(cfg *config.Config) time.Duration {
+	if !cfg.Features().RefreshTokens {
+		return 0
+	}
+	return cfg.Token.RefreshTTL
+}
+
+// refreshTokenBinding returns the refresh token device binding mode, or
+// logicv1.BindingOff when the feature is switched off.
+func refreshTokenBinding(cfg *config.Config) logicv1.BindingMode {
+	if !cfg.Features().RefreshTokenBinding {
+		return logicv1.BindingOff
+	}
+	return logicv1.BindingMode(strings.ToLower(cfg.Token.RefreshBinding))
+}
+
+// twoFactorKey returns the TOTP encryption key, or nil (2FA endpoints answer 501)
+// when the feature is switched off.
+func twoFactorKey(cfg *config.Config) []byte {
+	if !cfg.Features().TwoFactor {
+		return nil
+	}
+	return cfg.TwoFactor.Key()
+}
+
+// bcryptCost returns the bcrypt cost for new hashes: PASSWORD_BCRYPT_COST, or the
+// highest cost within PASSWORD_HASH_TARGET_LATENCY when calibration is enabled.
+func bcryptCost(cfg *config.Config) int {
+	if !cfg.Features().BcryptCalibration {
+		return cfg.Password.BcryptCost
+	}
+	cost, took := logicv1.CalibrateBcryptCost(cfg.Password.HashTargetLatency, cfg.Password.BcryptCost)
+	event := log.Info()
+	if took > cfg.Password.HashTargetLatency {
+		event = log.Warn()
+	}
+	event.
+		Int("cost", cost).
+		Int("min_cost", cfg.Password.BcryptCost).
+		Dur("hash_time", took).
+		Dur("target", cfg.Password.HashTargetLatency).
+		Msg("Bcrypt cost calibrated")
+	return cost
 }
 
 // setupServer creates and configures the HTTP server with all routes and middleware.
-func setupServer(cfg *config.Config, handler *webv1.Handler, isShuttingDown *atomic.Bool) *http.Server {
-	r := gin.Default()
+func setupServer(
+	cfg *config.Config, handler *webv1.Handler, handlerV2 *webv2.Handler,
+	health *middleware.HealthChecker, isShuttingDown *atomic.Bool,
+) *http.Server {
+	// No gin.Logger: it prints the raw query string, which carries single-use tokens
+	// (verify-email links); LoggingMiddleware logs every request with them redacted
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.HandleMethodNotAllowed = cfg.HTTP.MethodNotAllowed
+	r.RedirectTrailingSlash = cfg.HTTP.RedirectTrailingSlash
+
+	// Only trusted proxies may set the client IP; gin trusts every proxy by default,
+	// which would let clients pick a fresh rate limit bucket per request
+	if err := r.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
 
 	// Tracing middleware
 	r.Use(middleware.TracingMiddleware())
@@ -105,26 +331,56 @@ func setupServer(cfg *config.Config, handler *webv1.Handler, isShuttingDown *ato
 	// Prometheus middleware
 	r.Use(middleware.PrometheusMiddleware())
 
+	// Default Content-Type for responses written without one
+	r.Use(middleware.JSONContentTypeMiddleware())
+
+	// CORS for the browser frontend (answers preflights before routing)
+	if cfg.Features().CORS {
+		r.Use(middleware.CORSMiddleware(cfg.CORS))
+	}
+
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
 	// Readiness check
-	// Returns 503 once shutdown has started, to drain traffic before HTTP shutdown.
+	// Returns 503 once shutdown has started, to drain traffic before HTTP shutdown,
+	// and while a dependency check fails. Each probe updates the health_check_* gauges.
 	r.GET("/ready", func(c *gin.Context) {
 		if isShuttingDown.Load() {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		status, checks := http.StatusOK, gin.H{}
+		for dependency, result := range health.Run(c.Request.Context()) {
+			checks[dependency] = "ok"
+			if result.Err != nil {
+				log.Warn().Err(result.Err).Str("dependency", dependency).Msg("Health check failed")
+				status, checks[dependency] = http.StatusServiceUnavailable, "unavailable"
+			}
+		}
+		if status != http.StatusOK {
+			c.JSON(status, gin.H{"status": "unavailable", "checks": checks})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
 	})
 
-	// Metrics endpoint
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	// Metrics endpoint (optionally protected via METRICS_AUTH)
+	r.GET("/metrics", middleware.MetricsAuthMiddleware(cfg.Metrics), gin.WrapH(promhttp.Handler()))
 
-	// Auth v1 routes — Variant A edge naming (see api-naming-convention.md)
-	handler.RegisterRoutes(r)
+	// Structured responses for unmatched routes (same shape as API errors)
+	r.NoRoute(handler.NotFound)
+	r.NoMethod(handler.MethodNotAllowed)
+
+	// Auth v1 and v2 routes — Variant A edge naming (see api-naming-convention.md)
+	api := r.Group("")
+	if cfg.Features().ResponseDigest {
+		api.Use(middleware.ContentDigestMiddleware())
+	}
+	handler.RegisterRoutes(api)
+	handlerV2.RegisterRoutes(api)
 
 	// Create HTTP server with ReadHeaderTimeout to prevent Slowloris attacks
 	return &http.Server{
@@ -135,13 +391,14 @@ func setupServer(cfg *config.Config, handler *webv1.Handler, isShuttingDown *ato
 }
 
 // runGracefulShutdown starts the server and handles graceful shutdown.
-// Shutdown sequence (VictoriaMetrics pattern): /ready → 503 → drain delay → HTTP → Database → Tracer.
+// Shutdown sequence (VictoriaMetrics pattern): /ready → 503 → drain delay → HTTP → Jobs → Database → Tracer.
 func runGracefulShutdown(
 	cfg *config.Config,
 	srv *http.Server,
 	pool *pgxpool.Pool,
 	tp interface{ Shutdown(context.Context) error },
 	isShuttingDown *atomic.Bool,
+	stopJobs func(),
 ) {
 	// Start server in a goroutine
 	go func() {
@@ -184,13 +441,17 @@ func runGracefulShutdown(
 		log.Info().Msg("HTTP server shutdown complete")
 	}
 
-	// 2. Close database connection pool
+	// 2. Stop background jobs; canceling aborts any query in flight
+	stopJobs()
+	log.Info().Msg("Background jobs stopped")
+
+	// 3. Close database connection pool
 	if pool != nil {
 		pool.Close()
 		log.Info().Msg("Database connection pool closed")
 	}
 
-	// 3. Shutdown tracer
+	// 4. Shutdown tracer
 	if tp != nil {
 		if err := tp.Shutdown(shutdownCtx); err != nil {
 			log.Error().Err(err).Msg("Tracer shutdown error")
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/duynhne/auth-service/config"
+	database "github.com/duynhne/auth-service/internal/core"
+	"github.com/duynhne/auth-service/middleware"
+)
+
+// selfTestTimeout bounds the whole self-test so a hung dependency fails the gate.
+const selfTestTimeout = 30 * time.Second
+
+// runSelfTest verifies the service could start: configuration is already validated
+// by the caller, so this connects to the database and runs every check of the
+// readiness probe once.
+// Returns the process exit code (0 = ready, 1 = a dependency failed).
+func runSelfTest(cfg *config.Config) int {
+	ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+	defer cancel()
+
+	log.Info().Str("service", cfg.Service.Name).Msg("Self-test started")
+
+	pool, err := database.Connect(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("check", "database.connect").Msg("Self-test failed")
+		return 1
+	}
+	defer pool.Close()
+
+	return checkDependencies(ctx, newHealthChecker(pool, selfTestTimeout))
+}
+
+// checkDependencies runs the registered checks once and returns the exit code:
+// 0 when every check passes, 1 when any fails. Each failure is logged.
+func checkDependencies(ctx context.Context, health *middleware.HealthChecker) int {
+	code := 0
+	for dependency, result := range health.Run(ctx) {
+		if result.Err != nil {
+			log.Error().Err(result.Err).Str("check", dependency).Msg("Self-test failed")
+			code = 1
+			continue
+		}
+		log.Info().Str("check", dependency).Dur("duration", result.Duration).Msg("Self-test check passed")
+	}
+
+	if code == 0 {
+		log.Info().Msg("Self-test passed")
+	}
+	return code
+}
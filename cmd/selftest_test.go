@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/middleware"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	pass := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name     string
+		checks   map[string]middleware.HealthCheckFunc
+		wantCode int
+	}{
+		{"all pass", map[string]middleware.HealthCheckFunc{"database": pass, "mailer": pass}, 0},
+		{"one fails", map[string]middleware.HealthCheckFunc{"database": pass, "mailer": fail}, 1},
+		{"one hangs past the timeout", map[string]middleware.HealthCheckFunc{"database": hang}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := middleware.NewHealthChecker(50 * time.Millisecond)
+			for dependency, check := range tt.checks {
+				health.Register("selftest_"+dependency, check)
+			}
+			if code := checkDependencies(context.Background(), health); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -4,8 +4,11 @@
 // Configuration Sources (12-factor app principles):
 //  1. Default values (hardcoded)
 //  2. .env file (local development via godotenv)
-//  3. Environment variables (Kubernetes runtime)
-//  4. Helm values → deployment.yaml → env/extraEnv → container environment
+//  3. Config file (optional YAML/JSON via CONFIG_FILE)
+//  4. Environment variables (Kubernetes runtime)
+//  5. Helm values → deployment.yaml → env/extraEnv → container environment
+//
+// Precedence: env > .env > config file > defaults. Validate() runs on the merged result.
 //
 // Usage:
 //
@@ -21,13 +24,17 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/goccy/go-yaml"
 	"github.com/joho/godotenv"
 )
 
@@ -36,17 +43,34 @@ const defaultServiceName = "unknown"
 
 // Config holds all configuration for a microservice
 type Config struct {
-	Service         ServiceConfig   // Service-specific settings (port, name, version)
-	Tracing         TracingConfig   // OpenTelemetry/Tempo configuration
-	Profiling       ProfilingConfig // Pyroscope continuous profiling
-	Logging         LoggingConfig   // Structured logging (Zap)
-	Metrics         MetricsConfig   // Prometheus metrics
-	Database        DatabaseConfig  // PostgreSQL database configuration
-	ShutdownTimeout int             // Graceful shutdown timeout in seconds - from SHUTDOWN_TIMEOUT env (default: 10)
+	Service         ServiceConfig      // Service-specific settings (port, name, version)
+	Tracing         TracingConfig      // OpenTelemetry/Tempo configuration
+	Profiling       ProfilingConfig    // Pyroscope continuous profiling
+	Logging         LoggingConfig      // Structured logging (Zap)
+	Metrics         MetricsConfig      // Prometheus metrics
+	Database        DatabaseConfig     // PostgreSQL database configuration
+	Password        PasswordConfig     // Password policy (composition rules)
+	HTTP            HTTPConfig         // HTTP response behavior (opt-in extras)
+	CORS            CORSConfig         // Cross-origin access for the browser frontend
+	Session         SessionConfig      // Session lifetime and binding
+	Registration    RegistrationConfig // Self-service registration mode and invites
+	Token           TokenConfig        // Access token signing (HS256 JWT)
+	Lockout         LockoutConfig      // Account lockout after repeated failed logins
+	Login           LoginConfig        // Pre-session login checks
+	Username        UsernameConfig     // Username change rules
+	RateLimit       RateLimitConfig    // Per-IP rate limits on credential endpoints
+	TwoFactor       TwoFactorConfig    // TOTP two-factor authentication
+	ShutdownTimeout int                // Graceful shutdown timeout in seconds - from SHUTDOWN_TIMEOUT env (default: 10)
 	// ReadinessDrainDelay: delay after failing readiness before shutting down the HTTP server.
 	// This gives Kubernetes/Service routing time to stop sending new traffic.
 	// From READINESS_DRAIN_DELAY env (default: 5s, max: 30s).
 	ReadinessDrainDelay int
+	// SelfTest boots, checks dependencies, and exits instead of serving (init-container gate).
+	// From SELF_TEST env or the --check flag (default: false)
+	SelfTest bool
+
+	// configFileErr records a CONFIG_FILE read/parse failure, reported by Validate()
+	configFileErr error
 }
 
 // ServiceConfig defines basic service configuration
@@ -78,27 +102,259 @@ type ProfilingConfig struct {
 type LoggingConfig struct {
 	Level  string // Log level: debug, info, warn, error (default: "info") - from LOG_LEVEL env
 	Format string // Log format: json, console (default: "json") - from LOG_FORMAT env
+	// RedactFields lists extra field/header names whose values are never logged,
+	// on top of the built-in password/token/secret/Authorization set - from LOG_REDACT_FIELDS env (comma-separated)
+	RedactFields []string
 }
 
 // MetricsConfig defines Prometheus metrics configuration
 type MetricsConfig struct {
 	Enabled bool   // Enable metrics (default: true) - from METRICS_ENABLED env
 	Path    string // Metrics endpoint path (default: "/metrics") - from METRICS_PATH env
+	// Auth protects the metrics endpoint: off | token | basic (default: "off") - from METRICS_AUTH env
+	Auth      string
+	AuthToken string // Bearer token required when Auth=token - from METRICS_AUTH_TOKEN env
+	// AuthUsername/AuthPassword are required when Auth=basic - from METRICS_AUTH_USERNAME/METRICS_AUTH_PASSWORD env
+	AuthUsername string
+	// nolint:gosec // G117: This is a configuration field for the metrics scrape password
+	AuthPassword string
 }
 
 // DatabaseConfig defines PostgreSQL database configuration
 // All database connections use separate environment variables (not DATABASE_URL string)
 type DatabaseConfig struct {
-	Host           string // Database host - from DB_HOST env
-	Port           string // Database port - from DB_PORT env (default: "5432")
-	Name           string // Database name - from DB_NAME env
-	User           string // Database user - from DB_USER env
+	Host string // Database host - from DB_HOST env
+	Port string // Database port - from DB_PORT env (default: "5432")
+	Name string // Database name - from DB_NAME env
+	User string // Database user - from DB_USER env
 	// nolint:gosec // G117: This is a configuration field for the database password
 	Password       string // Database password - from DB_PASSWORD env
 	SSLMode        string // SSL mode - from DB_SSLMODE env (default: "disable")
 	MaxConnections int    // Max connections - from DB_POOL_MAX_CONNECTIONS env (default: 25)
 	PoolMode       string // Pool mode - from DB_POOL_MODE env (optional)
 	PoolerType     string // Pooler type - from DB_POOLER_TYPE env (optional)
+	// AcquireTimeout bounds the wait for a pooled connection; past it requests get 503
+	// From DB_POOL_ACQUIRE_TIMEOUT env (default: 2s, 0 = wait for the request deadline)
+	AcquireTimeout time.Duration
+}
+
+// PasswordConfig defines the password policy enforced on register/change/reset.
+// Composition rules are disabled (0) by default in favor of strength scoring;
+// enable them only for compliance regimes that mandate character classes.
+type PasswordConfig struct {
+	MinLength    int // Minimum length in characters (default: 8, 0 = off) - from PASSWORD_MIN_LENGTH env
+	MinUppercase int // Minimum uppercase letters (default: 0 = off) - from PASSWORD_MIN_UPPERCASE env
+	MinLowercase int // Minimum lowercase letters (default: 0 = off) - from PASSWORD_MIN_LOWERCASE env
+	MinDigits    int // Minimum digits (default: 0 = off) - from PASSWORD_MIN_DIGITS env
+	MinSymbols   int // Minimum symbols (default: 0 = off) - from PASSWORD_MIN_SYMBOLS env
+	// Hasher hashes newly set passwords: bcrypt | argon2id. Existing hashes keep working
+	// after a switch - from PASSWORD_HASHER env (default: "bcrypt")
+	Hasher string
+	// BcryptCost is the bcrypt cost for new hashes (4-31) - from PASSWORD_BCRYPT_COST env (default: 10)
+	// With HashTargetLatency set it is the floor that calibration never goes below
+	BcryptCost int
+	// HashTargetLatency calibrates the bcrypt cost at startup to the highest one whose
+	// hash fits this budget - from PASSWORD_HASH_TARGET_LATENCY env (default: 0 = off)
+	HashTargetLatency time.Duration
+	// RejectPersonalInfo rejects passwords containing the username or email local part
+	// From PASSWORD_REJECT_PERSONAL_INFO env (default: true)
+	RejectPersonalInfo bool
+	// MaxAgeDays forces a reset once a password is this old (default: 0 = never expires)
+	// Passwords set before age tracking never expire - from PASSWORD_MAX_AGE_DAYS env
+	MaxAgeDays int
+	ResetTTL   time.Duration // How long a reset token stays valid - from PASSWORD_RESET_TTL env (default: 1h)
+	// ResetNotify emails a "your password was changed" notice after a reset
+	// From PASSWORD_RESET_NOTIFY env (default: true)
+	ResetNotify bool
+	// ResetBackupEmail lets forgot-password send the reset link to a user's confirmed
+	// backup email - from PASSWORD_RESET_BACKUP_EMAIL env (default: true)
+	ResetBackupEmail bool
+}
+
+// HTTPConfig defines optional HTTP response behavior
+type HTTPConfig struct {
+	// ResponseDigest emits a Content-Digest (SHA-256) header on auth responses.
+	// Buffers the whole response body, so it is off by default - from RESPONSE_DIGEST_ENABLED env
+	ResponseDigest bool
+	// MethodNotAllowed answers a known path with the wrong method with 405 + Allow header
+	// instead of 404 - from HTTP_METHOD_NOT_ALLOWED env (default: true)
+	MethodNotAllowed bool
+	// RedirectTrailingSlash redirects /path/ to /path (and vice versa) when only the
+	// other form is routed - from HTTP_REDIRECT_TRAILING_SLASH env (default: true)
+	RedirectTrailingSlash bool
+	// ResponseEnvelope wraps API successes in {"data": ...} and errors in {"error": {...}}.
+	// Off by default for backward compatibility; callers of /auth/v1/private/me must
+	// unwrap "data" when it is on - from RESPONSE_ENVELOPE env (default: false)
+	ResponseEnvelope bool
+	// TrustedProxies lists the proxy IPs/CIDRs allowed to set the client IP via X-Forwarded-For
+	// or X-Real-IP; rate limits and subnet binding key on that IP. With none, the TCP peer is the client
+	// From TRUSTED_PROXIES env (comma-separated, default: none)
+	TrustedProxies []string
+}
+
+// CORSConfig defines which browser origins may call the API cross-origin.
+// CORS is off (no Access-Control-* headers) until AllowedOrigins is set.
+type CORSConfig struct {
+	// AllowedOrigins lists exact origins (scheme://host[:port]) allowed to call the API;
+	// "*" allows any origin but cannot be combined with AllowCredentials
+	// From CORS_ALLOWED_ORIGINS env (comma-separated, default: none = CORS disabled)
+	AllowedOrigins []string
+	// AllowedMethods is answered to preflights - from CORS_ALLOWED_METHODS env
+	// (comma-separated, default: GET,POST,PUT,DELETE,OPTIONS)
+	AllowedMethods []string
+	// AllowedHeaders is answered to preflights - from CORS_ALLOWED_HEADERS env
+	// (comma-separated, default: Authorization,Content-Type)
+	AllowedHeaders []string
+	// ExposedHeaders are readable by frontend scripts - from CORS_EXPOSED_HEADERS env
+	// (comma-separated, default: Retry-After and the X-RateLimit-* headers)
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and Authorization cross-origin
+	// From CORS_ALLOW_CREDENTIALS env (default: false)
+	AllowCredentials bool
+	MaxAge           time.Duration // How long browsers may cache a preflight - from CORS_MAX_AGE env (default: 10m)
+}
+
+// UsernameConfig defines username change rules
+type UsernameConfig struct {
+	// ChangeInterval is the minimum time between renames of one user; a released
+	// username also stays unavailable to others this long (0 disables both)
+	// From USERNAME_CHANGE_INTERVAL env (default: 720h)
+	ChangeInterval time.Duration
+	// CaseSensitive makes lookups (login, password reset) match the stored case exactly;
+	// when false "Alice" logs in as "alice". Either way the typed case is displayed, and
+	// names differing only in case cannot both be registered (V24)
+	// From USERNAME_CASE_SENSITIVE env (default: true)
+	CaseSensitive bool
+}
+
+// RateLimitConfig defines per-IP rate limiting of login, register and password reset.
+// Each endpoint has its own budget of Requests per Window; limits are per replica.
+// UserRequests is a separate budget per authenticated user, shared by every route
+// behind the auth middleware whatever IP the requests come from.
+// IntrospectionRequests is the much larger budget of each API key calling token
+// introspection; gateways send every request through it from a few IPs.
+type RateLimitConfig struct {
+	Requests int           // Requests allowed per window (0 disables) - from RATE_LIMIT_REQUESTS env (default: 10)
+	Window   time.Duration // Window the budget refills over - from RATE_LIMIT_WINDOW env (default: 1m)
+
+	UserRequests int           // Authenticated requests per user per window (0 disables) - from USER_RATE_LIMIT_REQUESTS env (default: 0)
+	UserWindow   time.Duration // Window the user budget refills over - from USER_RATE_LIMIT_WINDOW env (default: 1m)
+
+	IntrospectionRequests int           // Introspections per API key per window (0 disables) - from INTROSPECTION_RATE_LIMIT_REQUESTS env (default: 6000)
+	IntrospectionWindow   time.Duration // Window the API key budget refills over - from INTROSPECTION_RATE_LIMIT_WINDOW env (default: 1m)
+}
+
+// SessionConfig defines session security configuration
+type SessionConfig struct {
+	// Binding ties a session to the device it was issued to (X-Device-ID header).
+	// off: never checked | lax: reject only a mismatching device ID | strict: require a matching device ID
+	// strict requires services calling /auth/v1/private/me to forward X-Device-ID.
+	// From SESSION_BINDING env (default: "off")
+	Binding string
+	// TouchInterval throttles last_used_at writes to at most once per interval per session
+	// From SESSION_TOUCH_INTERVAL env (default: 1m)
+	TouchInterval time.Duration
+	// IdleTimeout expires sessions unused for this long, independent of absolute expiry
+	// From SESSION_IDLE_TIMEOUT env (default: 0 = disabled)
+	IdleTimeout time.Duration
+	// SubnetBinding rejects a session used outside the /24 (IPv4) or /64 (IPv6) it was issued from.
+	// High-security mode: mobile clients changing networks must log in again.
+	// The subnet comes from the client IP, so behind a gateway this only protects anything with
+	// TRUSTED_PROXIES set to exactly the gateway; otherwise X-Forwarded-For picks the subnet.
+	// From SESSION_SUBNET_BINDING env (default: false)
+	SubnetBinding bool
+	// CleanupInterval is how often expired sessions are deleted from the database
+	// From SESSION_CLEANUP_INTERVAL env (default: 10m, 0 = disabled)
+	CleanupInterval time.Duration
+	// LazyCleanup also deletes an expired or idle session when its token is presented,
+	// at the cost of one write per rejected token - from SESSION_LAZY_CLEANUP env (default: true)
+	LazyCleanup bool
+}
+
+// RegistrationConfig defines who may create an account
+type RegistrationConfig struct {
+	// Mode: open (anyone) | invite (single-use invite token required) | closed (403)
+	// From REGISTRATION_MODE env (default: "open")
+	Mode      string
+	InviteTTL time.Duration // How long an invite stays redeemable - from INVITE_TTL env (default: 168h)
+	// VerificationTTL is how long an email verification link stays valid
+	// From EMAIL_VERIFICATION_TTL env (default: 24h)
+	VerificationTTL time.Duration
+	// VerificationResendInterval is the minimum time between verification emails per user (0 disables)
+	// From EMAIL_VERIFICATION_RESEND_INTERVAL env (default: 60s)
+	VerificationResendInterval time.Duration
+	// DedupWindow replays a successful registration to identical resubmissions (0 disables)
+	// From REGISTRATION_DEDUP_WINDOW env (default: 10s)
+	DedupWindow time.Duration
+}
+
+// TokenConfig defines access token signing.
+// Tokens are HS256 JWTs; rotate by moving the current secret to JWT_PREVIOUS_SECRET
+// and setting a new JWT_SECRET, then drop the previous secret after one TTL.
+type TokenConfig struct {
+	// nolint:gosec // G117: This is a configuration field for the token signing secret
+	Secret string // HMAC signing secret, at least 32 bytes - from JWT_SECRET env (required)
+	// nolint:gosec // G117: This is a configuration field for the token signing secret
+	PreviousSecret string        // Verify-only secret during rotation - from JWT_PREVIOUS_SECRET env (optional)
+	TTL            time.Duration // Access token lifetime - from TOKEN_TTL env (default: 24h)
+	// MinTTL/MaxTTL clamp a lifetime requested by the client at login (expires_in)
+	// From TOKEN_TTL_MIN (default: 5m) and TOKEN_TTL_MAX (default: 720h) env
+	MinTTL time.Duration
+	MaxTTL time.Duration
+	// RefreshTTL is the lifetime of refresh tokens, independent of TTL; sessions last
+	// as long as their newest refresh token. From REFRESH_TOKEN_TTL env (default: 0 = disabled)
+	RefreshTTL time.Duration
+	// RefreshBinding ties refresh tokens to the device (X-Device-ID) they were issued to;
+	// a refresh from another device revokes the session. Same modes as SESSION_BINDING.
+	// From REFRESH_TOKEN_BINDING env (default: "off")
+	RefreshBinding string
+	// IntrospectionAPIKey lets gateways call /auth/v1/private/introspect with X-API-Key,
+	// at least 32 bytes - from INTROSPECTION_API_KEY env (optional; unset = admin tokens only)
+	// nolint:gosec // G117: This is a configuration field for the introspection API key
+	IntrospectionAPIKey string
+}
+
+// TwoFactorConfig defines TOTP two-factor authentication.
+type TwoFactorConfig struct {
+	// Enabled turns on 2FA enrollment; it requires TOTP_ENCRYPTION_KEY
+	// From TWO_FACTOR_ENABLED env (default: true when TOTP_ENCRYPTION_KEY is set)
+	Enabled bool
+	Issuer  string // Account label shown in authenticator apps - from TOTP_ISSUER env (default: auth-service)
+	// nolint:gosec // G117: This is a configuration field for the TOTP encryption key
+	EncryptionKey string // Base64 of a 32-byte AES-256 key for secrets at rest - from TOTP_ENCRYPTION_KEY env (optional)
+}
+
+// Key returns the decoded TOTP encryption key, or nil when none is configured.
+// Validate has already checked the format.
+func (t TwoFactorConfig) Key() []byte {
+	key, err := base64.StdEncoding.DecodeString(t.EncryptionKey)
+	if err != nil || len(key) == 0 {
+		return nil
+	}
+	return key
+}
+
+// LockoutConfig defines account lockout after repeated failed logins
+type LockoutConfig struct {
+	// Threshold is the number of consecutive bad passwords that locks the account
+	// From LOGIN_LOCKOUT_THRESHOLD env (default: 5, 0 = disabled)
+	Threshold int
+	Duration  time.Duration // How long a locked account stays locked - from LOGIN_LOCKOUT_DURATION env (default: 15m)
+}
+
+// LoginConfig defines the pre-session checks run on every login
+type LoginConfig struct {
+	// Checks lists the checks to run, in order: not_locked, password, password_age.
+	// password always runs, even if omitted - from LOGIN_CHECKS env (comma-separated, default: all in that order)
+	Checks []string
+	// BackoffBase is the first delay imposed on a username after repeated failed logins;
+	// it doubles with each further failure up to BackoffMax
+	// From LOGIN_BACKOFF_BASE env (default: 1s, 0 = disabled)
+	BackoffBase time.Duration
+	BackoffMax  time.Duration // Ceiling for the per-username delay - from LOGIN_BACKOFF_MAX env (default: 1m)
+	// LastLoginInterval throttles users.last_login writes to at most once per interval per user
+	// From LOGIN_LAST_LOGIN_INTERVAL env (default: 10s, 0 = write on every login)
+	LastLoginInterval time.Duration
 }
 
 // BuildDSN constructs PostgreSQL connection string from config
@@ -111,14 +367,18 @@ func (c *DatabaseConfig) BuildDSN() string {
 
 // Load reads configuration from environment variables with defaults
 // It automatically loads .env file if present (for local development)
+// and the optional CONFIG_FILE (YAML or JSON) for values not set in the environment.
 //
-// Priority: .env file < environment variables
-// This means ENV vars override .env file values (production takes precedence)
+// Priority: defaults < config file < .env file < environment variables
+// This means ENV vars override file values (production takes precedence)
 func Load() *Config {
 	// Load .env file if exists (for local development)
 	// godotenv.Load() fails silently if .env doesn't exist - perfect for production
 	_ = godotenv.Load()
 
+	// Load CONFIG_FILE if set; values only apply where the env var is unset
+	fileErr := loadConfigFile(os.Getenv("CONFIG_FILE"))
+
 	return &Config{
 		Service: ServiceConfig{
 			Name:    getEnv("SERVICE_NAME", defaultServiceName),
@@ -139,12 +399,17 @@ func Load() *Config {
 			ServiceName: getEnv("SERVICE_NAME", defaultServiceName),
 		},
 		Logging: LoggingConfig{
-			Level:  getEnv("LOG_LEVEL", "info"),
-			Format: getEnv("LOG_FORMAT", "json"),
+			Level:        getEnv("LOG_LEVEL", "info"),
+			Format:       getEnv("LOG_FORMAT", "json"),
+			RedactFields: getEnvList("LOG_REDACT_FIELDS", nil),
 		},
 		Metrics: MetricsConfig{
-			Enabled: getEnvBool("METRICS_ENABLED", true),
-			Path:    getEnv("METRICS_PATH", "/metrics"),
+			Enabled:      getEnvBool("METRICS_ENABLED", true),
+			Path:         getEnv("METRICS_PATH", "/metrics"),
+			Auth:         getEnv("METRICS_AUTH", "off"),
+			AuthToken:    getEnv("METRICS_AUTH_TOKEN", ""),
+			AuthUsername: getEnv("METRICS_AUTH_USERNAME", ""),
+			AuthPassword: getEnv("METRICS_AUTH_PASSWORD", ""),
 		},
 		Database: DatabaseConfig{
 			Host:           getEnv("DB_HOST", ""),
@@ -156,9 +421,97 @@ func Load() *Config {
 			MaxConnections: getEnvInt("DB_POOL_MAX_CONNECTIONS", 25),
 			PoolMode:       getEnv("DB_POOL_MODE", ""),
 			PoolerType:     getEnv("DB_POOLER_TYPE", ""),
+			AcquireTimeout: getEnvDuration("DB_POOL_ACQUIRE_TIMEOUT", 2*time.Second),
+		},
+		Password: PasswordConfig{
+			MinLength:          getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MinUppercase:       getEnvInt("PASSWORD_MIN_UPPERCASE", 0),
+			MinLowercase:       getEnvInt("PASSWORD_MIN_LOWERCASE", 0),
+			MinDigits:          getEnvInt("PASSWORD_MIN_DIGITS", 0),
+			MinSymbols:         getEnvInt("PASSWORD_MIN_SYMBOLS", 0),
+			RejectPersonalInfo: getEnvBool("PASSWORD_REJECT_PERSONAL_INFO", true),
+			MaxAgeDays:         getEnvInt("PASSWORD_MAX_AGE_DAYS", 0),
+			ResetTTL:           getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+			ResetNotify:        getEnvBool("PASSWORD_RESET_NOTIFY", true),
+			ResetBackupEmail:   getEnvBool("PASSWORD_RESET_BACKUP_EMAIL", true),
+			Hasher:             getEnv("PASSWORD_HASHER", "bcrypt"),
+			BcryptCost:         getEnvInt("PASSWORD_BCRYPT_COST", 10),
+			HashTargetLatency:  getEnvDuration("PASSWORD_HASH_TARGET_LATENCY", 0),
+		},
+		HTTP: HTTPConfig{
+			ResponseDigest:        getEnvBool("RESPONSE_DIGEST_ENABLED", false),
+			MethodNotAllowed:      getEnvBool("HTTP_METHOD_NOT_ALLOWED", true),
+			RedirectTrailingSlash: getEnvBool("HTTP_REDIRECT_TRAILING_SLASH", true),
+			ResponseEnvelope:      getEnvBool("RESPONSE_ENVELOPE", false),
+			TrustedProxies:        getEnvList("TRUSTED_PROXIES", nil),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS", nil),
+			AllowedMethods: getEnvList("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+			AllowedHeaders: getEnvList("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type"}),
+			ExposedHeaders: getEnvList("CORS_EXPOSED_HEADERS",
+				[]string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}),
+			AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           getEnvDuration("CORS_MAX_AGE", 10*time.Minute),
+		},
+		Session: SessionConfig{
+			Binding:         getEnv("SESSION_BINDING", "off"),
+			TouchInterval:   getEnvDuration("SESSION_TOUCH_INTERVAL", time.Minute),
+			IdleTimeout:     getEnvDuration("SESSION_IDLE_TIMEOUT", 0),
+			SubnetBinding:   getEnvBool("SESSION_SUBNET_BINDING", false),
+			CleanupInterval: getEnvDuration("SESSION_CLEANUP_INTERVAL", 10*time.Minute),
+			LazyCleanup:     getEnvBool("SESSION_LAZY_CLEANUP", true),
+		},
+		Registration: RegistrationConfig{
+			Mode:                       getEnv("REGISTRATION_MODE", "open"),
+			InviteTTL:                  getEnvDuration("INVITE_TTL", 7*24*time.Hour),
+			VerificationTTL:            getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			VerificationResendInterval: getEnvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+			DedupWindow:                getEnvDuration("REGISTRATION_DEDUP_WINDOW", 10*time.Second),
+		},
+		Token: TokenConfig{
+			Secret:              getEnv("JWT_SECRET", ""),
+			PreviousSecret:      getEnv("JWT_PREVIOUS_SECRET", ""),
+			TTL:                 getEnvDuration("TOKEN_TTL", 24*time.Hour),
+			MinTTL:              getEnvDuration("TOKEN_TTL_MIN", 5*time.Minute),
+			MaxTTL:              getEnvDuration("TOKEN_TTL_MAX", 30*24*time.Hour),
+			RefreshTTL:          getEnvDuration("REFRESH_TOKEN_TTL", 0),
+			RefreshBinding:      getEnv("REFRESH_TOKEN_BINDING", "off"),
+			IntrospectionAPIKey: getEnv("INTROSPECTION_API_KEY", ""),
+		},
+		Username: UsernameConfig{
+			ChangeInterval: getEnvDuration("USERNAME_CHANGE_INTERVAL", 30*24*time.Hour),
+			CaseSensitive:  getEnvBool("USERNAME_CASE_SENSITIVE", true),
+		},
+		RateLimit: RateLimitConfig{
+			Requests: getEnvInt("RATE_LIMIT_REQUESTS", 10),
+			Window:   getEnvDuration("RATE_LIMIT_WINDOW", time.Minute),
+
+			UserRequests: getEnvInt("USER_RATE_LIMIT_REQUESTS", 0),
+			UserWindow:   getEnvDuration("USER_RATE_LIMIT_WINDOW", time.Minute),
+
+			IntrospectionRequests: getEnvInt("INTROSPECTION_RATE_LIMIT_REQUESTS", 6000),
+			IntrospectionWindow:   getEnvDuration("INTROSPECTION_RATE_LIMIT_WINDOW", time.Minute),
+		},
+		TwoFactor: TwoFactorConfig{
+			Enabled:       getEnvBool("TWO_FACTOR_ENABLED", getEnv("TOTP_ENCRYPTION_KEY", "") != ""),
+			Issuer:        getEnv("TOTP_ISSUER", "auth-service"),
+			EncryptionKey: getEnv("TOTP_ENCRYPTION_KEY", ""),
+		},
+		Lockout: LockoutConfig{
+			Threshold: getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+			Duration:  getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
 		},
-		ShutdownTimeout: getEnvDurationSeconds("SHUTDOWN_TIMEOUT", 10),
+		Login: LoginConfig{
+			Checks:            getEnvList("LOGIN_CHECKS", nil),
+			BackoffBase:       getEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+			BackoffMax:        getEnvDuration("LOGIN_BACKOFF_MAX", time.Minute),
+			LastLoginInterval: getEnvDuration("LOGIN_LAST_LOGIN_INTERVAL", 10*time.Second),
+		},
+		ShutdownTimeout:     getEnvDurationSeconds("SHUTDOWN_TIMEOUT", 10),
 		ReadinessDrainDelay: getEnvDurationSecondsWithMax("READINESS_DRAIN_DELAY", 5, 30),
+		SelfTest:            getEnvBool("SELF_TEST", false),
+		configFileErr:       fileErr,
 	}
 }
 
@@ -167,11 +520,27 @@ func Load() *Config {
 func (c *Config) Validate() error {
 	var errs []string
 
+	if c.configFileErr != nil {
+		errs = append(errs, "CONFIG_FILE could not be loaded: "+c.configFileErr.Error())
+	}
 	errs = append(errs, c.validateService()...)
 	errs = append(errs, c.validateTracing()...)
 	errs = append(errs, c.validateProfiling()...)
 	errs = append(errs, c.validateLogging()...)
+	errs = append(errs, c.validateMetrics()...)
 	errs = append(errs, c.validateDatabase()...)
+	errs = append(errs, c.validatePassword()...)
+	errs = append(errs, c.validateHTTP()...)
+	errs = append(errs, c.validateCORS()...)
+	errs = append(errs, c.validateSession()...)
+	errs = append(errs, c.validateRegistration()...)
+	errs = append(errs, c.validateToken()...)
+	errs = append(errs, c.validateLockout()...)
+	errs = append(errs, c.validateLogin()...)
+	errs = append(errs, c.validateTwoFactor()...)
+	errs = append(errs, c.validateRateLimit()...)
+	errs = append(errs, c.validateUsername()...)
+	errs = append(errs, c.validateFeatures()...)
 
 	if len(errs) > 0 {
 		return fmt.Errorf("configuration validation failed:\n  - %s", strings.Join(errs, "\n  - "))
@@ -256,6 +625,28 @@ func (c *Config) validateLogging() []string {
 	return errs
 }
 
+// validateMetrics validates metrics configuration fields
+func (c *Config) validateMetrics() []string {
+	var errs []string
+
+	validAuthModes := []string{"off", "token", "basic"}
+	if !contains(validAuthModes, c.Metrics.Auth) {
+		errs = append(errs, fmt.Sprintf("METRICS_AUTH must be one of %v, got: %s", validAuthModes, c.Metrics.Auth))
+	}
+	switch strings.ToLower(c.Metrics.Auth) {
+	case "token":
+		if c.Metrics.AuthToken == "" {
+			errs = append(errs, "METRICS_AUTH_TOKEN is required when METRICS_AUTH=token")
+		}
+	case "basic":
+		if c.Metrics.AuthUsername == "" || c.Metrics.AuthPassword == "" {
+			errs = append(errs, "METRICS_AUTH_USERNAME and METRICS_AUTH_PASSWORD are required when METRICS_AUTH=basic")
+		}
+	}
+
+	return errs
+}
+
 // validateDatabase validates database configuration fields
 func (c *Config) validateDatabase() []string {
 	if c.Database.Host == "" {
@@ -278,6 +669,275 @@ func (c *Config) validateDatabase() []string {
 			errs = append(errs, "DB_PORT must be a valid number, got: "+c.Database.Port)
 		}
 	}
+	if c.Database.AcquireTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("DB_POOL_ACQUIRE_TIMEOUT must be >= 0, got: %s", c.Database.AcquireTimeout))
+	}
+
+	return errs
+}
+
+// validatePassword validates password policy configuration fields
+func (c *Config) validatePassword() []string {
+	var errs []string
+
+	rules := []struct {
+		env   string
+		value int
+	}{
+		{"PASSWORD_MIN_LENGTH", c.Password.MinLength},
+		{"PASSWORD_MIN_UPPERCASE", c.Password.MinUppercase},
+		{"PASSWORD_MIN_LOWERCASE", c.Password.MinLowercase},
+		{"PASSWORD_MIN_DIGITS", c.Password.MinDigits},
+		{"PASSWORD_MIN_SYMBOLS", c.Password.MinSymbols},
+		{"PASSWORD_MAX_AGE_DAYS", c.Password.MaxAgeDays},
+	}
+	for _, rule := range rules {
+		if rule.value < 0 {
+			errs = append(errs, fmt.Sprintf("%s must be >= 0, got: %d", rule.env, rule.value))
+		}
+	}
+	validHashers := []string{"bcrypt", "argon2id"}
+	if !contains(validHashers, c.Password.Hasher) {
+		errs = append(errs, fmt.Sprintf("PASSWORD_HASHER must be one of %v, got: %s", validHashers, c.Password.Hasher))
+	}
+	if c.Password.BcryptCost < 4 || c.Password.BcryptCost > 31 {
+		errs = append(errs, fmt.Sprintf("PASSWORD_BCRYPT_COST must be between 4 and 31, got: %d", c.Password.BcryptCost))
+	}
+	if c.Password.HashTargetLatency < 0 {
+		errs = append(errs, fmt.Sprintf("PASSWORD_HASH_TARGET_LATENCY must be >= 0, got: %s", c.Password.HashTargetLatency))
+	}
+	if c.Password.ResetTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("PASSWORD_RESET_TTL must be > 0, got: %s", c.Password.ResetTTL))
+	}
+
+	return errs
+}
+
+// validateSession validates session configuration fields
+func (c *Config) validateSession() []string {
+	var errs []string
+
+	validBindings := []string{"off", "lax", "strict"}
+	if !contains(validBindings, c.Session.Binding) {
+		errs = append(errs, fmt.Sprintf("SESSION_BINDING must be one of %v, got: %s", validBindings, c.Session.Binding))
+	}
+	if c.Session.TouchInterval < 0 {
+		errs = append(errs, fmt.Sprintf("SESSION_TOUCH_INTERVAL must be >= 0, got: %s", c.Session.TouchInterval))
+	}
+	if c.Session.IdleTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("SESSION_IDLE_TIMEOUT must be >= 0, got: %s", c.Session.IdleTimeout))
+	}
+	if c.Session.CleanupInterval < 0 {
+		errs = append(errs, fmt.Sprintf("SESSION_CLEANUP_INTERVAL must be >= 0, got: %s", c.Session.CleanupInterval))
+	}
+	// last_used_at lags real activity by up to TouchInterval, so a shorter idle
+	// timeout would expire sessions that are actively in use
+	if c.Session.IdleTimeout > 0 && c.Session.IdleTimeout <= c.Session.TouchInterval {
+		errs = append(errs, fmt.Sprintf("SESSION_IDLE_TIMEOUT (%s) must be greater than SESSION_TOUCH_INTERVAL (%s)",
+			c.Session.IdleTimeout, c.Session.TouchInterval))
+	}
+
+	return errs
+}
+
+// validateRegistration validates registration configuration fields
+func (c *Config) validateRegistration() []string {
+	var errs []string
+
+	validModes := []string{"open", "invite", "closed"}
+	if !contains(validModes, c.Registration.Mode) {
+		errs = append(errs, fmt.Sprintf("REGISTRATION_MODE must be one of %v, got: %s", validModes, c.Registration.Mode))
+	}
+	if c.Registration.InviteTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("INVITE_TTL must be > 0, got: %s", c.Registration.InviteTTL))
+	}
+	if c.Registration.VerificationTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("EMAIL_VERIFICATION_TTL must be > 0, got: %s", c.Registration.VerificationTTL))
+	}
+	if c.Registration.VerificationResendInterval < 0 {
+		errs = append(errs, fmt.Sprintf("EMAIL_VERIFICATION_RESEND_INTERVAL must be >= 0, got: %s",
+			c.Registration.VerificationResendInterval))
+	}
+	if c.Registration.DedupWindow < 0 {
+		errs = append(errs, fmt.Sprintf("REGISTRATION_DEDUP_WINDOW must be >= 0, got: %s", c.Registration.DedupWindow))
+	}
+
+	return errs
+}
+
+// validateUsername validates username change configuration fields
+func (c *Config) validateUsername() []string {
+	var errs []string
+
+	if c.Username.ChangeInterval < 0 {
+		errs = append(errs, fmt.Sprintf("USERNAME_CHANGE_INTERVAL must be >= 0, got: %s", c.Username.ChangeInterval))
+	}
+
+	return errs
+}
+
+// validateRateLimit validates rate limiting configuration fields
+func (c *Config) validateRateLimit() []string {
+	var errs []string
+
+	if c.RateLimit.Requests < 0 {
+		errs = append(errs, fmt.Sprintf("RATE_LIMIT_REQUESTS must be >= 0, got: %d", c.RateLimit.Requests))
+	}
+	if c.RateLimit.Requests > 0 && c.RateLimit.Window <= 0 {
+		errs = append(errs, fmt.Sprintf("RATE_LIMIT_WINDOW must be > 0 when rate limiting is enabled, got: %s",
+			c.RateLimit.Window))
+	}
+	if c.RateLimit.UserRequests < 0 {
+		errs = append(errs, fmt.Sprintf("USER_RATE_LIMIT_REQUESTS must be >= 0, got: %d", c.RateLimit.UserRequests))
+	}
+	if c.RateLimit.UserRequests > 0 && c.RateLimit.UserWindow <= 0 {
+		errs = append(errs, fmt.Sprintf("USER_RATE_LIMIT_WINDOW must be > 0 when the user rate limit is enabled, got: %s",
+			c.RateLimit.UserWindow))
+	}
+	if c.RateLimit.IntrospectionRequests < 0 {
+		errs = append(errs, fmt.Sprintf("INTROSPECTION_RATE_LIMIT_REQUESTS must be >= 0, got: %d",
+			c.RateLimit.IntrospectionRequests))
+	}
+	if c.RateLimit.IntrospectionRequests > 0 && c.RateLimit.IntrospectionWindow <= 0 {
+		errs = append(errs, fmt.Sprintf("INTROSPECTION_RATE_LIMIT_WINDOW must be > 0 when the introspection rate limit is enabled, got: %s",
+			c.RateLimit.IntrospectionWindow))
+	}
+
+	return errs
+}
+
+// validateTwoFactor validates TOTP two-factor configuration fields
+func (c *Config) validateTwoFactor() []string {
+	var errs []string
+
+	if c.TwoFactor.Issuer == "" || strings.Contains(c.TwoFactor.Issuer, ":") {
+		errs = append(errs, fmt.Sprintf("TOTP_ISSUER must be non-empty and must not contain ':', got: %q",
+			c.TwoFactor.Issuer))
+	}
+	if c.TwoFactor.EncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.TwoFactor.EncryptionKey)
+		if err != nil || len(key) != 32 {
+			errs = append(errs, "TOTP_ENCRYPTION_KEY must be the base64 encoding of exactly 32 bytes")
+		}
+	}
+
+	return errs
+}
+
+// minTokenSecretLen is the minimum JWT_SECRET length: HS256 needs a key at least
+// as long as its 256-bit output to keep full strength
+const minTokenSecretLen = 32
+
+// validateToken validates access token signing configuration fields
+func (c *Config) validateToken() []string {
+	var errs []string
+
+	if len(c.Token.Secret) < minTokenSecretLen {
+		errs = append(errs, fmt.Sprintf("JWT_SECRET is required and must be at least %d bytes", minTokenSecretLen))
+	}
+	if c.Token.PreviousSecret != "" && len(c.Token.PreviousSecret) < minTokenSecretLen {
+		errs = append(errs, fmt.Sprintf("JWT_PREVIOUS_SECRET must be at least %d bytes when set", minTokenSecretLen))
+	}
+	if c.Token.TTL <= 0 {
+		errs = append(errs, fmt.Sprintf("TOKEN_TTL must be > 0, got: %s", c.Token.TTL))
+	}
+	if c.Token.MinTTL <= 0 || c.Token.MaxTTL < c.Token.MinTTL {
+		errs = append(errs, fmt.Sprintf("TOKEN_TTL_MIN must be > 0 and <= TOKEN_TTL_MAX, got: %s and %s",
+			c.Token.MinTTL, c.Token.MaxTTL))
+	} else if c.Token.TTL < c.Token.MinTTL || c.Token.TTL > c.Token.MaxTTL {
+		errs = append(errs, fmt.Sprintf("TOKEN_TTL must be between TOKEN_TTL_MIN and TOKEN_TTL_MAX (%s-%s), got: %s",
+			c.Token.MinTTL, c.Token.MaxTTL, c.Token.TTL))
+	}
+	if c.Token.RefreshTTL < 0 || (c.Token.RefreshTTL > 0 && c.Token.RefreshTTL <= c.Token.TTL) {
+		errs = append(errs, fmt.Sprintf("REFRESH_TOKEN_TTL must be 0 (disabled) or greater than TOKEN_TTL (%s), got: %s",
+			c.Token.TTL, c.Token.RefreshTTL))
+	}
+	validBindings := []string{"off", "lax", "strict"}
+	if !contains(validBindings, c.Token.RefreshBinding) {
+		errs = append(errs, fmt.Sprintf("REFRESH_TOKEN_BINDING must be one of %v, got: %s",
+			validBindings, c.Token.RefreshBinding))
+	}
+	if c.Token.IntrospectionAPIKey != "" && len(c.Token.IntrospectionAPIKey) < minTokenSecretLen {
+		errs = append(errs, fmt.Sprintf("INTROSPECTION_API_KEY must be at least %d bytes when set", minTokenSecretLen))
+	}
+
+	return errs
+}
+
+// validateHTTP validates HTTP behavior configuration fields
+func (c *Config) validateHTTP() []string {
+	var errs []string
+
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			errs = append(errs, fmt.Sprintf("TRUSTED_PROXIES entries must be an IP or CIDR, got: %s", proxy))
+		}
+	}
+
+	return errs
+}
+
+// validateCORS validates cross-origin configuration fields
+func (c *Config) validateCORS() []string {
+	var errs []string
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				errs = append(errs, "CORS_ALLOWED_ORIGINS=* cannot be combined with CORS_ALLOW_CREDENTIALS=true")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.User != nil {
+			errs = append(errs, fmt.Sprintf("CORS_ALLOWED_ORIGINS entries must be scheme://host[:port] or *, got: %s", origin))
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, fmt.Sprintf("CORS_MAX_AGE must be >= 0, got: %s", c.CORS.MaxAge))
+	}
+
+	return errs
+}
+
+// validateLockout validates account lockout configuration fields
+func (c *Config) validateLockout() []string {
+	var errs []string
+
+	if c.Lockout.Threshold < 0 {
+		errs = append(errs, fmt.Sprintf("LOGIN_LOCKOUT_THRESHOLD must be >= 0, got: %d", c.Lockout.Threshold))
+	}
+	if c.Lockout.Threshold > 0 && c.Lockout.Duration <= 0 {
+		errs = append(errs, fmt.Sprintf("LOGIN_LOCKOUT_DURATION must be > 0 when lockout is enabled, got: %s", c.Lockout.Duration))
+	}
+
+	return errs
+}
+
+// validateLogin validates login check configuration fields
+func (c *Config) validateLogin() []string {
+	var errs []string
+
+	validChecks := []string{"not_locked", "password", "password_age"}
+	for _, check := range c.Login.Checks {
+		if !contains(validChecks, check) {
+			errs = append(errs, fmt.Sprintf("LOGIN_CHECKS entries must be one of %v, got: %s", validChecks, check))
+		}
+	}
+	if c.Login.BackoffBase < 0 {
+		errs = append(errs, fmt.Sprintf("LOGIN_BACKOFF_BASE must be >= 0, got: %s", c.Login.BackoffBase))
+	}
+	if c.Login.BackoffBase > 0 && c.Login.BackoffMax < c.Login.BackoffBase {
+		errs = append(errs, fmt.Sprintf("LOGIN_BACKOFF_MAX (%s) must be >= LOGIN_BACKOFF_BASE (%s)",
+			c.Login.BackoffMax, c.Login.BackoffBase))
+	}
+	if c.Login.LastLoginInterval < 0 {
+		errs = append(errs, fmt.Sprintf("LOGIN_LAST_LOGIN_INTERVAL must be >= 0, got: %s", c.Login.LastLoginInterval))
+	}
 
 	return errs
 }
@@ -296,6 +956,64 @@ func (c *Config) IsProduction() bool {
 
 // Helper functions for environment variable parsing
 
+// loadConfigFile reads a flat YAML or JSON document of ENV_NAME: value pairs
+// (e.g., LOG_LEVEL: debug) and exports each one that is not already set in the
+// environment, mirroring godotenv semantics so every env reader sees file values.
+// Lists of scalars are joined with commas, as list env vars expect; maps and nested
+// lists have no env representation and are rejected. An empty path disables
+// file-based configuration.
+func loadConfigFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is operator-supplied configuration
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+
+	// YAML is a superset of JSON, so one decoder handles both formats
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	for key, value := range raw {
+		key = strings.ToUpper(key)
+		if value == nil || os.Getenv(key) != "" {
+			continue
+		}
+		env, err := configFileValue(value)
+		if err != nil {
+			return fmt.Errorf("%s in %s: %w", key, path, err)
+		}
+		if err := os.Setenv(key, env); err != nil {
+			return fmt.Errorf("set %s from %s: %w", key, path, err)
+		}
+	}
+	return nil
+}
+
+// configFileValue renders a config file value as an env var string.
+func configFileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case nil, []any, map[string]any:
+				return "", fmt.Errorf("list items must be scalars, got %T", item)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", errors.New("nested maps are not supported; use a flat ENV_NAME: value pair")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
 // getEnv reads an environment variable with a default fallback
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -315,6 +1033,22 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return value == "true" || value == "1" || value == "yes"
 }
 
+// getEnvList reads a comma-separated environment variable with a default fallback
+// Empty items are dropped and surrounding whitespace is trimmed
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getEnvInt reads an integer environment variable with a default fallback
 // Returns default if parsing fails
 func getEnvInt(key string, defaultValue int) int {
@@ -374,6 +1108,20 @@ func getEnvDurationSeconds(key string, defaultValueSeconds int) int {
 	return seconds
 }
 
+// getEnvDuration reads a Go duration environment variable (e.g., "30s", "15m")
+// Returns default if parsing fails
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return duration
+}
+
 // GetShutdownTimeoutDuration returns shutdown timeout as time.Duration
 // Convenience method for use in main.go
 func (c *Config) GetShutdownTimeoutDuration() time.Duration {
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantEnv map[string]string
+		wantErr string
+	}{
+		{
+			name:    "scalars",
+			content: "LOG_LEVEL: debug\nRATE_LIMIT_REQUESTS: 20\nRESPONSE_ENVELOPE: true\n",
+			wantEnv: map[string]string{"LOG_LEVEL": "debug", "RATE_LIMIT_REQUESTS": "20", "RESPONSE_ENVELOPE": "true"},
+		},
+		{
+			name:    "list joined with commas",
+			content: "TRUSTED_PROXIES:\n  - 10.0.0.0/8\n  - 192.168.1.1\n",
+			wantEnv: map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,192.168.1.1"},
+		},
+		{
+			name:    "json list",
+			content: `{"CORS_ALLOWED_ORIGINS": ["https://a.example", "https://b.example"]}`,
+			wantEnv: map[string]string{"CORS_ALLOWED_ORIGINS": "https://a.example,https://b.example"},
+		},
+		{
+			name:    "map rejected",
+			content: "DATABASE:\n  HOST: localhost\n",
+			wantErr: "DATABASE",
+		},
+		{
+			name:    "nested list rejected",
+			content: "TRUSTED_PROXIES:\n  - [10.0.0.1]\n",
+			wantErr: "TRUSTED_PROXIES",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			for key := range tt.wantEnv {
+				t.Setenv(key, "")
+			}
+			if tt.wantErr != "" {
+				t.Setenv(tt.wantErr, "")
+			}
+
+			err := loadConfigFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadConfigFile() error = %v, want one naming %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadConfigFile() error = %v", err)
+			}
+			for key, want := range tt.wantEnv {
+				if got := os.Getenv(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileKeepsEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("LOG_LEVEL: debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_LEVEL", "warn")
+
+	if err := loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if got := os.Getenv("LOG_LEVEL"); got != "warn" {
+		t.Errorf("LOG_LEVEL = %q, want the environment value %q", got, "warn")
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import "strings"
+
+// Features is the resolved on/off state of every optional feature.
+// main.go wires optional components from it rather than re-deriving each
+// condition from raw settings, and validateFeatures rejects any feature that
+// is switched on without the settings it depends on.
+type Features struct {
+	TwoFactor            bool // TOTP 2FA enrollment and login codes (TWO_FACTOR_ENABLED)
+	InviteRegistration   bool // Registration requires an invite (REGISTRATION_MODE=invite)
+	RegistrationDedup    bool // Identical registrations replay the first result (REGISTRATION_DEDUP_WINDOW > 0)
+	BcryptCalibration    bool // Startup bcrypt cost calibration (PASSWORD_HASH_TARGET_LATENCY > 0, PASSWORD_HASHER=bcrypt)
+	PasswordResetNotify  bool // "Password changed" email after a reset (PASSWORD_RESET_NOTIFY)
+	BackupEmailRecovery  bool // Forgot-password accepts a confirmed backup email (PASSWORD_RESET_BACKUP_EMAIL)
+	AccountLockout       bool // Lock accounts after repeated failed logins (LOGIN_LOCKOUT_THRESHOLD > 0)
+	LoginBackoff         bool // Per-username delay after repeated failed logins (LOGIN_BACKOFF_BASE > 0)
+	RateLimit            bool // Per-IP limits on login, register and password reset (RATE_LIMIT_REQUESTS > 0)
+	UserRateLimit        bool // Per-user budget on authenticated routes (USER_RATE_LIMIT_REQUESTS > 0)
+	IntrospectionLimit   bool // Per-API-key budget on token introspection (INTROSPECTION_RATE_LIMIT_REQUESTS > 0)
+	SessionSubnetBinding bool // Sessions only valid from the issuing subnet (SESSION_SUBNET_BINDING)
+	RefreshTokens        bool // Rotating refresh tokens issued with every session (REFRESH_TOKEN_TTL > 0)
+	RefreshTokenBinding  bool // Refresh tokens only valid from the issuing device (REFRESH_TOKEN_BINDING != off)
+	CORS                 bool // Access-Control-* headers for allowlisted origins (CORS_ALLOWED_ORIGINS set)
+	ResponseEnvelope     bool // {"data": ...} response envelope (RESPONSE_ENVELOPE)
+	ResponseDigest       bool // Content-Digest response header (RESPONSE_DIGEST_ENABLED)
+}
+
+// Features returns which optional features this configuration enables.
+func (c *Config) Features() Features {
+	return Features{
+		TwoFactor:            c.TwoFactor.Enabled,
+		InviteRegistration:   strings.EqualFold(c.Registration.Mode, "invite"),
+		RegistrationDedup:    c.Registration.DedupWindow > 0,
+		BcryptCalibration:    c.Password.HashTargetLatency > 0 && strings.EqualFold(c.Password.Hasher, "bcrypt"),
+		PasswordResetNotify:  c.Password.ResetNotify,
+		BackupEmailRecovery:  c.Password.ResetBackupEmail,
+		AccountLockout:       c.Lockout.Threshold > 0,
+		LoginBackoff:         c.Login.BackoffBase > 0,
+		RateLimit:            c.RateLimit.Requests > 0,
+		UserRateLimit:        c.RateLimit.UserRequests > 0,
+		IntrospectionLimit:   c.RateLimit.IntrospectionRequests > 0,
+		SessionSubnetBinding: c.Session.SubnetBinding,
+		RefreshTokens:        c.Token.RefreshTTL > 0,
+		RefreshTokenBinding:  c.Token.RefreshTTL > 0 && !strings.EqualFold(c.Token.RefreshBinding, "off"),
+		CORS:                 len(c.CORS.AllowedOrigins) > 0,
+		ResponseEnvelope:     c.HTTP.ResponseEnvelope,
+		ResponseDigest:       c.HTTP.ResponseDigest,
+	}
+}
+
+// validateFeatures checks that every enabled feature has the settings it needs.
+// Value formats are checked by the per-section validators; this only covers
+// dependencies between a feature switch and its settings.
+func (c *Config) validateFeatures() []string {
+	var errs []string
+
+	if c.Features().TwoFactor && c.TwoFactor.EncryptionKey == "" {
+		errs = append(errs, "TWO_FACTOR_ENABLED=true requires TOTP_ENCRYPTION_KEY")
+	}
+
+	return errs
+}
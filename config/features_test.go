@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateFeatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name:    "two-factor without an encryption key",
+			cfg:     Config{TwoFactor: TwoFactorConfig{Enabled: true}},
+			wantErr: "TWO_FACTOR_ENABLED=true requires TOTP_ENCRYPTION_KEY",
+		},
+		{
+			name: "two-factor with an encryption key",
+			cfg:  Config{TwoFactor: TwoFactorConfig{Enabled: true, EncryptionKey: "a2V5"}},
+		},
+		{
+			name: "disabled feature needs no settings",
+			cfg:  Config{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.cfg.validateFeatures()
+			if tt.wantErr == "" {
+				if len(errs) != 0 {
+					t.Errorf("validateFeatures() = %v, want none", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0] != tt.wantErr {
+				t.Errorf("validateFeatures() = %v, want [%s]", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReportsFeatureErrors(t *testing.T) {
+	cfg := Config{TwoFactor: TwoFactorConfig{Enabled: true}}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "TWO_FACTOR_ENABLED=true requires TOTP_ENCRYPTION_KEY") {
+		t.Errorf("Validate() error = %v, want it to name the missing TOTP_ENCRYPTION_KEY", err)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	cfg := Config{
+		Registration: RegistrationConfig{Mode: "INVITE"},
+		Token:        TokenConfig{RefreshBinding: "strict"},
+	}
+	if f := cfg.Features(); !f.InviteRegistration || f.RefreshTokens || f.RefreshTokenBinding {
+		t.Errorf("Features() = %+v, want invite registration only", f)
+	}
+
+	// Refresh token binding only counts once refresh tokens are issued
+	cfg.Token.RefreshTTL = 24 * time.Hour
+	if f := cfg.Features(); !f.RefreshTokens || !f.RefreshTokenBinding {
+		t.Errorf("Features() = %+v, want refresh tokens with binding", f)
+	}
+}
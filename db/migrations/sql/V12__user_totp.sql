@@ -0,0 +1,10 @@
+-- V12__user_totp.sql
+-- TOTP (RFC 6238) two-factor secrets, one per user
+
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted BYTEA NOT NULL,            -- AES-256-GCM (nonce || ciphertext) of the raw secret
+    confirmed_at TIMESTAMP,                     -- NULL until the first code is verified; 2FA is inactive until then
+    last_used_step BIGINT NOT NULL DEFAULT 0,   -- last accepted time step, so a code cannot be replayed
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
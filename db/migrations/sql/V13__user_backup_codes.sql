@@ -0,0 +1,13 @@
+-- V13__user_backup_codes.sql
+-- Single-use 2FA recovery codes, replaced as a set on every (re)generation
+
+CREATE TABLE IF NOT EXISTS user_backup_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(255) NOT NULL,            -- bcrypt hash of the normalized code (raw code is never stored)
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_user_backup_codes_user ON user_backup_codes(user_id);
@@ -0,0 +1,14 @@
+-- V14__username_history.sql
+-- Previous usernames, used to rate-limit renames and to keep a released
+-- username from being claimed by someone else right away (impersonation)
+
+CREATE TABLE IF NOT EXISTS username_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_username VARCHAR(100) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_username_history_user ON username_history(user_id, changed_at);
+CREATE INDEX IF NOT EXISTS idx_username_history_old ON username_history(LOWER(old_username), changed_at);
@@ -0,0 +1,7 @@
+-- V15__session_public_id.sql
+-- Public, non-sequential session identifier exposed by the API (the serial id stays internal)
+
+-- The default also backfills existing sessions
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_public_id ON sessions(public_id);
@@ -0,0 +1,16 @@
+-- V16__email_changes.sql
+-- Pending email address changes, applied only once the new address is confirmed
+
+CREATE TABLE IF NOT EXISTS email_changes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,     -- SHA-256 hex of the confirmation token (raw token is never stored)
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_email_changes_user ON email_changes(user_id);
+CREATE INDEX IF NOT EXISTS idx_email_changes_expires ON email_changes(expires_at);
@@ -0,0 +1,11 @@
+-- V17__backup_email.sql
+-- Verified backup email for account recovery when the primary address is inaccessible
+
+-- Only set once the address is confirmed; NULL when the user has none
+ALTER TABLE users ADD COLUMN IF NOT EXISTS backup_email VARCHAR(255);
+
+-- A backup address must identify a single account in the forgot-password flow
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_backup_email ON users(backup_email) WHERE backup_email IS NOT NULL;
+
+-- Pending backup email confirmations share email_changes with primary email changes
+ALTER TABLE email_changes ADD COLUMN IF NOT EXISTS purpose VARCHAR(16) NOT NULL DEFAULT 'primary';
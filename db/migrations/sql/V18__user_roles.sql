@@ -0,0 +1,8 @@
+-- V18__user_roles.sql
+-- Role-based access control: every user has exactly one role
+
+-- Existing and newly registered users default to the least-privileged role
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'user';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
@@ -0,0 +1,8 @@
+-- V19__user_soft_delete.sql
+-- Soft delete: deleted accounts keep their row (and foreign keys) but can no longer sign in
+
+-- NULL for active accounts; set once when the account is deleted
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Lookups and listings only consider active accounts
+CREATE INDEX IF NOT EXISTS idx_users_active ON users(id) WHERE deleted_at IS NULL;
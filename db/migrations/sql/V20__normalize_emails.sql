@@ -0,0 +1,21 @@
+-- V20__normalize_emails.sql
+-- Emails are now stored lowercased (the service normalizes them on every write and lookup)
+
+-- Lowercase existing addresses, skipping any that would collide with another
+-- account that differs only in case. Such pairs keep their stored case, cannot
+-- be found by a (lowercased) email lookup, and must be merged by hand.
+UPDATE users u SET email = LOWER(u.email)
+WHERE u.email <> LOWER(u.email)
+  AND NOT EXISTS (SELECT 1 FROM users o WHERE o.id <> u.id AND LOWER(o.email) = LOWER(u.email));
+
+UPDATE users u SET backup_email = LOWER(u.backup_email)
+WHERE u.backup_email <> LOWER(u.backup_email)
+  AND NOT EXISTS (SELECT 1 FROM users o WHERE o.id <> u.id AND LOWER(o.backup_email) = LOWER(u.backup_email));
+
+UPDATE invites SET email = LOWER(email) WHERE email <> LOWER(email);
+
+-- Note: once no case-only duplicates remain, the existing unique constraint on
+-- email can be backed by a case-insensitive one:
+--   CREATE UNIQUE INDEX idx_users_email_lower ON users (LOWER(email));
+-- It is not created here because it would fail on databases that still hold
+-- such duplicates.
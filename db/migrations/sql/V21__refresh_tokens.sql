@@ -0,0 +1,14 @@
+-- V21__refresh_tokens.sql
+-- Single-use refresh tokens, rotated on every refresh and deleted with their session
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,     -- SHA-256 hex of the refresh token (raw token is never stored)
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,                          -- set when the token is rotated
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session ON refresh_tokens(session_id);
@@ -0,0 +1,5 @@
+-- V22__refresh_token_binding.sql
+-- Device a refresh token was issued to, checked on refresh (REFRESH_TOKEN_BINDING)
+
+-- SHA-256 hex of the X-Device-ID (raw identifier is never stored); NULL = unbound
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS binding VARCHAR(64);
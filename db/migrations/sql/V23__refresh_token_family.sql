@@ -0,0 +1,15 @@
+-- V23__refresh_token_family.sql
+-- Refresh token families: every token rotated from one login shares a family_id,
+-- so presenting an already-used token can revoke the whole chain
+
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID;
+
+-- Existing chains belong to one session each; reuse its public ID as their family
+UPDATE refresh_tokens rt SET family_id = s.public_id
+FROM sessions s
+WHERE rt.session_id = s.id AND rt.family_id IS NULL;
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
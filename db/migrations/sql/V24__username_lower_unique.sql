@@ -0,0 +1,28 @@
+-- V24__username_lower_unique.sql
+-- Usernames are unique regardless of case among active accounts, so "Alice" and
+-- "alice" can never both sign up. USERNAME_CASE_SENSITIVE now only decides
+-- whether a lookup must match the stored case.
+
+-- The index cannot be built while active accounts differ only in case. Fail
+-- with the offending names instead of an opaque duplicate key error; rename or
+-- delete one account of each pair, then rerun the migration.
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(name, ', ') INTO duplicates
+    FROM (
+        SELECT LOWER(username) AS name FROM users
+        WHERE deleted_at IS NULL
+        GROUP BY LOWER(username)
+        HAVING COUNT(*) > 1
+    ) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'usernames differing only in case: %', duplicates;
+    END IF;
+END $$;
+
+-- Soft-deleted accounts keep their row but release the name (in any case)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower
+    ON users (LOWER(username)) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- V25__refresh_token_expiry_index.sql
+-- Lets the session cleanup job delete expired refresh tokens without a full scan
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
@@ -0,0 +1,5 @@
+-- V3__session_binding.sql
+-- Bind sessions to the client (device) they were issued to
+
+-- SHA-256 hex of the client device identifier; NULL means the session is unbound
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS binding VARCHAR(64);
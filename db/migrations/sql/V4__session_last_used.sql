@@ -0,0 +1,4 @@
+-- V4__session_last_used.sql
+-- Track when a session was last used (throttled, updated at most once per interval)
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
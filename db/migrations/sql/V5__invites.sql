@@ -0,0 +1,15 @@
+-- V5__invites.sql
+-- Single-use registration invites (REGISTRATION_MODE=invite)
+
+CREATE TABLE IF NOT EXISTS invites (
+    id SERIAL PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,     -- SHA-256 hex of the invite token (raw token is never stored)
+    email VARCHAR(255),                         -- optional: restrict the invite to this email
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_invites_expires ON invites(expires_at);
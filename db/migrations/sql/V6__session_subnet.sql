@@ -0,0 +1,5 @@
+-- V6__session_subnet.sql
+-- Record the network the session was issued from (SESSION_SUBNET_BINDING)
+
+-- Issuing subnet in CIDR form (/24 for IPv4, /64 for IPv6); NULL means not recorded
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS subnet VARCHAR(64);
@@ -0,0 +1,7 @@
+-- V7__user_public_id.sql
+-- Public, non-sequential user identifier exposed by the API (the serial id stays internal)
+
+-- gen_random_uuid() is built in since PostgreSQL 13; the default also backfills existing rows
+ALTER TABLE users ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_public_id ON users(public_id);
@@ -0,0 +1,7 @@
+-- V8__account_lockout.sql
+-- Lock accounts after repeated failed logins (LOGIN_LOCKOUT_THRESHOLD / LOGIN_LOCKOUT_DURATION)
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- NULL means the account is not locked
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
@@ -0,0 +1,5 @@
+-- V9__password_changed_at.sql
+-- Track password age for PASSWORD_MAX_AGE_DAYS
+
+-- NULL means the password never expires (rows created before this migration)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP;
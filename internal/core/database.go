@@ -125,6 +125,16 @@ func GetPool() *pgxpool.Pool {
 	return globalPool
 }
 
+// SelfCheck runs a trivial query to prove the pool can execute statements,
+// not just open connections. Used by the startup self-test (--check / SELF_TEST=true).
+func SelfCheck(ctx context.Context, pool *pgxpool.Pool) error {
+	var one int
+	if err := pool.QueryRow(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("self-check query failed: %w", err)
+	}
+	return nil
+}
+
 // GetDB is an alias for GetPool() - provided for backward compatibility.
 //
 // Deprecated: Use GetPool() for new code.
@@ -0,0 +1,24 @@
+package domain
+
+import "context"
+
+// BackupCodeRow is one stored 2FA backup code.
+type BackupCodeRow struct {
+	ID       int
+	CodeHash string
+}
+
+// BackupCodeRepository defines the data-access contract for 2FA backup codes.
+// Implementations live in internal/core/repository (Core layer).
+// Only bcrypt hashes of backup codes are ever persisted.
+type BackupCodeRepository interface {
+	// Replace deletes every backup code of the user and stores codeHashes in one statement.
+	Replace(ctx context.Context, userID int, codeHashes []string) error
+
+	// ListUnused returns the user's backup codes that have not been used yet.
+	ListUnused(ctx context.Context, userID int) ([]BackupCodeRow, error)
+
+	// Consume marks an unused backup code as used.
+	// Returns false when it was already used (e.g., by a concurrent login).
+	Consume(ctx context.Context, id int) (bool, error)
+}
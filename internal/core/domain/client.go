@@ -0,0 +1,8 @@
+package domain
+
+// ClientInfo describes the client making a request, as resolved by the Web layer.
+// The Logic layer uses it for session binding decisions without touching HTTP.
+type ClientInfo struct {
+	DeviceID string // Opaque device identifier (X-Device-ID header, set from a device cookie)
+	IP       string // Client IP as resolved by the router
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// EmailChangePurpose says which address a pending email change replaces.
+type EmailChangePurpose string
+
+const (
+	// EmailChangePrimary replaces the user's primary email.
+	EmailChangePrimary EmailChangePurpose = "primary"
+	// EmailChangeBackup sets the user's backup (recovery) email.
+	EmailChangeBackup EmailChangePurpose = "backup"
+)
+
+// EmailChange is a confirmed request to move a user to a new email address.
+type EmailChange struct {
+	UserID   int
+	NewEmail string
+}
+
+// EmailChangeRepository defines the data-access contract for pending email changes.
+// Implementations live in internal/core/repository (Core layer).
+// Only the SHA-256 hash of a confirmation token is ever persisted.
+type EmailChangeRepository interface {
+	// Create stores a pending change to newEmail for the user, replacing any
+	// earlier unconfirmed change with the same purpose so only the latest link works.
+	Create(
+		ctx context.Context, userID int, purpose EmailChangePurpose, newEmail, tokenHash string, expiresAt time.Time,
+	) error
+
+	// Consume atomically marks an unused, unexpired confirmation token with the
+	// given purpose as used and returns its change. Returns (nil, nil) when no
+	// usable token matches.
+	Consume(ctx context.Context, purpose EmailChangePurpose, tokenHash string) (*EmailChange, error)
+}
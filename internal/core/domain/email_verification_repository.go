@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// EmailVerificationRepository defines the data-access contract for email verification tokens.
+// Implementations live in internal/core/repository (Core layer).
+// Only the SHA-256 hash of a verification token is ever persisted. Tokens are
+// issued through UserTokenRepository.ReplaceToken with TokenEmailVerification.
+type EmailVerificationRepository interface {
+	// Consume atomically marks an unused, unexpired verification token as used and
+	// returns its user ID. Returns (0, false, nil) when no usable token matches.
+	Consume(ctx context.Context, tokenHash string) (int, bool, error)
+
+	// LastCreatedAt returns when the user's most recent verification token was issued.
+	// Returns (zero, false, nil) when the user has none.
+	LastCreatedAt(ctx context.Context, userID int) (time.Time, bool, error)
+}
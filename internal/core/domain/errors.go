@@ -0,0 +1,8 @@
+package domain
+
+import "errors"
+
+// ErrServiceUnavailable indicates a dependency is temporarily saturated or down
+// (e.g., no database connection could be acquired in time). Core implementations
+// wrap it so the Logic and Web layers can answer 503 instead of 500.
+var ErrServiceUnavailable = errors.New("service temporarily unavailable")
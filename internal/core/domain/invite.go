@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// InviteRequest issues a registration invite, optionally restricted to one email.
+type InviteRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// InviteResponse returns the raw invite token. It is shown only once;
+// the server keeps just its hash.
+type InviteResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// InviteRepository defines the data-access contract for registration invites.
+// Implementations live in internal/core/repository (Core layer).
+// Only the SHA-256 hash of an invite token is ever persisted.
+type InviteRepository interface {
+	// Create stores a new invite. email may be empty for an unrestricted invite.
+	Create(ctx context.Context, tokenHash, email string, createdBy int, expiresAt time.Time) error
+
+	// Consume atomically marks an unused, unexpired invite as used.
+	// Invites restricted to an email only match that email.
+	// Returns false when no redeemable invite matches.
+	Consume(ctx context.Context, tokenHash, email string) (bool, error)
+
+	// Release marks a consumed invite as unused again, for a registration that
+	// failed after consuming it.
+	Release(ctx context.Context, tokenHash string) error
+}
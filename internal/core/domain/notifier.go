@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Notifier delivers out-of-band messages (e.g., email) to users.
+// Implementations live in internal/core/notify (Core layer); the Logic layer
+// depends on this interface only, so delivery channels can be swapped freely.
+type Notifier interface {
+	// SendPasswordReset delivers a password reset token to the given address.
+	SendPasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error
+
+	// SendEmailVerification delivers an email verification token to the given address.
+	SendEmailVerification(ctx context.Context, email, token string, expiresAt time.Time) error
+
+	// SendEmailChangeVerification delivers a token confirming a change to the new address email.
+	SendEmailChangeVerification(ctx context.Context, email, token string, expiresAt time.Time) error
+
+	// SendBackupEmailVerification delivers a token confirming email as the user's backup address.
+	SendBackupEmailVerification(ctx context.Context, email, token string, expiresAt time.Time) error
+
+	// SendPasswordChanged tells the owner of email that their password was changed at
+	// changedAt, with instructions to secure the account if they did not do it.
+	SendPasswordChanged(ctx context.Context, email string, changedAt time.Time) error
+}
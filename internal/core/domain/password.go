@@ -0,0 +1,9 @@
+package domain
+
+// ChangePasswordRequest rotates the authenticated user's password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`   // nolint:gosec // G117: This is a user password field
+	NewPassword     string `json:"new_password" binding:"required,min=6"` // nolint:gosec // G117: This is a user password field
+	// RevokeOtherSessions logs out every other device; the current session is kept
+	RevokeOtherSessions bool `json:"revoke_other_sessions"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+// ForgotPasswordRequest starts the password reset flow for an email address.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest completes the password reset flow with the emailed token.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"` // nolint:gosec // G117: This is a user password field
+}
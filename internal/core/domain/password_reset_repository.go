@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+// PasswordResetRepository defines the data-access contract for password reset tokens.
+// Implementations live in internal/core/repository (Core layer).
+// Only the SHA-256 hash of a reset token is ever persisted. Tokens are issued
+// through UserTokenRepository.ReplaceToken with TokenPasswordReset.
+type PasswordResetRepository interface {
+	// Lookup returns the user ID of an unused, unexpired reset token without
+	// using it. Returns (0, false, nil) when no usable token matches.
+	Lookup(ctx context.Context, tokenHash string) (int, bool, error)
+
+	// Consume atomically marks an unused, unexpired reset token as used and
+	// returns its user ID. Returns (0, false, nil) when no usable token matches.
+	Consume(ctx context.Context, tokenHash string) (int, bool, error)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// NewRefreshToken holds the fields persisted when a refresh token is issued.
+type NewRefreshToken struct {
+	SessionID string // public ID of the owning session
+	FamilyID  string // UUID shared by every token rotated from the same login
+	TokenHash string // SHA-256 hex of the refresh token
+	ExpiresAt time.Time
+	Binding   string // device ID hash; empty leaves the token unbound
+}
+
+// RefreshTokenRow is a stored refresh token.
+type RefreshTokenRow struct {
+	SessionID string // public ID of the owning session
+	FamilyID  string
+	Binding   string // device ID hash recorded at issuance; empty when unbound
+	Used      bool   // rotated (always true once returned by Consume); presenting it again signals theft
+}
+
+// RefreshTokenRepository defines the data-access contract for refresh tokens.
+// Implementations live in internal/core/repository (Core layer).
+// Only the SHA-256 hash of a refresh token is ever persisted; tokens belong to a
+// session and are deleted with it.
+type RefreshTokenRepository interface {
+	// Create stores a new refresh token for a session.
+	Create(ctx context.Context, token NewRefreshToken) error
+
+	// Consume atomically marks an unused, unexpired refresh token as used and
+	// returns it. Returns (nil, nil) when no usable token matches.
+	Consume(ctx context.Context, tokenHash string) (*RefreshTokenRow, error)
+
+	// GetByHash returns the refresh token with the given hash, used or not, so a
+	// replayed token can be told apart from an unknown one.
+	// Returns (nil, nil) when no token matches.
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshTokenRow, error)
+
+	// RevokeFamily deletes every session holding a token of the family, and with
+	// them every token of the family. Returns the number of sessions deleted.
+	RevokeFamily(ctx context.Context, familyID string) (int64, error)
+
+	// DeleteExpired deletes every refresh token past its expiry, used or not, and
+	// returns how many were deleted. Used tokens must stay until then: presenting
+	// one again is how reuse is detected.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,17 @@
+package domain
+
+// Role is a user's authorization level, stored in users.role.
+// Admin-only endpoints check it; every other endpoint only needs a valid session.
+type Role string
+
+const (
+	// RoleUser is the default role given to every registered user.
+	RoleUser Role = "user"
+	// RoleAdmin may use admin-only endpoints.
+	RoleAdmin Role = "admin"
+)
+
+// Valid reports whether r is a known role (matches the users_role_check constraint).
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// SessionInfo is the public view of a session, listed by /auth/v1/public/sessions.
+// It is an API contract: never add the token, jti or binding hash to this struct.
+type SessionInfo struct {
+	ID         string    `json:"id"` // public UUID; pass to DELETE /auth/v1/public/sessions/:id
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// Subnet is the network the session was issued from; empty unless SESSION_SUBNET_BINDING is on
+	Subnet string `json:"subnet,omitempty"`
+}
+
+// RefreshRequest exchanges a refresh token for a new access token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"` // nolint:gosec // G117: This is a refresh token field
+}
+
+// IntrospectRequest asks whether an access token is currently active (RFC 7662).
+// Accepted as JSON or as the RFC's form-encoded token parameter.
+type IntrospectRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// IntrospectionResponse describes an access token (RFC 7662 §2.2). An inactive
+// token carries only "active": false, never the reason.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub,omitempty"` // user public ID
+	Username  string `json:"username,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"` // Unix seconds
+}
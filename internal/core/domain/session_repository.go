@@ -8,20 +8,74 @@ import (
 // SessionRow represents a session joined with its owner user,
 // returned by session lookup queries.
 type SessionRow struct {
+	ID           int
+	PublicID     string // UUID exposed by the API; ID stays internal
+	UserID       int
+	UserPublicID string
+	Username     string
+	Email        string
+	// EmailVerified is the owner's users.email_verified
+	EmailVerified bool
+	Role          Role // the owner's users.role
+	ExpiresAt     time.Time
+	Binding       string // client fingerprint hash; empty when the session is unbound
+	Subnet        string // issuing subnet in CIDR form; empty when not recorded
+	// LastActiveAt is last_used_at, or created_at for a session that was never used
+	LastActiveAt time.Time
+}
+
+// NewSession holds the fields persisted when a session is created.
+type NewSession struct {
 	UserID    int
-	Username  string
-	Email     string
+	Token     string // session key: the access token's jti
 	ExpiresAt time.Time
+	Binding   string // client fingerprint hash; empty leaves the session unbound
+	Subnet    string // issuing subnet in CIDR form; empty leaves it unrecorded
 }
 
 // SessionRepository defines the data-access contract for session operations.
 // Implementations live in internal/core/repository (Core layer).
 type SessionRepository interface {
-	// Create inserts a new session for the given user.
-	Create(ctx context.Context, userID int, token string, expiresAt time.Time) error
+	// Create inserts a new session for the given user and returns its public ID.
+	Create(ctx context.Context, session NewSession) (string, error)
 
-	// GetUserByToken looks up the session by token and returns the associated
+	// GetUserByToken looks up the session by token (the access token's jti) and returns the associated
 	// user data together with the session expiry time.
 	// Returns (nil, nil) when the token does not match any session.
 	GetUserByToken(ctx context.Context, token string) (*SessionRow, error)
+
+	// GetByPublicID returns the session with the given public ID together with its owner.
+	// Returns (nil, nil) when no session matches.
+	GetByPublicID(ctx context.Context, publicID string) (*SessionRow, error)
+
+	// ListByUserID returns the user's unexpired sessions, newest first.
+	ListByUserID(ctx context.Context, userID int) ([]SessionInfo, error)
+
+	// DeleteByID deletes the session with the given ID.
+	DeleteByID(ctx context.Context, sessionID int) error
+
+	// DeleteByUserID deletes every session of the given user.
+	// Returns the number of sessions deleted.
+	DeleteByUserID(ctx context.Context, userID int) (int64, error)
+
+	// DeleteOthersByUserID deletes every session of the user except the one with keepToken (jti).
+	// Returns the number of sessions deleted.
+	DeleteOthersByUserID(ctx context.Context, userID int, keepToken string) (int64, error)
+
+	// DeleteExpired deletes every session past its expires_at.
+	// Returns the number of sessions deleted.
+	DeleteExpired(ctx context.Context) (int64, error)
+
+	// DeleteByToken deletes the session with the given token (jti).
+	// Deleting a token that has no session is not an error.
+	DeleteByToken(ctx context.Context, token string) error
+
+	// Rotate re-keys the session to a new access token (jti) and expiry after a
+	// refresh, marking it used now. The previous access token stops working.
+	Rotate(ctx context.Context, sessionID int, token string, expiresAt time.Time) error
+
+	// TouchLastUsed sets last_used_at to now unless it was already updated within
+	// minInterval, so hot sessions don't cause a write per request.
+	// Returns true when the row was updated.
+	TouchLastUsed(ctx context.Context, sessionID int, minInterval time.Duration) (bool, error)
 }
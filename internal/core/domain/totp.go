@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// TOTPRow is a user's TOTP enrollment as stored in user_totp.
+type TOTPRow struct {
+	UserID          int
+	SecretEncrypted []byte
+	ConfirmedAt     *time.Time // nil until enrollment is confirmed; 2FA is inactive until then
+	LastUsedStep    int64
+}
+
+// TOTPEnrollment is returned by /auth/v1/public/2fa/enroll.
+// Secret is shown once for manual entry; OTPAuthURI is the payload to render as a QR code.
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TOTPCodeRequest carries a 6-digit code from the user's authenticator app.
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
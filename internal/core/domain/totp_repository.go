@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+// TOTPRepository defines the data-access contract for TOTP two-factor secrets.
+// Implementations live in internal/core/repository (Core layer).
+// Secrets are encrypted by the Logic layer before they reach the repository.
+type TOTPRepository interface {
+	// GetByUserID returns the user's enrollment, or nil if there is none.
+	GetByUserID(ctx context.Context, userID int) (*TOTPRow, error)
+
+	// Upsert stores a new unconfirmed secret for the user, replacing any earlier
+	// unconfirmed one. Returns false without changes if 2FA is already confirmed.
+	Upsert(ctx context.Context, userID int, secretEncrypted []byte) (bool, error)
+
+	// Confirm activates the user's enrollment and records step as used.
+	Confirm(ctx context.Context, userID int, step int64) error
+
+	// UseStep records step as the last accepted code if it is newer than the stored one.
+	// Returns false when the step was already used (replay).
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+}
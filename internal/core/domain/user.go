@@ -1,24 +1,112 @@
 package domain
 
+import "time"
+
+// User is the public user representation serialized by /auth/me, login and register.
+// It is an API contract: never add credentials (password, hash, tokens) to this struct;
+// keep them on UserRow, which is never serialized.
+// ID is the public UUID (users.public_id), always serialized as a JSON string.
+// InternalID is the serial users.id for repository calls and is never serialized.
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password,omitempty"` // nolint:gosec // G117: This is a user password field
+	ID         string `json:"id"`
+	InternalID int    `json:"-"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	// EmailVerified lets the frontend gate features until the email link is followed
+	EmailVerified bool `json:"email_verified"`
+	// Role is the user's authorization level (user or admin)
+	Role Role `json:"role"`
 }
 
+// LoginRequest authenticates a user. Username accepts either the username or the
+// account's email address: values containing "@" are also matched against emails.
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required"` // username or email address
 	Password string `json:"password" binding:"required"` // nolint:gosec // G117: This is a user password field
+	// ExpiresIn requests a session lifetime in seconds (e.g., remember-me).
+	// Omitted uses TOKEN_TTL; other values are clamped to TOKEN_TTL_MIN..TOKEN_TTL_MAX.
+	ExpiresIn int `json:"expires_in,omitempty" binding:"omitempty,min=0"`
+	// TOTPCode is the authenticator code, required once the user has confirmed 2FA
+	TOTPCode string `json:"totp_code,omitempty" binding:"omitempty,len=6,numeric"`
+	// BackupCode is a single-use recovery code, accepted in place of TOTPCode
+	BackupCode string `json:"backup_code,omitempty" binding:"omitempty,max=32"`
 }
 
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"` // nolint:gosec // G117: This is a user password field
+	// InviteToken is required when REGISTRATION_MODE=invite
+	InviteToken string `json:"invite_token,omitempty"`
 }
 
+// ChangeUsernameRequest renames the authenticated user; the password is re-checked.
+type ChangeUsernameRequest struct {
+	Username        string `json:"username" binding:"required"`
+	CurrentPassword string `json:"current_password" binding:"required"` // nolint:gosec // G117: This is a user password field
+}
+
+// ChangeEmailRequest starts an email change for the authenticated user; the password is re-checked.
+// The new address takes effect only after it is confirmed.
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"` // nolint:gosec // G117: This is a user password field
+}
+
+// SetBackupEmailRequest registers a recovery address for the authenticated user; the password
+// is re-checked. The address is used only once it is confirmed.
+type SetBackupEmailRequest struct {
+	BackupEmail     string `json:"backup_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"` // nolint:gosec // G117: This is a user password field
+}
+
+// UserPage is one page of the admin user listing. Users never carry credentials.
+type UserPage struct {
+	Users  []User `json:"users"`
+	Total  int    `json:"total"` // number of users across all pages
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// UpdateProfileRequest changes the authenticated user's username and/or email in one
+// call; omitted fields are left unchanged and the password is re-checked.
+// A new email takes effect only after it is confirmed, as with ChangeEmailRequest.
+type UpdateProfileRequest struct {
+	Username        string `json:"username,omitempty"`
+	Email           string `json:"email,omitempty" binding:"omitempty,email"`
+	CurrentPassword string `json:"current_password" binding:"required"` // nolint:gosec // G117: This is a user password field
+}
+
+// UpdateProfileResponse is the profile after an update.
+type UpdateProfileResponse struct {
+	User User `json:"user"`
+	// EmailChangePending is true when a confirmation link was sent to the new email;
+	// User.Email keeps the old address until it is followed
+	EmailChangePending bool `json:"email_change_pending"`
+}
+
+// DeleteAccountRequest deletes the authenticated user's account; the password is re-checked.
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"` // nolint:gosec // G117: This is a user password field
+}
+
+// TokenTypeBearer is the token_type of every issued access token (RFC 6750).
+const TokenTypeBearer = "Bearer"
+
+// AuthResponse is returned by login, register and refresh. Token and RefreshToken
+// are the only secrets it may carry.
 type AuthResponse struct {
 	Token string `json:"token"`
-	User  User   `json:"user"`
+	// TokenType is always TokenTypeBearer
+	TokenType string `json:"token_type"`
+	// ExpiresAt is when Token expires (RFC 3339), so clients can refresh or
+	// re-authenticate ahead of time instead of waiting for a 401
+	ExpiresAt time.Time `json:"expires_at"`
+	// RefreshToken is exchanged once at /auth/v1/public/refresh for a new Token;
+	// empty when refresh tokens are disabled (REFRESH_TOKEN_TTL=0)
+	RefreshToken string `json:"refresh_token,omitempty"` // nolint:gosec // G117: This is a refresh token field
+	// SessionID is the public ID of the session behind Token, as listed by
+	// /auth/v1/public/sessions, so clients can revoke it without listing first
+	SessionID string `json:"session_id"`
+	User      User   `json:"user"`
 }
@@ -1,31 +1,128 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // UserRow represents a user record returned from the database.
 // It includes the password hash so the Logic layer can verify credentials.
 type UserRow struct {
-	ID           int
+	ID           int    // internal serial ID; never exposed outside the service
+	PublicID     string // public UUID used in API responses, tokens and path params
 	Username     string
 	Email        string
 	PasswordHash string
+	// FailedLoginAttempts counts consecutive bad passwords since the last success or lockout
+	FailedLoginAttempts int
+	LockedUntil         *time.Time // nil when the account is not locked
+	PasswordChangedAt   *time.Time // nil for legacy rows: the password never expires
+	EmailVerified       bool
+	// BackupEmail is the confirmed recovery address; empty when none is set
+	BackupEmail string
+	Role        Role
 }
 
 // UserRepository defines the data-access contract for user operations.
 // Implementations live in internal/core/repository (Core layer).
 // The Logic layer depends on this interface only — never on SQL or pgx directly.
+// Lookups, List and Count only see active users: soft-deleted rows are skipped.
 type UserRepository interface {
 	// GetByUsername returns the user matching the given username.
 	// Returns (nil, nil) when no user is found.
 	GetByUsername(ctx context.Context, username string) (*UserRow, error)
 
-	// ExistsByUsernameOrEmail returns true when a user with the given
-	// username or email already exists.
-	ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error)
+	// GetByEmail returns the user registered with the given email.
+	// Returns (nil, nil) when no user is found.
+	GetByEmail(ctx context.Context, email string) (*UserRow, error)
+
+	// GetByBackupEmail returns the user whose confirmed backup email is email.
+	// Returns (nil, nil) when no user is found.
+	GetByBackupEmail(ctx context.Context, email string) (*UserRow, error)
+
+	// GetByID returns the user with the given ID.
+	// Returns (nil, nil) when no user is found.
+	GetByID(ctx context.Context, id int) (*UserRow, error)
+
+	// GetByPublicID returns the user with the given public UUID.
+	// Returns (nil, nil) when no user is found.
+	GetByPublicID(ctx context.Context, publicID string) (*UserRow, error)
+
+	// List returns up to limit users ordered by ID, skipping the first offset.
+	List(ctx context.Context, limit, offset int) ([]UserRow, error)
+
+	// Count returns the total number of users.
+	Count(ctx context.Context) (int, error)
+
+	// ExistsByUsername returns true when a user with the given username already exists.
+	// Only the username column is checked, so a username never collides with an email.
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+
+	// ExistsByEmail returns true when a user with the given email already exists.
+	// Only the email column is checked, so an email never collides with a username.
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+
+	// Create inserts a new user and returns it with its generated IDs.
+	Create(ctx context.Context, username, email, passwordHash string) (*UserRow, error)
+
+	// SoftDelete marks the user deleted. The row is kept for foreign keys and
+	// history, but no lookup returns it afterwards.
+	SoftDelete(ctx context.Context, userID int) error
+
+	// Delete removes the user row and, by cascade, everything referencing it.
+	// It only undoes a registration that could not complete; accounts are
+	// otherwise soft-deleted.
+	Delete(ctx context.Context, userID int) error
+
+	// UpdateLastLogin sets last_login to now unless it was already updated within
+	// minInterval, so bursts of logins for one user don't cause a write each.
+	// It never waits on a row locked by a concurrent login; that login's write wins.
+	// Returns true when the row was updated.
+	UpdateLastLogin(ctx context.Context, userID int, minInterval time.Duration) (bool, error)
+
+	// UpdatePassword replaces the user's password hash and sets password_changed_at to now.
+	UpdatePassword(ctx context.Context, userID int, passwordHash string) error
+
+	// RehashPassword replaces oldHash with newHash, an encoding of the same password,
+	// without touching password_changed_at. Does nothing if the stored hash is no
+	// longer oldHash (the password was changed concurrently).
+	RehashPassword(ctx context.Context, userID int, oldHash, newHash string) error
+
+	// ChangeEmail sets a confirmed new email address and marks it verified.
+	// Returns false without changes when the email is already registered.
+	ChangeEmail(ctx context.Context, userID int, email string) (bool, error)
+
+	// SetBackupEmail sets a confirmed backup email for the user.
+	// Returns false without changes when another user already has it as backup email.
+	SetBackupEmail(ctx context.Context, userID int, email string) (bool, error)
+
+	// GetRole returns the user's role.
+	// Returns an empty Role when no user is found.
+	GetRole(ctx context.Context, userID int) (Role, error)
+
+	// SetRole changes the user's role.
+	SetRole(ctx context.Context, userID int, role Role) error
+
+	// ChangeUsername renames the user and records the old name in username_history.
+	// Returns false without changes when the username is already taken.
+	ChangeUsername(ctx context.Context, userID int, username string) (bool, error)
+
+	// LastUsernameChange returns when the user last changed their username.
+	// Returns (zero, false, nil) when they never did.
+	LastUsernameChange(ctx context.Context, userID int) (time.Time, bool, error)
+
+	// UsernameReleasedSince reports whether another user gave up username
+	// (case-insensitively) after since.
+	UsernameReleasedSince(ctx context.Context, username string, userID int, since time.Time) (bool, error)
+
+	// MarkEmailVerified sets email_verified to true for the given user.
+	MarkEmailVerified(ctx context.Context, userID int) error
 
-	// Create inserts a new user and returns the generated user ID.
-	Create(ctx context.Context, username, email, passwordHash string) (int, error)
+	// RecordFailedLogin atomically increments the failed-login counter. When it
+	// reaches threshold, the account is locked for lockout and the counter restarts.
+	// Returns true when this attempt locked the account.
+	RecordFailedLogin(ctx context.Context, userID, threshold int, lockout time.Duration) (bool, error)
 
-	// UpdateLastLogin sets the last_login timestamp to now for the given user.
-	UpdateLastLogin(ctx context.Context, userID int) error
+	// ResetFailedLogins clears the failed-login counter and any lock.
+	ResetFailedLogins(ctx context.Context, userID int) error
 }
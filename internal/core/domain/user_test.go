@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// forbiddenKeyFragments are substrings no serialized key of User or AuthResponse
+// may contain: they would mean a credential is leaking into API responses.
+var forbiddenKeyFragments = []string{"password", "hash", "secret", "salt", "totp", "backup_code"}
+
+// jsonKeys marshals v and returns every object key in the result, nested keys
+// prefixed with their parent ("user.id").
+func jsonKeys(t *testing.T, v any) []string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal %T: %v", v, err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal %T: %v", v, err)
+	}
+
+	var keys []string
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for key, value := range m {
+			keys = append(keys, prefix+key)
+			if nested, ok := value.(map[string]any); ok {
+				walk(prefix+key+".", nested)
+			}
+		}
+	}
+	walk("", decoded)
+	slices.Sort(keys)
+	return keys
+}
+
+// assertNoCredentialKeys fails for every key containing a forbidden fragment.
+func assertNoCredentialKeys(t *testing.T, keys []string) {
+	t.Helper()
+
+	for _, key := range keys {
+		for _, fragment := range forbiddenKeyFragments {
+			if strings.Contains(strings.ToLower(key), fragment) {
+				t.Errorf("serialized key %q looks like a credential (contains %q)", key, fragment)
+			}
+		}
+	}
+}
+
+func TestUserJSONContract(t *testing.T) {
+	user := User{
+		ID:            "0b8e6c1e-3f0a-4a57-9b7e-2d1c0f7e4a11",
+		InternalID:    42,
+		Username:      "alice",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Role:          RoleUser,
+	}
+
+	keys := jsonKeys(t, user)
+	want := []string{"email", "email_verified", "id", "role", "username"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("User JSON keys = %v, want %v", keys, want)
+	}
+	assertNoCredentialKeys(t, keys)
+}
+
+func TestAuthResponseJSONContract(t *testing.T) {
+	response := AuthResponse{
+		Token:        "access-token",
+		TokenType:    TokenTypeBearer,
+		ExpiresAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		RefreshToken: "refresh-token",
+		SessionID:    "5f1d7a2c-8c4e-4d0b-a6f3-9e2b1c7d8a40",
+		User:         User{ID: "0b8e6c1e-3f0a-4a57-9b7e-2d1c0f7e4a11", InternalID: 42, Username: "alice"},
+	}
+
+	keys := jsonKeys(t, response)
+	want := []string{
+		"expires_at", "refresh_token", "session_id", "token", "token_type", "user",
+		"user.email", "user.email_verified", "user.id", "user.role", "user.username",
+	}
+	if !slices.Equal(keys, want) {
+		t.Errorf("AuthResponse JSON keys = %v, want %v", keys, want)
+	}
+	assertNoCredentialKeys(t, keys)
+}
+
+func TestAuthResponseOmitsDisabledRefreshToken(t *testing.T) {
+	keys := jsonKeys(t, AuthResponse{Token: "access-token", TokenType: TokenTypeBearer})
+	if slices.Contains(keys, "refresh_token") {
+		t.Errorf("refresh_token serialized while empty: %v", keys)
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TokenPurpose names the flow a single-use user token belongs to.
+type TokenPurpose string
+
+const (
+	// TokenPasswordReset is a forgot-password reset token.
+	TokenPasswordReset TokenPurpose = "password_reset"
+	// TokenEmailVerification is an email ownership verification token.
+	TokenEmailVerification TokenPurpose = "email_verification"
+)
+
+// UserTokenRepository issues the single-use tokens of every purpose; they are
+// looked up and consumed through the repository of their flow.
+// Implementations live in internal/core/repository (Core layer).
+// Only the SHA-256 hash of a token is ever persisted.
+type UserTokenRepository interface {
+	// ReplaceToken stores a new token of purpose for the user and deletes the
+	// user's unused tokens of that purpose in the same operation, so repeated
+	// requests never leave more than the latest token valid.
+	ReplaceToken(ctx context.Context, userID int, purpose TokenPurpose, tokenHash string, expiresAt time.Time) error
+}
@@ -0,0 +1,70 @@
+// Package notify provides domain.Notifier implementations (Core layer).
+package notify
+
+import (
+	"context"
+	"time"
+
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+)
+
+// LogNotifier is a placeholder domain.Notifier that records each delivery
+// request in the log instead of sending it. It never logs the token itself,
+// so it is safe in any environment, but users receive nothing: wire a real
+// mailer before enabling the forgot-password flow for end users.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a new LogNotifier.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// SendPasswordReset logs that a password reset was requested for email.
+func (n *LogNotifier) SendPasswordReset(ctx context.Context, email, _ string, expiresAt time.Time) error {
+	pkgzerolog.FromContext(ctx).Info().
+		Str("notification", "password_reset").
+		Str("email", email).
+		Time("expires_at", expiresAt).
+		Msg("Notification not delivered: no mailer configured")
+	return nil
+}
+
+// SendPasswordChanged logs that a password-changed notice was requested for email.
+func (n *LogNotifier) SendPasswordChanged(ctx context.Context, email string, changedAt time.Time) error {
+	pkgzerolog.FromContext(ctx).Info().
+		Str("notification", "password_changed").
+		Str("email", email).
+		Time("changed_at", changedAt).
+		Msg("Notification not delivered: no mailer configured")
+	return nil
+}
+
+// SendEmailChangeVerification logs that an email change confirmation was requested for email.
+func (n *LogNotifier) SendEmailChangeVerification(ctx context.Context, email, _ string, expiresAt time.Time) error {
+	pkgzerolog.FromContext(ctx).Info().
+		Str("notification", "email_change_verification").
+		Str("email", email).
+		Time("expires_at", expiresAt).
+		Msg("Notification not delivered: no mailer configured")
+	return nil
+}
+
+// SendBackupEmailVerification logs that a backup email confirmation was requested for email.
+func (n *LogNotifier) SendBackupEmailVerification(ctx context.Context, email, _ string, expiresAt time.Time) error {
+	pkgzerolog.FromContext(ctx).Info().
+		Str("notification", "backup_email_verification").
+		Str("email", email).
+		Time("expires_at", expiresAt).
+		Msg("Notification not delivered: no mailer configured")
+	return nil
+}
+
+// SendEmailVerification logs that a verification email was requested for email.
+func (n *LogNotifier) SendEmailVerification(ctx context.Context, email, _ string, expiresAt time.Time) error {
+	pkgzerolog.FromContext(ctx).Info().
+		Str("notification", "email_verification").
+		Str("email", email).
+		Time("expires_at", expiresAt).
+		Msg("Notification not delivered: no mailer configured")
+	return nil
+}
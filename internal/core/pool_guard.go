@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// poolExhausted counts queries rejected because no connection could be acquired
+// within the acquire timeout.
+var poolExhausted = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "db_pool_exhausted_total",
+	Help: "Number of database operations rejected because the connection pool was exhausted",
+})
+
+// GuardedPool wraps a pgxpool.Pool so a saturated pool fails fast.
+//
+// pgxpool blocks an acquire until the caller's context ends, which turns a
+// saturated pool into slow, generic timeouts. GuardedPool bounds the wait to
+// acquireTimeout and then returns an error wrapping domain.ErrServiceUnavailable,
+// which the Web layer answers with 503 and Retry-After so load balancers back off.
+type GuardedPool struct {
+	pool           *pgxpool.Pool
+	acquireTimeout time.Duration
+}
+
+// NewGuardedPool wraps pool. An acquireTimeout <= 0 disables the guard.
+func NewGuardedPool(pool *pgxpool.Pool, acquireTimeout time.Duration) *GuardedPool {
+	return &GuardedPool{pool: pool, acquireTimeout: acquireTimeout}
+}
+
+// Exec acquires a connection, runs sql and releases the connection.
+func (p *GuardedPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer conn.Release()
+
+	return conn.Exec(ctx, sql, args...)
+}
+
+// Query acquires a connection and runs sql. The connection is released when
+// the rows are closed or fully read.
+func (p *GuardedPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+
+	return &guardedRows{Rows: rows, conn: conn}, nil
+}
+
+// QueryRow acquires a connection and runs sql. The connection is released by Scan.
+func (p *GuardedPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return errRow{err: err}
+	}
+
+	return &guardedRow{Row: conn.QueryRow(ctx, sql, args...), conn: conn}
+}
+
+// acquire gets a connection, waiting at most acquireTimeout.
+func (p *GuardedPool) acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	if p.acquireTimeout <= 0 {
+		return p.pool.Acquire(ctx)
+	}
+
+	acquireCtx, cancel := context.WithTimeout(ctx, p.acquireTimeout)
+	defer cancel()
+
+	conn, err := p.pool.Acquire(acquireCtx)
+	if err != nil {
+		// Only our own deadline means exhaustion; a cancelled request is not the pool's fault
+		if ctx.Err() == nil && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+			poolExhausted.Inc()
+			return nil, fmt.Errorf("acquire connection within %s: %w", p.acquireTimeout, domain.ErrServiceUnavailable)
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// guardedRow releases its connection once scanned.
+type guardedRow struct {
+	pgx.Row
+	conn *pgxpool.Conn
+}
+
+func (r *guardedRow) Scan(dest ...any) error {
+	defer r.conn.Release()
+	return r.Row.Scan(dest...)
+}
+
+// errRow is returned by QueryRow when no connection could be acquired.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+// guardedRows releases its connection when closed or exhausted.
+type guardedRows struct {
+	pgx.Rows
+	conn    *pgxpool.Conn
+	release sync.Once
+}
+
+func (r *guardedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+func (r *guardedRows) Close() {
+	r.Rows.Close()
+	r.release.Do(r.conn.Release)
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// newTinyPool returns a one-connection pool whose connections are served by
+// fakeServer, so the pool can be exhausted without a database.
+func newTinyPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	cfg, err := pgxpool.ParseConfig("postgres://test@localhost/test?sslmode=disable")
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	cfg.MaxConns = 1
+	cfg.ConnConfig.DialFunc = func(context.Context, string, string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go fakeServer(server)
+		return client, nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("new pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// fakeServer completes the startup handshake and then ignores every message
+// until the client hangs up.
+func fakeServer(conn net.Conn) {
+	defer conn.Close()
+
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: []byte{0, 0, 0, 1}})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+	for {
+		if _, err := backend.Receive(); err != nil {
+			return
+		}
+	}
+}
+
+func TestGuardedPoolExhausted(t *testing.T) {
+	const acquireTimeout = 50 * time.Millisecond
+
+	pool := newTinyPool(t)
+	guarded := NewGuardedPool(pool, acquireTimeout)
+
+	// Hold the only connection
+	held, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer held.Release()
+
+	before := testutil.ToFloat64(poolExhausted)
+	operations := map[string]func(ctx context.Context) error{
+		"exec": func(ctx context.Context) error {
+			_, err := guarded.Exec(ctx, "SELECT 1")
+			return err
+		},
+		"query": func(ctx context.Context) error {
+			_, err := guarded.Query(ctx, "SELECT 1")
+			return err
+		},
+		"query row": func(ctx context.Context) error {
+			var n int
+			return guarded.QueryRow(ctx, "SELECT 1").Scan(&n)
+		},
+	}
+	for name, op := range operations {
+		t.Run(name, func(t *testing.T) {
+			// The request deadline is far off: only the acquire timeout may end the wait
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			start := time.Now()
+			err := op(ctx)
+			if !errors.Is(err, domain.ErrServiceUnavailable) {
+				t.Fatalf("error = %v, want %v", err, domain.ErrServiceUnavailable)
+			}
+			if took := time.Since(start); took > 10*acquireTimeout {
+				t.Errorf("took %s, want about the acquire timeout (%s)", took, acquireTimeout)
+			}
+		})
+	}
+	if got := testutil.ToFloat64(poolExhausted) - before; got != float64(len(operations)) {
+		t.Errorf("db_pool_exhausted_total grew by %v, want %d", got, len(operations))
+	}
+}
+
+func TestGuardedPoolCancelledRequest(t *testing.T) {
+	pool := newTinyPool(t)
+	guarded := NewGuardedPool(pool, time.Minute)
+
+	held, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer held.Release()
+
+	before := testutil.ToFloat64(poolExhausted)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// A request that gives up first is not an exhausted pool
+	_, err = guarded.Exec(ctx, "SELECT 1")
+	if err == nil || errors.Is(err, domain.ErrServiceUnavailable) {
+		t.Errorf("error = %v, want the request's own deadline", err)
+	}
+	if got := testutil.ToFloat64(poolExhausted); got != before {
+		t.Errorf("db_pool_exhausted_total = %v, want unchanged %v", got, before)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// PgxBackupCodeRepository implements domain.BackupCodeRepository using pgxpool.
+type PgxBackupCodeRepository struct {
+	pool DB
+}
+
+// NewBackupCodeRepository creates a new PgxBackupCodeRepository.
+func NewBackupCodeRepository(pool DB) *PgxBackupCodeRepository {
+	return &PgxBackupCodeRepository{pool: pool}
+}
+
+// Replace deletes every backup code of the user and stores codeHashes.
+// The data-modifying CTE makes it one statement, so old codes never outlive
+// the new set and a failure leaves the old set intact.
+func (r *PgxBackupCodeRepository) Replace(ctx context.Context, userID int, codeHashes []string) error {
+	query := `
+		WITH deleted AS (DELETE FROM user_backup_codes WHERE user_id = $1)
+		INSERT INTO user_backup_codes (user_id, code_hash)
+		SELECT $1, unnest($2::text[])
+	`
+	_, err := r.pool.Exec(ctx, query, userID, codeHashes)
+	return err
+}
+
+// ListUnused returns the user's backup codes that have not been used yet.
+func (r *PgxBackupCodeRepository) ListUnused(ctx context.Context, userID int) ([]domain.BackupCodeRow, error) {
+	query := `SELECT id, code_hash FROM user_backup_codes WHERE user_id = $1 AND used_at IS NULL ORDER BY id`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []domain.BackupCodeRow
+	for rows.Next() {
+		var code domain.BackupCodeRow
+		if err := rows.Scan(&code.ID, &code.CodeHash); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+// Consume marks an unused backup code as used.
+// Returns false when it was already used (e.g., by a concurrent login).
+func (r *PgxBackupCodeRepository) Consume(ctx context.Context, id int) (bool, error) {
+	query := `UPDATE user_backup_codes SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// PgxEmailChangeRepository implements domain.EmailChangeRepository using pgxpool.
+type PgxEmailChangeRepository struct {
+	pool DB
+}
+
+// NewEmailChangeRepository creates a new PgxEmailChangeRepository.
+func NewEmailChangeRepository(pool DB) *PgxEmailChangeRepository {
+	return &PgxEmailChangeRepository{pool: pool}
+}
+
+// Create stores a pending change to newEmail for the user. Earlier unconfirmed
+// changes with the same purpose are deleted in the same statement so only the
+// latest link works.
+func (r *PgxEmailChangeRepository) Create(
+	ctx context.Context, userID int, purpose domain.EmailChangePurpose, newEmail, tokenHash string, expiresAt time.Time,
+) error {
+	query := `
+		WITH superseded AS (
+			DELETE FROM email_changes WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+		)
+		INSERT INTO email_changes (user_id, purpose, new_email, token_hash, expires_at) VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.pool.Exec(ctx, query, userID, string(purpose), newEmail, tokenHash, expiresAt)
+	return err
+}
+
+// Consume atomically marks an unused, unexpired confirmation token with the given
+// purpose as used and returns its change. Returns (nil, nil) when no usable token matches.
+func (r *PgxEmailChangeRepository) Consume(
+	ctx context.Context, purpose domain.EmailChangePurpose, tokenHash string,
+) (*domain.EmailChange, error) {
+	query := `
+		UPDATE email_changes SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id, new_email
+	`
+
+	var change domain.EmailChange
+	err := r.pool.QueryRow(ctx, query, tokenHash, string(purpose)).Scan(&change.UserID, &change.NewEmail)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &change, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PgxEmailVerificationRepository implements domain.EmailVerificationRepository using pgxpool.
+type PgxEmailVerificationRepository struct {
+	pool DB
+}
+
+// NewEmailVerificationRepository creates a new PgxEmailVerificationRepository.
+func NewEmailVerificationRepository(pool DB) *PgxEmailVerificationRepository {
+	return &PgxEmailVerificationRepository{pool: pool}
+}
+
+// Consume atomically marks an unused, unexpired verification token as used and
+// returns its user ID. Returns (0, false, nil) when no usable token matches.
+func (r *PgxEmailVerificationRepository) Consume(ctx context.Context, tokenHash string) (int, bool, error) {
+	query := `
+		UPDATE email_verifications SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`
+
+	var userID int
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return userID, true, nil
+}
+
+// LastCreatedAt returns when the user's most recent verification token was issued.
+// Returns (zero, false, nil) when the user has none.
+func (r *PgxEmailVerificationRepository) LastCreatedAt(ctx context.Context, userID int) (time.Time, bool, error) {
+	query := `SELECT MAX(created_at) FROM email_verifications WHERE user_id = $1`
+
+	var createdAt *time.Time
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&createdAt); err != nil {
+		return time.Time{}, false, err
+	}
+	if createdAt == nil {
+		return time.Time{}, false, nil
+	}
+
+	return *createdAt, true, nil
+}
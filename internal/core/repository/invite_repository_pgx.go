@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// PgxInviteRepository implements domain.InviteRepository using pgxpool.
+type PgxInviteRepository struct {
+	pool DB
+}
+
+// NewInviteRepository creates a new PgxInviteRepository.
+func NewInviteRepository(pool DB) *PgxInviteRepository {
+	return &PgxInviteRepository{pool: pool}
+}
+
+// Create stores a new invite. email may be empty for an unrestricted invite.
+func (r *PgxInviteRepository) Create(
+	ctx context.Context, tokenHash, email string, createdBy int, expiresAt time.Time,
+) error {
+	query := `
+		INSERT INTO invites (token_hash, email, created_by, expires_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4)
+	`
+	_, err := r.pool.Exec(ctx, query, tokenHash, email, createdBy, expiresAt)
+	return err
+}
+
+// Consume atomically marks an unused, unexpired invite as used.
+// The single UPDATE guarantees an invite can only be redeemed once, even under
+// concurrent registrations. Returns false when no redeemable invite matches.
+func (r *PgxInviteRepository) Consume(ctx context.Context, tokenHash, email string) (bool, error) {
+	query := `
+		UPDATE invites SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+		  AND (email IS NULL OR email = $2)
+	`
+	tag, err := r.pool.Exec(ctx, query, tokenHash, email)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Release marks a consumed invite as unused again.
+func (r *PgxInviteRepository) Release(ctx context.Context, tokenHash string) error {
+	query := `UPDATE invites SET used_at = NULL WHERE token_hash = $1`
+	_, err := r.pool.Exec(ctx, query, tokenHash)
+	return err
+}
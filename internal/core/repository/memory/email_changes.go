@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// emailChange is a stored pending email change.
+type emailChange struct {
+	domain.EmailChange
+	purpose   domain.EmailChangePurpose
+	expiresAt time.Time
+	used      bool
+}
+
+// EmailChanges implements domain.EmailChangeRepository.
+type EmailChanges struct {
+	mu   sync.Mutex
+	rows map[string]*emailChange
+}
+
+func (f *EmailChanges) Create(
+	_ context.Context, userID int, purpose domain.EmailChangePurpose, newEmail, tokenHash string, expiresAt time.Time,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for hash, change := range f.rows {
+		if change.UserID == userID && change.purpose == purpose && !change.used {
+			delete(f.rows, hash)
+		}
+	}
+	f.rows[tokenHash] = &emailChange{
+		EmailChange: domain.EmailChange{UserID: userID, NewEmail: newEmail},
+		purpose:     purpose,
+		expiresAt:   expiresAt,
+	}
+	return nil
+}
+
+func (f *EmailChanges) Consume(
+	_ context.Context, purpose domain.EmailChangePurpose, tokenHash string,
+) (*domain.EmailChange, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	change, ok := f.rows[tokenHash]
+	if !ok || change.used || change.purpose != purpose || !change.expiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	change.used = true
+	copied := change.EmailChange
+	return &copied, nil
+}
+
+// ExpireAll moves every pending change's expiry into the past.
+func (f *EmailChanges) ExpireAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, change := range f.rows {
+		change.expiresAt = time.Now().Add(-time.Second)
+	}
+}
@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// invite is a stored invite.
+type invite struct {
+	email     string
+	expiresAt time.Time
+	used      bool
+}
+
+// Invites implements domain.InviteRepository.
+type Invites struct {
+	mu   sync.Mutex
+	rows map[string]*invite
+}
+
+func (f *Invites) Create(_ context.Context, tokenHash, email string, _ int, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows[tokenHash] = &invite{email: email, expiresAt: expiresAt}
+	return nil
+}
+
+func (f *Invites) Consume(_ context.Context, tokenHash, email string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	invite, ok := f.rows[tokenHash]
+	if !ok || invite.used || !invite.expiresAt.After(time.Now()) || (invite.email != "" && invite.email != email) {
+		return false, nil
+	}
+	invite.used = true
+	return true, nil
+}
+
+func (f *Invites) Release(_ context.Context, tokenHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if invite, ok := f.rows[tokenHash]; ok {
+		invite.used = false
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Package memory provides in-memory implementations of the domain repositories
+// and notifier, for tests and benchmarks of the Logic and Web layers without a
+// database. They follow the contracts documented on the domain interfaces, not
+// the SQL, so they stay small; helpers such as Users.AddUser and Notifier.WaitFor
+// take a testing.TB and are meant for tests only.
+package memory
+
+import "github.com/duynhne/auth-service/internal/core/domain"
+
+// Repos holds one instance of every repository, wired together like the real
+// schema: sessions join users, and refresh tokens go away with their session.
+type Repos struct {
+	Users         *Users
+	Sessions      *Sessions
+	Invites       *Invites
+	Resets        *Resets
+	Verifications *Verifications
+	Tokens        *UserTokens
+	EmailChanges  *EmailChanges
+	TOTP          *TOTP
+	BackupCodes   *BackupCodes
+	RefreshTokens *RefreshTokens
+	Notifier      *Notifier
+}
+
+// New returns empty repositories.
+func New() *Repos {
+	users := &Users{rows: map[int]*domain.UserRow{}}
+	sessions := &Sessions{users: users, rows: map[int]*storedSession{}}
+	resets := &Resets{rows: map[string]*userToken{}}
+	verifications := &Verifications{rows: map[string]*userToken{}}
+	return &Repos{
+		Users:         users,
+		Sessions:      sessions,
+		Invites:       &Invites{rows: map[string]*invite{}},
+		Resets:        resets,
+		Verifications: verifications,
+		Tokens:        &UserTokens{resets: resets, verifications: verifications},
+		EmailChanges:  &EmailChanges{rows: map[string]*emailChange{}},
+		TOTP:          &TOTP{rows: map[int]*domain.TOTPRow{}},
+		BackupCodes:   &BackupCodes{rows: map[int][]*backupCode{}},
+		RefreshTokens: &RefreshTokens{sessions: sessions, rows: map[string]*refreshToken{}},
+		Notifier:      &Notifier{},
+	}
+}
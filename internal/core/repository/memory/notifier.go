@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Message is one message delivered through Notifier.
+type Message struct {
+	Kind  string
+	To    string
+	Token string
+}
+
+// Notifier implements domain.Notifier by recording every message.
+type Notifier struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (f *Notifier) record(kind, to, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, Message{Kind: kind, To: to, Token: token})
+	return nil
+}
+
+// Messages returns the messages of kind sent so far, oldest first.
+func (f *Notifier) Messages(kind string) []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []Message
+	for _, m := range f.sent {
+		if m.Kind == kind {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
+
+// WaitFor waits for the first message of kind to address to, which some flows
+// deliver in the background, and fails the test if none arrives.
+func (f *Notifier) WaitFor(tb testing.TB, kind, to string) Message {
+	tb.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, m := range f.Messages(kind) {
+			if strings.EqualFold(m.To, to) {
+				return m
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	tb.Fatalf("no %s message sent to %s", kind, to)
+	return Message{}
+}
+
+func (f *Notifier) SendPasswordReset(_ context.Context, email, token string, _ time.Time) error {
+	return f.record("password_reset", email, token)
+}
+
+func (f *Notifier) SendEmailVerification(_ context.Context, email, token string, _ time.Time) error {
+	return f.record("email_verification", email, token)
+}
+
+func (f *Notifier) SendEmailChangeVerification(_ context.Context, email, token string, _ time.Time) error {
+	return f.record("email_change", email, token)
+}
+
+func (f *Notifier) SendBackupEmailVerification(_ context.Context, email, token string, _ time.Time) error {
+	return f.record("backup_email", email, token)
+}
+
+func (f *Notifier) SendPasswordChanged(_ context.Context, email string, _ time.Time) error {
+	return f.record("password_changed", email, "")
+}
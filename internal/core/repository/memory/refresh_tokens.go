@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// refreshToken is a stored refresh token.
+type refreshToken struct {
+	domain.NewRefreshToken
+	used bool
+}
+
+// RefreshTokens implements domain.RefreshTokenRepository. Tokens are deleted
+// with their session, as by the foreign key cascade.
+type RefreshTokens struct {
+	mu       sync.Mutex
+	sessions *Sessions
+	rows     map[string]*refreshToken
+}
+
+func (t *refreshToken) row() *domain.RefreshTokenRow {
+	return &domain.RefreshTokenRow{SessionID: t.SessionID, FamilyID: t.FamilyID, Binding: t.Binding, Used: t.used}
+}
+
+// live returns the stored token unless its session is gone.
+func (f *RefreshTokens) live(tokenHash string) *refreshToken {
+	token, ok := f.rows[tokenHash]
+	if !ok {
+		return nil
+	}
+	if row, _ := f.sessions.GetByPublicID(context.Background(), token.SessionID); row == nil {
+		delete(f.rows, tokenHash)
+		return nil
+	}
+	return token
+}
+
+func (f *RefreshTokens) Create(_ context.Context, token domain.NewRefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows[token.TokenHash] = &refreshToken{NewRefreshToken: token}
+	return nil
+}
+
+func (f *RefreshTokens) Consume(_ context.Context, tokenHash string) (*domain.RefreshTokenRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token := f.live(tokenHash)
+	if token == nil || token.used || !token.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	token.used = true
+	return token.row(), nil
+}
+
+func (f *RefreshTokens) GetByHash(_ context.Context, tokenHash string) (*domain.RefreshTokenRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token := f.live(tokenHash)
+	if token == nil {
+		return nil, nil
+	}
+	return token.row(), nil
+}
+
+func (f *RefreshTokens) DeleteExpired(context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	var deleted int64
+	for hash, token := range f.rows {
+		if token.ExpiresAt.Before(now) {
+			delete(f.rows, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Expire moves the expiry of a stored token into the past.
+func (f *RefreshTokens) Expire(tokenHash string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if token, ok := f.rows[tokenHash]; ok {
+		token.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+}
+
+func (f *RefreshTokens) RevokeFamily(_ context.Context, familyID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sessionIDs := map[string]bool{}
+	for hash, token := range f.rows {
+		if token.FamilyID == familyID {
+			sessionIDs[token.SessionID] = true
+			delete(f.rows, hash)
+		}
+	}
+	return f.sessions.deleteWhere(func(s *storedSession) bool { return sessionIDs[s.publicID] }), nil
+}
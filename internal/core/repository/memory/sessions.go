@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// storedSession is a stored session.
+type storedSession struct {
+	domain.NewSession
+	publicID   string
+	createdAt  time.Time
+	lastUsedAt *time.Time
+}
+
+// Sessions implements domain.SessionRepository, joining users like the SQL does.
+type Sessions struct {
+	mu     sync.Mutex
+	users  *Users
+	rows   map[int]*storedSession
+	nextID int
+	// CreateErr, when set, is returned by Create instead of inserting
+	CreateErr error
+}
+
+func (f *Sessions) Create(_ context.Context, session domain.NewSession) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.CreateErr != nil {
+		return "", f.CreateErr
+	}
+	f.nextID++
+	publicID := uuid.NewString()
+	f.rows[f.nextID] = &storedSession{NewSession: session, publicID: publicID, createdAt: time.Now()}
+	return publicID, nil
+}
+
+// lookup returns the session matching match joined with its user, or nil.
+func (f *Sessions) lookup(match func(*storedSession) bool) *domain.SessionRow {
+	f.mu.Lock()
+	var (
+		id      int
+		session storedSession
+		found   bool
+	)
+	for sid, s := range f.rows {
+		if match(s) {
+			id, session, found = sid, *s, true
+			break
+		}
+	}
+	f.mu.Unlock()
+	if !found {
+		return nil
+	}
+
+	user, _ := f.users.GetByID(context.Background(), session.UserID)
+	if user == nil {
+		return nil
+	}
+	lastActive := session.createdAt
+	if session.lastUsedAt != nil {
+		lastActive = *session.lastUsedAt
+	}
+	return &domain.SessionRow{
+		ID:            id,
+		PublicID:      session.publicID,
+		UserID:        user.ID,
+		UserPublicID:  user.PublicID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+		ExpiresAt:     session.ExpiresAt,
+		Binding:       session.Binding,
+		Subnet:        session.Subnet,
+		LastActiveAt:  lastActive,
+	}
+}
+
+func (f *Sessions) GetUserByToken(_ context.Context, token string) (*domain.SessionRow, error) {
+	return f.lookup(func(s *storedSession) bool { return s.Token == token }), nil
+}
+
+func (f *Sessions) GetByPublicID(_ context.Context, publicID string) (*domain.SessionRow, error) {
+	return f.lookup(func(s *storedSession) bool { return s.publicID == publicID }), nil
+}
+
+func (f *Sessions) ListByUserID(_ context.Context, userID int) ([]domain.SessionInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	infos := []domain.SessionInfo{}
+	for _, s := range f.rows {
+		if s.UserID == userID && s.ExpiresAt.After(time.Now()) {
+			lastUsed := s.createdAt
+			if s.lastUsedAt != nil {
+				lastUsed = *s.lastUsedAt
+			}
+			infos = append(infos, domain.SessionInfo{
+				ID: s.publicID, CreatedAt: s.createdAt, ExpiresAt: s.ExpiresAt, LastUsedAt: lastUsed, Subnet: s.Subnet,
+			})
+		}
+	}
+	return infos, nil
+}
+
+// deleteWhere deletes every session matching match and returns how many it deleted.
+func (f *Sessions) deleteWhere(match func(*storedSession) bool) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deleted int64
+	for id, s := range f.rows {
+		if match(s) {
+			delete(f.rows, id)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// Count returns the number of stored sessions.
+func (f *Sessions) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.rows)
+}
+
+// Expire moves the expiry of the session with the given public ID into the past.
+func (f *Sessions) Expire(publicID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, s := range f.rows {
+		if s.publicID == publicID {
+			s.ExpiresAt = time.Now().Add(-time.Minute)
+		}
+	}
+}
+
+func (f *Sessions) DeleteByID(_ context.Context, sessionID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.rows, sessionID)
+	return nil
+}
+
+func (f *Sessions) DeleteByUserID(_ context.Context, userID int) (int64, error) {
+	return f.deleteWhere(func(s *storedSession) bool { return s.UserID == userID }), nil
+}
+
+func (f *Sessions) DeleteOthersByUserID(_ context.Context, userID int, keepToken string) (int64, error) {
+	return f.deleteWhere(func(s *storedSession) bool { return s.UserID == userID && s.Token != keepToken }), nil
+}
+
+func (f *Sessions) DeleteExpired(context.Context) (int64, error) {
+	now := time.Now()
+	return f.deleteWhere(func(s *storedSession) bool { return s.ExpiresAt.Before(now) }), nil
+}
+
+func (f *Sessions) DeleteByToken(_ context.Context, token string) error {
+	f.deleteWhere(func(s *storedSession) bool { return s.Token == token })
+	return nil
+}
+
+func (f *Sessions) Rotate(_ context.Context, sessionID int, token string, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.rows[sessionID]; ok {
+		now := time.Now()
+		s.Token, s.ExpiresAt, s.lastUsedAt = token, expiresAt, &now
+	}
+	return nil
+}
+
+func (f *Sessions) TouchLastUsed(_ context.Context, sessionID int, minInterval time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.rows[sessionID]
+	if !ok {
+		return false, nil
+	}
+	now := time.Now()
+	if s.lastUsedAt != nil && now.Sub(*s.lastUsedAt) < minInterval {
+		return false, nil
+	}
+	s.lastUsedAt = &now
+	return true, nil
+}
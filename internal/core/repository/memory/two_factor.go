@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// TOTP implements domain.TOTPRepository.
+type TOTP struct {
+	mu   sync.Mutex
+	rows map[int]*domain.TOTPRow
+}
+
+func (f *TOTP) GetByUserID(_ context.Context, userID int) (*domain.TOTPRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	row, ok := f.rows[userID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *row
+	return &copied, nil
+}
+
+func (f *TOTP) Upsert(_ context.Context, userID int, secretEncrypted []byte) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if row, ok := f.rows[userID]; ok && row.ConfirmedAt != nil {
+		return false, nil
+	}
+	f.rows[userID] = &domain.TOTPRow{UserID: userID, SecretEncrypted: secretEncrypted}
+	return true, nil
+}
+
+func (f *TOTP) Confirm(_ context.Context, userID int, step int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if row, ok := f.rows[userID]; ok {
+		now := time.Now()
+		row.ConfirmedAt, row.LastUsedStep = &now, step
+	}
+	return nil
+}
+
+func (f *TOTP) UseStep(_ context.Context, userID int, step int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	row, ok := f.rows[userID]
+	if !ok || step <= row.LastUsedStep {
+		return false, nil
+	}
+	row.LastUsedStep = step
+	return true, nil
+}
+
+// backupCode is a stored backup code.
+type backupCode struct {
+	domain.BackupCodeRow
+	used bool
+}
+
+// BackupCodes implements domain.BackupCodeRepository.
+type BackupCodes struct {
+	mu     sync.Mutex
+	rows   map[int][]*backupCode
+	nextID int
+}
+
+func (f *BackupCodes) Replace(_ context.Context, userID int, codeHashes []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows[userID] = nil
+	for _, hash := range codeHashes {
+		f.nextID++
+		f.rows[userID] = append(f.rows[userID], &backupCode{BackupCodeRow: domain.BackupCodeRow{ID: f.nextID, CodeHash: hash}})
+	}
+	return nil
+}
+
+func (f *BackupCodes) ListUnused(_ context.Context, userID int) ([]domain.BackupCodeRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var rows []domain.BackupCodeRow
+	for _, code := range f.rows[userID] {
+		if !code.used {
+			rows = append(rows, code.BackupCodeRow)
+		}
+	}
+	return rows, nil
+}
+
+func (f *BackupCodes) Consume(_ context.Context, id int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, codes := range f.rows {
+		for _, code := range codes {
+			if code.ID == id && !code.used {
+				code.used = true
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// userToken is a stored single-use token of a user (reset or verification).
+type userToken struct {
+	userID    int
+	createdAt time.Time
+	expiresAt time.Time
+	used      bool
+}
+
+// userTokens is the shared store behind Resets and Verifications.
+type userTokens struct {
+	mu   sync.Mutex
+	rows map[string]*userToken
+}
+
+func (f *userTokens) replace(userID int, tokenHash string, expiresAt time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for hash, token := range f.rows {
+		if token.userID == userID && !token.used {
+			delete(f.rows, hash)
+		}
+	}
+	f.rows[tokenHash] = &userToken{userID: userID, createdAt: time.Now(), expiresAt: expiresAt}
+}
+
+func (f *userTokens) lookup(tokenHash string, consume bool) (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token, ok := f.rows[tokenHash]
+	if !ok || token.used || !token.expiresAt.After(time.Now()) {
+		return 0, false
+	}
+	token.used = token.used || consume
+	return token.userID, true
+}
+
+// UserTokens implements domain.UserTokenRepository over Resets and Verifications.
+type UserTokens struct {
+	resets        *Resets
+	verifications *Verifications
+}
+
+func (f *UserTokens) ReplaceToken(
+	_ context.Context, userID int, purpose domain.TokenPurpose, tokenHash string, expiresAt time.Time,
+) error {
+	switch purpose {
+	case domain.TokenPasswordReset:
+		(*userTokens)(f.resets).replace(userID, tokenHash, expiresAt)
+	case domain.TokenEmailVerification:
+		(*userTokens)(f.verifications).replace(userID, tokenHash, expiresAt)
+	default:
+		return fmt.Errorf("unknown token purpose %q", purpose)
+	}
+	return nil
+}
+
+// Resets implements domain.PasswordResetRepository.
+type Resets userTokens
+
+func (f *Resets) Lookup(_ context.Context, tokenHash string) (int, bool, error) {
+	userID, ok := (*userTokens)(f).lookup(tokenHash, false)
+	return userID, ok, nil
+}
+
+func (f *Resets) Consume(_ context.Context, tokenHash string) (int, bool, error) {
+	userID, ok := (*userTokens)(f).lookup(tokenHash, true)
+	return userID, ok, nil
+}
+
+// Verifications implements domain.EmailVerificationRepository.
+type Verifications userTokens
+
+func (f *Verifications) Consume(_ context.Context, tokenHash string) (int, bool, error) {
+	userID, ok := (*userTokens)(f).lookup(tokenHash, true)
+	return userID, ok, nil
+}
+
+func (f *Verifications) LastCreatedAt(_ context.Context, userID int) (time.Time, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var last time.Time
+	for _, token := range f.rows {
+		if token.userID == userID && token.createdAt.After(last) {
+			last = token.createdAt
+		}
+	}
+	return last, !last.IsZero(), nil
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AddUser stores a user with password hashed at cost and returns its row.
+func (f *Users) AddUser(tb testing.TB, username, email, password string, cost int) *domain.UserRow {
+	tb.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		tb.Fatalf("hash password: %v", err)
+	}
+	row, err := f.Create(context.Background(), username, email, string(hash))
+	if err != nil {
+		tb.Fatalf("create user: %v", err)
+	}
+	return row
+}
+
+// Users implements domain.UserRepository.
+type Users struct {
+	mu     sync.Mutex
+	rows   map[int]*domain.UserRow
+	nextID int
+	// CreateErr, when set, is returned by Create instead of inserting
+	CreateErr error
+	// renames is the username history, oldest first
+	renames []usernameChange
+	// lastLogins holds users.last_login; lastLoginWrites counts the updates
+	lastLogins      map[int]time.Time
+	lastLoginWrites int
+}
+
+// usernameChange is one username_history row.
+type usernameChange struct {
+	userID      int
+	oldUsername string
+	changedAt   time.Time
+}
+
+func (f *Users) find(match func(*domain.UserRow) bool) *domain.UserRow {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, row := range f.rows {
+		if match(row) {
+			copied := *row
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (f *Users) update(userID int, change func(*domain.UserRow)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if row, ok := f.rows[userID]; ok {
+		change(row)
+	}
+}
+
+func (f *Users) GetByUsername(_ context.Context, username string) (*domain.UserRow, error) {
+	return f.find(func(r *domain.UserRow) bool { return r.Username == username }), nil
+}
+
+func (f *Users) GetByEmail(_ context.Context, email string) (*domain.UserRow, error) {
+	return f.find(func(r *domain.UserRow) bool { return r.Email == email }), nil
+}
+
+func (f *Users) GetByBackupEmail(_ context.Context, email string) (*domain.UserRow, error) {
+	return f.find(func(r *domain.UserRow) bool { return r.BackupEmail != "" && r.BackupEmail == email }), nil
+}
+
+func (f *Users) GetByID(_ context.Context, id int) (*domain.UserRow, error) {
+	return f.find(func(r *domain.UserRow) bool { return r.ID == id }), nil
+}
+
+func (f *Users) GetByPublicID(_ context.Context, publicID string) (*domain.UserRow, error) {
+	return f.find(func(r *domain.UserRow) bool { return r.PublicID == publicID }), nil
+}
+
+func (f *Users) List(_ context.Context, limit, offset int) ([]domain.UserRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]int, 0, len(f.rows))
+	for id := range f.rows {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	var rows []domain.UserRow
+	for i := offset; i < len(ids) && len(rows) < limit; i++ {
+		rows = append(rows, *f.rows[ids[i]])
+	}
+	return rows, nil
+}
+
+func (f *Users) Count(_ context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.rows), nil
+}
+
+// ExistsByUsername ignores case, like idx_users_username_lower.
+func (f *Users) ExistsByUsername(_ context.Context, username string) (bool, error) {
+	row := f.find(func(r *domain.UserRow) bool { return strings.EqualFold(r.Username, username) })
+	return row != nil, nil
+}
+
+func (f *Users) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	row, err := f.GetByEmail(ctx, email)
+	return row != nil, err
+}
+
+func (f *Users) Create(_ context.Context, username, email, passwordHash string) (*domain.UserRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.CreateErr != nil {
+		return nil, f.CreateErr
+	}
+	f.nextID++
+	now := time.Now()
+	row := &domain.UserRow{
+		ID:                f.nextID,
+		PublicID:          uuid.NewString(),
+		Username:          username,
+		Email:             email,
+		PasswordHash:      passwordHash,
+		PasswordChangedAt: &now,
+		Role:              domain.RoleUser,
+	}
+	f.rows[row.ID] = row
+	copied := *row
+	return &copied, nil
+}
+
+func (f *Users) SoftDelete(_ context.Context, userID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.rows, userID)
+	return nil
+}
+
+func (f *Users) Delete(_ context.Context, userID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.rows, userID)
+	return nil
+}
+
+func (f *Users) UpdateLastLogin(_ context.Context, userID int, minInterval time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if last, ok := f.lastLogins[userID]; ok && now.Sub(last) < minInterval {
+		return false, nil
+	}
+	if f.lastLogins == nil {
+		f.lastLogins = map[int]time.Time{}
+	}
+	f.lastLogins[userID] = now
+	f.lastLoginWrites++
+	return true, nil
+}
+
+// LastLoginWrites returns the number of last_login updates written.
+func (f *Users) LastLoginWrites() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastLoginWrites
+}
+
+func (f *Users) UpdatePassword(_ context.Context, userID int, passwordHash string) error {
+	f.update(userID, func(r *domain.UserRow) {
+		now := time.Now()
+		r.PasswordHash, r.PasswordChangedAt = passwordHash, &now
+	})
+	return nil
+}
+
+func (f *Users) RehashPassword(_ context.Context, userID int, oldHash, newHash string) error {
+	f.update(userID, func(r *domain.UserRow) {
+		if r.PasswordHash == oldHash {
+			r.PasswordHash = newHash
+		}
+	})
+	return nil
+}
+
+func (f *Users) ChangeEmail(ctx context.Context, userID int, email string) (bool, error) {
+	if taken, _ := f.ExistsByEmail(ctx, email); taken {
+		return false, nil
+	}
+	f.update(userID, func(r *domain.UserRow) { r.Email, r.EmailVerified = email, true })
+	return true, nil
+}
+
+func (f *Users) SetBackupEmail(ctx context.Context, userID int, email string) (bool, error) {
+	if other, _ := f.GetByBackupEmail(ctx, email); other != nil && other.ID != userID {
+		return false, nil
+	}
+	f.update(userID, func(r *domain.UserRow) { r.BackupEmail = email })
+	return true, nil
+}
+
+func (f *Users) GetRole(ctx context.Context, userID int) (domain.Role, error) {
+	row, _ := f.GetByID(ctx, userID)
+	if row == nil {
+		return "", nil
+	}
+	return row.Role, nil
+}
+
+func (f *Users) SetRole(_ context.Context, userID int, role domain.Role) error {
+	f.update(userID, func(r *domain.UserRow) { r.Role = role })
+	return nil
+}
+
+func (f *Users) ChangeUsername(_ context.Context, userID int, username string) (bool, error) {
+	taken := f.find(func(r *domain.UserRow) bool { return r.ID != userID && strings.EqualFold(r.Username, username) })
+	if taken != nil {
+		return false, nil
+	}
+	f.update(userID, func(r *domain.UserRow) {
+		f.renames = append(f.renames, usernameChange{userID: userID, oldUsername: r.Username, changedAt: time.Now()})
+		r.Username = username
+	})
+	return true, nil
+}
+
+func (f *Users) LastUsernameChange(_ context.Context, userID int) (time.Time, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, change := range slices.Backward(f.renames) {
+		if change.userID == userID {
+			return change.changedAt, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+func (f *Users) UsernameReleasedSince(_ context.Context, username string, userID int, since time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.ContainsFunc(f.renames, func(change usernameChange) bool {
+		return change.userID != userID && strings.EqualFold(change.oldUsername, username) && change.changedAt.After(since)
+	}), nil
+}
+
+func (f *Users) MarkEmailVerified(_ context.Context, userID int) error {
+	f.update(userID, func(r *domain.UserRow) { r.EmailVerified = true })
+	return nil
+}
+
+func (f *Users) RecordFailedLogin(_ context.Context, userID, threshold int, lockout time.Duration) (bool, error) {
+	locked := false
+	f.update(userID, func(r *domain.UserRow) {
+		r.FailedLoginAttempts++
+		if r.FailedLoginAttempts >= threshold {
+			until := time.Now().Add(lockout)
+			r.LockedUntil, r.FailedLoginAttempts, locked = &until, 0, true
+		}
+	})
+	return locked, nil
+}
+
+func (f *Users) ResetFailedLogins(_ context.Context, userID int) error {
+	f.update(userID, func(r *domain.UserRow) { r.FailedLoginAttempts, r.LockedUntil = 0, nil })
+	return nil
+}
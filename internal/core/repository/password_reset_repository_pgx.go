@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PgxPasswordResetRepository implements domain.PasswordResetRepository using pgxpool.
+type PgxPasswordResetRepository struct {
+	pool DB
+}
+
+// NewPasswordResetRepository creates a new PgxPasswordResetRepository.
+func NewPasswordResetRepository(pool DB) *PgxPasswordResetRepository {
+	return &PgxPasswordResetRepository{pool: pool}
+}
+
+// Lookup returns the user ID of an unused, unexpired reset token without using it.
+// Returns (0, false, nil) when no usable token matches.
+func (r *PgxPasswordResetRepository) Lookup(ctx context.Context, tokenHash string) (int, bool, error) {
+	query := `
+		SELECT user_id FROM password_resets
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	var userID int
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return userID, true, nil
+}
+
+// Consume atomically marks an unused, unexpired reset token as used and returns
+// its user ID. The single UPDATE guarantees a token works only once, even under
+// concurrent requests. Returns (0, false, nil) when no usable token matches.
+func (r *PgxPasswordResetRepository) Consume(ctx context.Context, tokenHash string) (int, bool, error) {
+	query := `
+		UPDATE password_resets SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`
+
+	var userID int
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return userID, true, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// refreshTokenColumns is the column list scanned by scanRefreshToken.
+// Queries must alias refresh_tokens as rt and join sessions as s.
+const refreshTokenColumns = `s.public_id::text, rt.family_id::text, COALESCE(rt.binding, ''), rt.used_at IS NOT NULL`
+
+// PgxRefreshTokenRepository implements domain.RefreshTokenRepository using pgxpool.
+type PgxRefreshTokenRepository struct {
+	pool DB
+}
+
+// NewRefreshTokenRepository creates a new PgxRefreshTokenRepository.
+func NewRefreshTokenRepository(pool DB) *PgxRefreshTokenRepository {
+	return &PgxRefreshTokenRepository{pool: pool}
+}
+
+// Create stores a new refresh token for a session.
+func (r *PgxRefreshTokenRepository) Create(ctx context.Context, token domain.NewRefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (session_id, family_id, token_hash, expires_at, binding)
+		SELECT id, $2, $3, $4, NULLIF($5, '') FROM sessions WHERE public_id = $1
+	`
+	tag, err := r.pool.Exec(ctx, query,
+		token.SessionID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.Binding,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("session %s not found", token.SessionID)
+	}
+	return nil
+}
+
+// Consume atomically marks an unused, unexpired refresh token as used and returns it.
+// The single UPDATE guarantees a token is rotated only once, even under concurrent
+// requests. Returns (nil, nil) when no usable token matches.
+func (r *PgxRefreshTokenRepository) Consume(ctx context.Context, tokenHash string) (*domain.RefreshTokenRow, error) {
+	query := `
+		UPDATE refresh_tokens rt SET used_at = CURRENT_TIMESTAMP
+		FROM sessions s
+		WHERE rt.session_id = s.id
+		  AND rt.token_hash = $1
+		  AND rt.used_at IS NULL
+		  AND rt.expires_at > CURRENT_TIMESTAMP
+		RETURNING ` + refreshTokenColumns
+	return scanRefreshToken(r.pool.QueryRow(ctx, query, tokenHash))
+}
+
+// GetByHash returns the refresh token with the given hash, used or not.
+// Returns (nil, nil) when no token matches.
+func (r *PgxRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshTokenRow, error) {
+	query := `SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens rt JOIN sessions s ON rt.session_id = s.id
+		WHERE rt.token_hash = $1`
+	return scanRefreshToken(r.pool.QueryRow(ctx, query, tokenHash))
+}
+
+// DeleteExpired deletes every refresh token past its expiry, used or not, and
+// returns how many were deleted. Revoked tokens need no pruning: they are deleted
+// with their session.
+func (r *PgxRefreshTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM refresh_tokens WHERE expires_at < CURRENT_TIMESTAMP`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RevokeFamily deletes every session holding a token of the family; the foreign key
+// cascade removes the family's tokens. Returns the number of sessions deleted.
+func (r *PgxRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) (int64, error) {
+	query := `
+		DELETE FROM sessions
+		WHERE id IN (SELECT session_id FROM refresh_tokens WHERE family_id = $1)
+	`
+	tag, err := r.pool.Exec(ctx, query, familyID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// scanRefreshToken scans a row selected with refreshTokenColumns.
+// Returns (nil, nil) when the query matched no row.
+func scanRefreshToken(row pgx.Row) (*domain.RefreshTokenRow, error) {
+	var token domain.RefreshTokenRow
+	err := row.Scan(&token.SessionID, &token.FamilyID, &token.Binding, &token.Used)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
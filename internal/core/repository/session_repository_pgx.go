@@ -6,42 +6,165 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/duynhne/auth-service/internal/core/domain"
 )
 
+// sessionRowColumns is the column list scanned by scanSessionRow.
+// Queries must alias sessions as s and join users as u.
+const sessionRowColumns = `s.id, s.public_id::text, u.id, u.public_id::text, u.username, u.email, u.email_verified, u.role,
+	s.expires_at, COALESCE(s.binding, ''), COALESCE(s.subnet, ''),
+	COALESCE(s.last_used_at, s.created_at, CURRENT_TIMESTAMP)`
+
 // PgxSessionRepository implements domain.SessionRepository using pgxpool.
 type PgxSessionRepository struct {
-	pool *pgxpool.Pool
+	pool DB
 }
 
 // NewSessionRepository creates a new PgxSessionRepository.
-func NewSessionRepository(pool *pgxpool.Pool) *PgxSessionRepository {
+func NewSessionRepository(pool DB) *PgxSessionRepository {
 	return &PgxSessionRepository{pool: pool}
 }
 
-// Create inserts a new session for the given user.
-func (r *PgxSessionRepository) Create(ctx context.Context, userID int, token string, expiresAt time.Time) error {
-	query := `INSERT INTO sessions (user_id, token, expires_at) VALUES ($1, $2, $3)`
-	_, err := r.pool.Exec(ctx, query, userID, token, expiresAt)
-	return err
+// Create inserts a new session for the given user and returns its public ID.
+func (r *PgxSessionRepository) Create(ctx context.Context, session domain.NewSession) (string, error) {
+	query := `
+		INSERT INTO sessions (user_id, token, expires_at, binding, subnet)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING public_id::text
+	`
+	var publicID string
+	err := r.pool.QueryRow(ctx, query,
+		session.UserID, session.Token, session.ExpiresAt, session.Binding, session.Subnet,
+	).Scan(&publicID)
+	return publicID, err
 }
 
 // GetUserByToken looks up the session by token and returns the associated
 // user data together with the session expiry time.
 // Returns (nil, nil) when the token does not match any session.
 func (r *PgxSessionRepository) GetUserByToken(ctx context.Context, token string) (*domain.SessionRow, error) {
+	query := `SELECT ` + sessionRowColumns + ` FROM sessions s JOIN users u ON s.user_id = u.id WHERE s.token = $1`
+	return scanSessionRow(r.pool.QueryRow(ctx, query, token))
+}
+
+// GetByPublicID returns the session with the given public ID together with its owner.
+// Returns (nil, nil) when no session matches.
+func (r *PgxSessionRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.SessionRow, error) {
+	query := `SELECT ` + sessionRowColumns + ` FROM sessions s JOIN users u ON s.user_id = u.id WHERE s.public_id = $1`
+	return scanSessionRow(r.pool.QueryRow(ctx, query, publicID))
+}
+
+// ListByUserID returns the user's unexpired sessions, newest first.
+func (r *PgxSessionRepository) ListByUserID(ctx context.Context, userID int) ([]domain.SessionInfo, error) {
+	query := `
+		SELECT public_id::text, COALESCE(created_at, CURRENT_TIMESTAMP), expires_at,
+		       COALESCE(last_used_at, created_at, CURRENT_TIMESTAMP), COALESCE(subnet, '')
+		FROM sessions
+		WHERE user_id = $1 AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.SessionInfo{}
+	for rows.Next() {
+		var s domain.SessionInfo
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.ExpiresAt, &s.LastUsedAt, &s.Subnet); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// DeleteByID deletes the session with the given ID.
+func (r *PgxSessionRepository) DeleteByID(ctx context.Context, sessionID int) error {
+	query := `DELETE FROM sessions WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, sessionID)
+	return err
+}
+
+// DeleteByUserID deletes every session of the given user.
+// Returns the number of sessions deleted.
+func (r *PgxSessionRepository) DeleteByUserID(ctx context.Context, userID int) (int64, error) {
+	query := `DELETE FROM sessions WHERE user_id = $1`
+	tag, err := r.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteOthersByUserID deletes every session of the user except the one with keepToken (jti).
+// Returns the number of sessions deleted.
+func (r *PgxSessionRepository) DeleteOthersByUserID(ctx context.Context, userID int, keepToken string) (int64, error) {
+	query := `DELETE FROM sessions WHERE user_id = $1 AND token <> $2`
+	tag, err := r.pool.Exec(ctx, query, userID, keepToken)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteExpired deletes every session past its expires_at.
+// Returns the number of sessions deleted.
+func (r *PgxSessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at <= CURRENT_TIMESTAMP`
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteByToken deletes the session with the given token (jti).
+// Deleting a token that has no session is not an error.
+func (r *PgxSessionRepository) DeleteByToken(ctx context.Context, token string) error {
+	query := `DELETE FROM sessions WHERE token = $1`
+	_, err := r.pool.Exec(ctx, query, token)
+	return err
+}
+
+// Rotate re-keys the session to a new access token (jti) and expiry after a
+// refresh, marking it used now. The previous access token stops working.
+func (r *PgxSessionRepository) Rotate(ctx context.Context, sessionID int, token string, expiresAt time.Time) error {
 	query := `
-		SELECT u.id, u.username, u.email, s.expires_at
-		FROM sessions s
-		JOIN users u ON s.user_id = u.id
-		WHERE s.token = $1
+		UPDATE sessions SET token = $2, expires_at = $3, last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1
 	`
+	_, err := r.pool.Exec(ctx, query, sessionID, token, expiresAt)
+	return err
+}
+
+// TouchLastUsed sets last_used_at to now unless it was already updated within
+// minInterval. The throttle is evaluated in SQL so it holds across replicas.
+// Returns true when the row was updated.
+func (r *PgxSessionRepository) TouchLastUsed(ctx context.Context, sessionID int, minInterval time.Duration) (bool, error) {
+	query := `
+		UPDATE sessions SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - make_interval(secs => $2))
+	`
+	tag, err := r.pool.Exec(ctx, query, sessionID, minInterval.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
 
-	var row domain.SessionRow
-	err := r.pool.QueryRow(ctx, query, token).Scan(
-		&row.UserID, &row.Username, &row.Email, &row.ExpiresAt,
+// scanSessionRow scans a row selected with sessionRowColumns.
+// Returns (nil, nil) when the query matched no row.
+func scanSessionRow(row pgx.Row) (*domain.SessionRow, error) {
+	var s domain.SessionRow
+	err := row.Scan(
+		&s.ID, &s.PublicID, &s.UserID, &s.UserPublicID, &s.Username, &s.Email, &s.EmailVerified, &s.Role,
+		&s.ExpiresAt, &s.Binding, &s.Subnet, &s.LastActiveAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -50,5 +173,5 @@ func (r *PgxSessionRepository) GetUserByToken(ctx context.Context, token string)
 		return nil, err
 	}
 
-	return &row, nil
+	return &s, nil
 }
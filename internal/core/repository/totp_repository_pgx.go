@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// PgxTOTPRepository implements domain.TOTPRepository using pgxpool.
+type PgxTOTPRepository struct {
+	pool DB
+}
+
+// NewTOTPRepository creates a new PgxTOTPRepository.
+func NewTOTPRepository(pool DB) *PgxTOTPRepository {
+	return &PgxTOTPRepository{pool: pool}
+}
+
+// GetByUserID returns the user's enrollment, or nil if there is none.
+func (r *PgxTOTPRepository) GetByUserID(ctx context.Context, userID int) (*domain.TOTPRow, error) {
+	query := `SELECT user_id, secret_encrypted, confirmed_at, last_used_step FROM user_totp WHERE user_id = $1`
+
+	var row domain.TOTPRow
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&row.UserID, &row.SecretEncrypted, &row.ConfirmedAt, &row.LastUsedStep,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &row, nil
+}
+
+// Upsert stores a new unconfirmed secret for the user, replacing any earlier
+// unconfirmed one. The WHERE clause leaves a confirmed enrollment untouched, so
+// re-enrolling cannot silently swap an active secret.
+func (r *PgxTOTPRepository) Upsert(ctx context.Context, userID int, secretEncrypted []byte) (bool, error) {
+	query := `
+		INSERT INTO user_totp (user_id, secret_encrypted) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_encrypted = EXCLUDED.secret_encrypted,
+		    last_used_step = 0,
+		    created_at = CURRENT_TIMESTAMP
+		WHERE user_totp.confirmed_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, userID, secretEncrypted)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Confirm activates the user's enrollment and records step as used.
+func (r *PgxTOTPRepository) Confirm(ctx context.Context, userID int, step int64) error {
+	query := `
+		UPDATE user_totp SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL
+	`
+	_, err := r.pool.Exec(ctx, query, userID, step)
+	return err
+}
+
+// UseStep records step as the last accepted code if it is newer than the stored one.
+// The conditional UPDATE makes the check and the write atomic, so a code
+// accepted by one request is rejected by any concurrent one.
+func (r *PgxTOTPRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+
+	tag, err := r.pool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
@@ -3,49 +3,133 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/duynhne/auth-service/internal/core/domain"
 )
 
+// uniqueViolation is the PostgreSQL SQLSTATE for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// userColumns is the column list scanned by scanUser, shared by every user lookup.
+const userColumns = `id, public_id::text, username, email, password_hash,
+	failed_login_attempts, locked_until, password_changed_at, email_verified, COALESCE(backup_email, ''), role`
+
 // PgxUserRepository implements domain.UserRepository using pgxpool.
 type PgxUserRepository struct {
-	pool *pgxpool.Pool
+	pool DB
+	opts UserRepositoryOptions
+}
+
+// UserRepositoryOptions configures how PgxUserRepository matches users.
+type UserRepositoryOptions struct {
+	// CaseInsensitiveUsernames makes "Alice" match the user "alice" in lookups.
+	// Uniqueness ignores case either way (idx_users_username_lower), and
+	// usernames are still stored as typed.
+	CaseInsensitiveUsernames bool
 }
 
 // NewUserRepository creates a new PgxUserRepository.
-func NewUserRepository(pool *pgxpool.Pool) *PgxUserRepository {
-	return &PgxUserRepository{pool: pool}
+func NewUserRepository(pool DB, opts UserRepositoryOptions) *PgxUserRepository {
+	return &PgxUserRepository{pool: pool, opts: opts}
+}
+
+// usernameEquals returns the SQL condition matching column against the username
+// parameter param, honoring CaseInsensitiveUsernames.
+func (r *PgxUserRepository) usernameEquals(column, param string) string {
+	if r.opts.CaseInsensitiveUsernames {
+		return "LOWER(" + column + ") = LOWER(" + param + ")"
+	}
+	return column + " = " + param
+}
+
+// usernameTaken returns the SQL condition matching rows that keep the username
+// parameter param from being used: the same username (the unique constraint
+// covers deleted rows too) or an active one differing only in case.
+func usernameTaken(param string) string {
+	return "username = " + param + " OR (LOWER(username) = LOWER(" + param + ") AND deleted_at IS NULL)"
 }
 
 // GetByUsername returns the user matching the given username.
 // Returns (nil, nil) when no user is found.
 func (r *PgxUserRepository) GetByUsername(ctx context.Context, username string) (*domain.UserRow, error) {
-	query := `SELECT id, username, email, password_hash FROM users WHERE username = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + r.usernameEquals("username", "$1") + ` AND deleted_at IS NULL`
+	return scanUser(r.pool.QueryRow(ctx, query, username))
+}
 
-	var row domain.UserRow
-	err := r.pool.QueryRow(ctx, query, username).Scan(
-		&row.ID, &row.Username, &row.Email, &row.PasswordHash,
-	)
+// GetByEmail returns the user registered with the given email.
+// Returns (nil, nil) when no user is found.
+func (r *PgxUserRepository) GetByEmail(ctx context.Context, email string) (*domain.UserRow, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND deleted_at IS NULL`
+	return scanUser(r.pool.QueryRow(ctx, query, email))
+}
+
+// GetByBackupEmail returns the user whose confirmed backup email is email.
+// Returns (nil, nil) when no user is found.
+func (r *PgxUserRepository) GetByBackupEmail(ctx context.Context, email string) (*domain.UserRow, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE backup_email = $1 AND deleted_at IS NULL`
+	return scanUser(r.pool.QueryRow(ctx, query, email))
+}
+
+// GetByID returns the user with the given ID.
+// Returns (nil, nil) when no user is found.
+func (r *PgxUserRepository) GetByID(ctx context.Context, id int) (*domain.UserRow, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+	return scanUser(r.pool.QueryRow(ctx, query, id))
+}
+
+// GetByPublicID returns the user with the given public UUID.
+// Returns (nil, nil) when no user is found.
+func (r *PgxUserRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.UserRow, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE public_id = $1::uuid AND deleted_at IS NULL`
+	return scanUser(r.pool.QueryRow(ctx, query, publicID))
+}
+
+// List returns up to limit users ordered by ID, skipping the first offset.
+func (r *PgxUserRepository) List(ctx context.Context, limit, offset int) ([]domain.UserRow, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE deleted_at IS NULL ORDER BY id LIMIT $1 OFFSET $2`
+
+	rows, err := r.pool.Query(ctx, query, limit, offset)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
+	defer rows.Close()
+
+	users := []domain.UserRow{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+
+	return users, rows.Err()
+}
+
+// Count returns the total number of users.
+func (r *PgxUserRepository) Count(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, err
+	}
 
-	return &row, nil
+	return count, nil
 }
 
-// ExistsByUsernameOrEmail returns true when a user with the given
-// username or email already exists.
-func (r *PgxUserRepository) ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 OR email = $2)`
+// ExistsByUsername returns true when the username is taken: by a user whose
+// username is the same, or by an active user whose username differs only in case.
+// The two conditions mirror the username unique constraint and idx_users_username_lower.
+func (r *PgxUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE ` + usernameTaken("$1") + `)`
 
 	var exists bool
-	err := r.pool.QueryRow(ctx, query, username, email).Scan(&exists)
+	err := r.pool.QueryRow(ctx, query, username).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -53,22 +137,253 @@ func (r *PgxUserRepository) ExistsByUsernameOrEmail(ctx context.Context, usernam
 	return exists, nil
 }
 
-// Create inserts a new user and returns the generated user ID.
-func (r *PgxUserRepository) Create(ctx context.Context, username, email, passwordHash string) (int, error) {
-	query := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3) RETURNING id`
+// ExistsByEmail returns true when a user with the given email already exists.
+func (r *PgxUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
 
-	var userID int
-	err := r.pool.QueryRow(ctx, query, username, email, passwordHash).Scan(&userID)
+	var exists bool
+	err := r.pool.QueryRow(ctx, query, email).Scan(&exists)
 	if err != nil {
-		return 0, err
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Create inserts a new user and returns it with its generated IDs.
+func (r *PgxUserRepository) Create(ctx context.Context, username, email, passwordHash string) (*domain.UserRow, error) {
+	query := `
+		INSERT INTO users (username, email, password_hash, password_changed_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		RETURNING ` + userColumns
+	return scanUser(r.pool.QueryRow(ctx, query, username, email, passwordHash))
+}
+
+// SoftDelete marks the user deleted; later lookups skip the row.
+func (r *PgxUserRepository) SoftDelete(ctx context.Context, userID int) error {
+	query := `UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, userID)
+	return err
+}
+
+// Delete removes the user row; sessions, tokens and history cascade with it.
+func (r *PgxUserRepository) Delete(ctx context.Context, userID int) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	return err
+}
+
+// UpdateLastLogin sets last_login to now unless it was updated within minInterval.
+// SKIP LOCKED makes concurrent logins for the same user skip the write instead of
+// queueing behind each other's row lock.
+func (r *PgxUserRepository) UpdateLastLogin(ctx context.Context, userID int, minInterval time.Duration) (bool, error) {
+	query := `
+		UPDATE users SET last_login = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM users
+			WHERE id = $1
+			  AND (last_login IS NULL OR last_login < CURRENT_TIMESTAMP - make_interval(secs => $2))
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	tag, err := r.pool.Exec(ctx, query, userID, minInterval.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UpdatePassword replaces the user's password hash and sets password_changed_at to now.
+func (r *PgxUserRepository) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2, password_changed_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, userID, passwordHash)
+	return err
+}
+
+// RehashPassword replaces oldHash with newHash without touching password_changed_at.
+// Matching on the old hash keeps a concurrent password change from being overwritten.
+func (r *PgxUserRepository) RehashPassword(ctx context.Context, userID int, oldHash, newHash string) error {
+	query := `UPDATE users SET password_hash = $3 WHERE id = $1 AND password_hash = $2`
+	_, err := r.pool.Exec(ctx, query, userID, oldHash, newHash)
+	return err
+}
+
+// ChangeEmail sets a confirmed new email address and marks it verified.
+// Returns false when the email is already registered to another user.
+func (r *PgxUserRepository) ChangeEmail(ctx context.Context, userID int, email string) (bool, error) {
+	query := `UPDATE users SET email = $2, email_verified = true WHERE id = $1`
+
+	_, err := r.pool.Exec(ctx, query, userID, email)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetRole returns the user's role.
+// Returns an empty Role when no user is found.
+func (r *PgxUserRepository) GetRole(ctx context.Context, userID int) (domain.Role, error) {
+	query := `SELECT role FROM users WHERE id = $1`
+
+	var role domain.Role
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+// SetRole changes the user's role.
+func (r *PgxUserRepository) SetRole(ctx context.Context, userID int, role domain.Role) error {
+	query := `UPDATE users SET role = $2 WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, userID, string(role))
+	return err
+}
+
+// SetBackupEmail sets a confirmed backup email for the user.
+// Returns false when another user already has it as backup email.
+func (r *PgxUserRepository) SetBackupEmail(ctx context.Context, userID int, email string) (bool, error) {
+	query := `UPDATE users SET backup_email = $2 WHERE id = $1`
+
+	_, err := r.pool.Exec(ctx, query, userID, email)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ChangeUsername renames the user and records the old name in username_history.
+// Both happen in one statement: every CTE sees the pre-update row, so old.username
+// is the previous name. Returns false when the new username is already taken.
+func (r *PgxUserRepository) ChangeUsername(ctx context.Context, userID int, username string) (bool, error) {
+	// Check both unique indexes up front so the common conflict is no error;
+	// a concurrent rename still ends in uniqueViolation below
+	query := `
+		WITH old AS (SELECT username FROM users WHERE id = $1),
+		renamed AS (
+			UPDATE users SET username = $2 WHERE id = $1
+			  AND NOT EXISTS (SELECT 1 FROM users WHERE (` + usernameTaken("$2") + `) AND id <> $1)
+			RETURNING id
+		)
+		INSERT INTO username_history (user_id, old_username)
+		SELECT renamed.id, old.username FROM renamed, old
+	`
+
+	tag, err := r.pool.Exec(ctx, query, userID, username)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// LastUsernameChange returns when the user last changed their username.
+// Returns (zero, false, nil) when they never did.
+func (r *PgxUserRepository) LastUsernameChange(ctx context.Context, userID int) (time.Time, bool, error) {
+	query := `SELECT MAX(changed_at) FROM username_history WHERE user_id = $1`
+
+	var changedAt *time.Time
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&changedAt); err != nil {
+		return time.Time{}, false, err
+	}
+	if changedAt == nil {
+		return time.Time{}, false, nil
+	}
+
+	return *changedAt, true, nil
+}
+
+// UsernameReleasedSince reports whether a user other than userID gave up
+// username (case-insensitively) after since.
+func (r *PgxUserRepository) UsernameReleasedSince(
+	ctx context.Context, username string, userID int, since time.Time,
+) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM username_history
+			WHERE LOWER(old_username) = LOWER($1) AND user_id <> $2 AND changed_at > $3
+		)
+	`
+
+	var released bool
+	if err := r.pool.QueryRow(ctx, query, username, userID, since).Scan(&released); err != nil {
+		return false, err
+	}
+
+	return released, nil
+}
+
+// MarkEmailVerified sets email_verified to true for the given user.
+func (r *PgxUserRepository) MarkEmailVerified(ctx context.Context, userID int) error {
+	query := `UPDATE users SET email_verified = true WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, userID)
+	return err
+}
+
+// RecordFailedLogin atomically increments the failed-login counter. When it
+// reaches threshold, the account is locked for lockout and the counter restarts.
+// Evaluated in a single UPDATE so concurrent attempts across replicas are all counted.
+// Returns true when this attempt locked the account.
+func (r *PgxUserRepository) RecordFailedLogin(
+	ctx context.Context, userID, threshold int, lockout time.Duration,
+) (bool, error) {
+	query := `
+		UPDATE users SET
+			failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END,
+			locked_until = CASE WHEN failed_login_attempts + 1 >= $2
+			                    THEN CURRENT_TIMESTAMP + make_interval(secs => $3)
+			                    ELSE locked_until END
+		WHERE id = $1
+		RETURNING failed_login_attempts = 0
+	`
+
+	var locked bool
+	err := r.pool.QueryRow(ctx, query, userID, threshold, lockout.Seconds()).Scan(&locked)
+	if err != nil {
+		return false, err
 	}
 
-	return userID, nil
+	return locked, nil
 }
 
-// UpdateLastLogin sets the last_login timestamp to now for the given user.
-func (r *PgxUserRepository) UpdateLastLogin(ctx context.Context, userID int) error {
-	query := `UPDATE users SET last_login = CURRENT_TIMESTAMP WHERE id = $1`
+// ResetFailedLogins clears the failed-login counter and any lock.
+func (r *PgxUserRepository) ResetFailedLogins(ctx context.Context, userID int) error {
+	query := `UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1`
 	_, err := r.pool.Exec(ctx, query, userID)
 	return err
 }
+
+// scanUser scans a row selected with userColumns.
+// Returns (nil, nil) when the query matched no row.
+func scanUser(row pgx.Row) (*domain.UserRow, error) {
+	var u domain.UserRow
+	err := row.Scan(
+		&u.ID, &u.PublicID, &u.Username, &u.Email, &u.PasswordHash,
+		&u.FailedLoginAttempts, &u.LockedUntil, &u.PasswordChangedAt, &u.EmailVerified, &u.BackupEmail, &u.Role,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &u, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// tokenTables maps each token purpose to the table storing its tokens.
+var tokenTables = map[domain.TokenPurpose]string{
+	domain.TokenPasswordReset:     "password_resets",
+	domain.TokenEmailVerification: "email_verifications",
+}
+
+// PgxUserTokenRepository implements domain.UserTokenRepository using pgxpool.
+type PgxUserTokenRepository struct {
+	pool DB
+}
+
+// NewUserTokenRepository creates a new PgxUserTokenRepository.
+func NewUserTokenRepository(pool DB) *PgxUserTokenRepository {
+	return &PgxUserTokenRepository{pool: pool}
+}
+
+// ReplaceToken stores a new token of purpose for the user. The user's unused
+// tokens of that purpose are deleted in the same statement, so concurrent
+// requests cannot leave two of them valid.
+func (r *PgxUserTokenRepository) ReplaceToken(
+	ctx context.Context, userID int, purpose domain.TokenPurpose, tokenHash string, expiresAt time.Time,
+) error {
+	table, ok := tokenTables[purpose]
+	if !ok {
+		return fmt.Errorf("unknown token purpose %q", purpose)
+	}
+
+	query := `
+		WITH superseded AS (
+			DELETE FROM ` + table + ` WHERE user_id = $1 AND used_at IS NULL
+		)
+		INSERT INTO ` + table + ` (user_id, token_hash, expires_at) VALUES ($1, $2, $3)
+	`
+	_, err := r.pool.Exec(ctx, query, userID, tokenHash, expiresAt)
+	return err
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Page sizes for the admin user listing. Larger requested limits are capped to
+// MaxUserPageSize so a single request cannot scan the whole table.
+const (
+	DefaultUserPageSize = 20
+	MaxUserPageSize     = 100
+)
+
+// ListUsers returns one page of users for an admin, with the total user count.
+// limit <= 0 uses DefaultUserPageSize and larger limits are capped to
+// MaxUserPageSize; a negative offset starts from the beginning.
+func (s *AuthService) ListUsers(ctx context.Context, limit, offset int) (*domain.UserPage, error) {
+	if limit <= 0 {
+		limit = DefaultUserPageSize
+	}
+	limit = min(limit, MaxUserPageSize)
+	offset = max(offset, 0)
+
+	ctx, span := middleware.StartSpan(ctx, "auth.list_users", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("page.limit", limit),
+		attribute.Int("page.offset", offset),
+	))
+	defer span.End()
+
+	rows, err := s.users.List(ctx, limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	total, err := s.users.Count(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("count users: %w", err)
+	}
+
+	users := make([]domain.User, 0, len(rows))
+	for i := range rows {
+		users = append(users, *userFromRow(&rows[i]))
+	}
+
+	span.SetAttributes(attribute.Int("user.count", len(users)))
+	return &domain.UserPage{Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// UnlockUser lifts the brute-force lockout of the user with the given public ID on
+// behalf of an admin: locked_until and the failed-login counter are cleared, and so
+// is this replica's login backoff for the username. Nothing else about the account
+// changes. The action is recorded as an account_unlocked security event.
+// Returns ErrUserNotFound when the ID is malformed or no such user exists.
+func (s *AuthService) UnlockUser(ctx context.Context, admin *domain.User, publicID string) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.unlock_user", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", publicID),
+		attribute.String("admin.id", admin.ID),
+	))
+	defer span.End()
+
+	if !isPublicID(publicID) {
+		return fmt.Errorf("lookup user %q: %w", publicID, ErrUserNotFound)
+	}
+
+	row, err := s.users.GetByPublicID(ctx, publicID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query user %q: %w", publicID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("lookup user %q: %w", publicID, ErrUserNotFound)
+	}
+
+	if err := s.users.ResetFailedLogins(ctx, row.ID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("reset failed logins of user %q: %w", publicID, err)
+	}
+	if s.loginBackoff != nil {
+		s.loginBackoff.succeed(row.Username)
+	}
+
+	wasLocked := row.LockedUntil != nil && time.Now().Before(*row.LockedUntil)
+	middleware.RecordSecurityEvent(ctx, "account_unlocked",
+		attribute.String("user.id", row.PublicID),
+		attribute.String("admin.id", admin.ID),
+		attribute.Bool("account.was_locked", wasLocked),
+		attribute.Int("failed_attempts", row.FailedLoginAttempts),
+	)
+	return nil
+}
@@ -0,0 +1,298 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LoginCheck names one pre-session check run by Authenticate.
+// The enabled checks and their order come from Options.LoginChecks (LOGIN_CHECKS).
+type LoginCheck string
+
+const (
+	// CheckNotLocked rejects accounts locked by repeated failed logins (ErrAccountLocked).
+	CheckNotLocked LoginCheck = "not_locked"
+	// CheckPassword verifies the password and counts failures toward lockout
+	// (ErrInvalidCredentials). It cannot be disabled.
+	CheckPassword LoginCheck = "password"
+	// CheckPasswordAge rejects passwords older than Options.PasswordMaxAge (ErrPasswordExpired).
+	CheckPasswordAge LoginCheck = "password_age"
+)
+
+// DefaultLoginChecks is the check order used when Options.LoginChecks is empty.
+// Lockout runs before the password so a locked account cannot keep guessing;
+// password age runs after it so expiry is never revealed without the password.
+var DefaultLoginChecks = []LoginCheck{CheckNotLocked, CheckPassword, CheckPasswordAge}
+
+// NextStep tells the caller what must happen before a session may be issued.
+type NextStep string
+
+const (
+	// StepNone means all checks passed and a session can be issued.
+	StepNone NextStep = ""
+	// StepTOTP means the password was correct but the user has confirmed 2FA and
+	// sent neither a TOTP nor a backup code; the client must retry with one.
+	StepTOTP NextStep = "totp"
+)
+
+// AuthDecision is the outcome of an Authenticate call that rejected nothing.
+// User is always set; Next says whether a session may be issued yet.
+type AuthDecision struct {
+	User *domain.UserRow
+	Next NextStep
+}
+
+// loginAttempt is the state shared by the checks of one Authenticate call.
+type loginAttempt struct {
+	req  domain.LoginRequest
+	row  *domain.UserRow
+	span trace.Span
+}
+
+// loginCheckFunc runs one check. A non-nil error rejects the login and stops the pipeline.
+type loginCheckFunc func(ctx context.Context, s *AuthService, a *loginAttempt) error
+
+// loginChecks maps each LoginCheck to its implementation.
+var loginChecks = map[LoginCheck]loginCheckFunc{
+	CheckNotLocked:   checkNotLocked,
+	CheckPassword:    checkPassword,
+	CheckPasswordAge: checkPasswordAge,
+}
+
+// Authenticate runs every pre-session check for a login attempt and returns the
+// decision. The user lookup always runs first (every check needs the user);
+// the configured checks then run in order and the first failure short-circuits.
+// CheckPassword always runs, even if it is left out of the configured list.
+// The TOTP second factor is not configurable: it always runs last for users
+// with confirmed 2FA, so its requirement is never revealed without the password.
+// A missing code is not an error: the decision then asks for StepTOTP.
+//
+// Before any of that, a username with repeated recent failures is rejected with
+// a *RetryAfterError until its backoff delay has passed (see loginBackoff).
+func (s *AuthService) Authenticate(ctx context.Context, req domain.LoginRequest) (*AuthDecision, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.authenticate", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("username", req.Username),
+	))
+	defer span.End()
+
+	if s.loginBackoff == nil {
+		return s.authenticate(ctx, span, req)
+	}
+
+	now := time.Now()
+	if wait := s.loginBackoff.wait(req.Username, now); wait > 0 {
+		span.AddEvent("authentication.backoff")
+		return nil, fmt.Errorf("authenticate user %q: %w", req.Username, &RetryAfterError{RetryAfter: wait})
+	}
+
+	decision, err := s.authenticate(ctx, span, req)
+	switch {
+	case err == nil && decision.Next == StepNone:
+		s.loginBackoff.succeed(req.Username)
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidTOTPCode):
+		s.loginBackoff.fail(req.Username, now)
+	}
+	return decision, err
+}
+
+// lookupLoginUser finds the account for the login identifier, which may be a
+// username or an email address. Identifiers without "@" can only be usernames
+// (see usernamePattern). Identifiers with "@" are looked up as both an email
+// and a username, since accounts created before the username rules may have one
+// containing "@". Both queries always run, so response time does not reveal
+// which one matched; the email match wins.
+// Returns (nil, nil) when no user is found.
+func (s *AuthService) lookupLoginUser(ctx context.Context, identifier string) (*domain.UserRow, error) {
+	username := normalizeUsername(identifier)
+	if !strings.Contains(identifier, "@") {
+		return s.users.GetByUsername(ctx, username)
+	}
+
+	byEmail, err := s.users.GetByEmail(ctx, normalizeEmail(identifier))
+	if err != nil {
+		return nil, err
+	}
+	byUsername, err := s.users.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if byEmail != nil {
+		return byEmail, nil
+	}
+	return byUsername, nil
+}
+
+// authenticate runs the user lookup and login checks for Authenticate.
+func (s *AuthService) authenticate(ctx context.Context, span trace.Span, req domain.LoginRequest) (*AuthDecision, error) {
+	row, err := s.lookupLoginUser(ctx, req.Username)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("query user %q: %w", req.Username, err)
+	}
+	if row == nil {
+		span.AddEvent("authentication.failed")
+		return nil, fmt.Errorf("authenticate user %q: %w", req.Username, ErrUserNotFound)
+	}
+
+	attempt := &loginAttempt{req: req, row: row, span: span}
+	for _, name := range s.loginCheckOrder() {
+		if err := loginChecks[name](ctx, s, attempt); err != nil {
+			span.SetAttributes(attribute.String("auth.failed_check", string(name)))
+			return nil, fmt.Errorf("authenticate user %q: %w", req.Username, err)
+		}
+	}
+	next, err := s.checkLoginTOTP(ctx, attempt)
+	if err != nil {
+		span.SetAttributes(attribute.String("auth.failed_check", "totp"))
+		return nil, fmt.Errorf("authenticate user %q: %w", req.Username, err)
+	}
+	if next != StepNone {
+		span.SetAttributes(attribute.String("auth.next_step", string(next)))
+		return &AuthDecision{User: row, Next: next}, nil
+	}
+
+	// Best-effort, and only once every factor passed: clearing the counter on the
+	// password alone would let a second factor be guessed without ever locking.
+	if row.FailedLoginAttempts > 0 || row.LockedUntil != nil {
+		if resetErr := s.users.ResetFailedLogins(ctx, row.ID); resetErr != nil {
+			span.RecordError(fmt.Errorf("reset failed logins: %w", resetErr))
+		}
+	}
+
+	s.rehashPassword(ctx, span, row, req.Password)
+
+	return &AuthDecision{User: row, Next: StepNone}, nil
+}
+
+// loginCheckOrder returns the configured checks, falling back to the defaults.
+// Unknown names are skipped (config validation rejects them at startup) and the
+// password check is appended if it was left out.
+func (s *AuthService) loginCheckOrder() []LoginCheck {
+	configured := s.opts.LoginChecks
+	if len(configured) == 0 {
+		configured = DefaultLoginChecks
+	}
+
+	order := make([]LoginCheck, 0, len(configured)+1)
+	hasPassword := false
+	for _, name := range configured {
+		if _, ok := loginChecks[name]; !ok {
+			continue
+		}
+		hasPassword = hasPassword || name == CheckPassword
+		order = append(order, name)
+	}
+	if !hasPassword {
+		order = append(order, CheckPassword)
+	}
+	return order
+}
+
+// checkNotLocked rejects an account whose lockout window has not passed.
+func checkNotLocked(_ context.Context, _ *AuthService, a *loginAttempt) error {
+	if a.row.LockedUntil != nil && time.Now().Before(*a.row.LockedUntil) {
+		a.span.AddEvent("authentication.locked")
+		return fmt.Errorf("locked until %v: %w", *a.row.LockedUntil, ErrAccountLocked)
+	}
+	return nil
+}
+
+// checkPassword verifies the password. A bad password counts toward lockout;
+// Authenticate clears earlier failures once the whole login succeeds.
+func checkPassword(ctx context.Context, s *AuthService, a *loginAttempt) error {
+	err := comparePassword(a.row.PasswordHash, a.req.Password)
+	if err != nil {
+		a.span.AddEvent("authentication.failed")
+		if s.recordFailedLogin(ctx, a.span, a.row.ID) {
+			return ErrAccountLocked
+		}
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// checkPasswordAge rejects a password older than PasswordMaxAge.
+// Rows without password_changed_at never expire.
+func checkPasswordAge(_ context.Context, s *AuthService, a *loginAttempt) error {
+	if s.opts.PasswordMaxAge <= 0 || a.row.PasswordChangedAt == nil {
+		return nil
+	}
+	if time.Since(*a.row.PasswordChangedAt) > s.opts.PasswordMaxAge {
+		a.span.AddEvent("authentication.password_expired")
+		return ErrPasswordExpired
+	}
+	return nil
+}
+
+// rehashPassword upgrades a stored hash made with another algorithm or other
+// parameters than the configured hasher, now that the plaintext is known to be
+// correct. It is a no-op for up-to-date hashes and best-effort: a failure is
+// recorded on the span and never blocks the login.
+func (s *AuthService) rehashPassword(ctx context.Context, span trace.Span, row *domain.UserRow, password string) {
+	if !s.hasher.NeedsRehash(row.PasswordHash) {
+		return
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		span.RecordError(fmt.Errorf("rehash password: %w", err))
+		return
+	}
+	if err := s.users.RehashPassword(ctx, row.ID, row.PasswordHash, hash); err != nil {
+		span.RecordError(fmt.Errorf("store rehashed password: %w", err))
+		return
+	}
+	span.AddEvent("authentication.password_rehashed")
+}
+
+// verifyStepUp re-checks the password of an already authenticated user before a
+// sensitive change, so a stolen session alone is not enough to make it. Wrong
+// passwords count toward lockout exactly like failed logins, and a locked account
+// is refused outright; otherwise the step-up would be an unlimited password oracle.
+// Returns ErrAccountLocked or ErrInvalidCredentials.
+func (s *AuthService) verifyStepUp(ctx context.Context, span trace.Span, row *domain.UserRow, password string) error {
+	if row.LockedUntil != nil && time.Now().Before(*row.LockedUntil) {
+		span.AddEvent("step_up.locked")
+		return fmt.Errorf("locked until %v: %w", *row.LockedUntil, ErrAccountLocked)
+	}
+	if err := comparePassword(row.PasswordHash, password); err != nil {
+		span.AddEvent("step_up.wrong_password")
+		if s.recordFailedLogin(ctx, span, row.ID) {
+			return ErrAccountLocked
+		}
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// recordFailedLogin counts a bad password toward lockout and reports whether
+// this attempt locked the account. Failures are recorded on the span only:
+// a counter outage must not turn wrong passwords into 500s.
+func (s *AuthService) recordFailedLogin(ctx context.Context, span trace.Span, userID int) bool {
+	if s.opts.LockoutThreshold <= 0 {
+		return false
+	}
+
+	locked, err := s.users.RecordFailedLogin(ctx, userID, s.opts.LockoutThreshold, s.opts.LockoutDuration)
+	if err != nil {
+		span.RecordError(fmt.Errorf("record failed login: %w", err))
+		return false
+	}
+	if locked {
+		middleware.RecordSecurityEvent(ctx, "account_locked",
+			attribute.Int("user_id", userID),
+			attribute.Int("failed_attempts", s.opts.LockoutThreshold),
+		)
+	}
+
+	return locked
+}
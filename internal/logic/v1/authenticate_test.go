@@ -0,0 +1,173 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const stepUpTestPassword = "correct-horse-battery"
+
+func TestStepUpWrongPasswordCountsTowardLockout(t *testing.T) {
+	svc, repos := newTestService(t, Options{LockoutThreshold: 3, LockoutDuration: time.Hour})
+	row := repos.Users.AddUser(t, "alice", "alice@example.com", stepUpTestPassword, bcrypt.MinCost)
+	requester := userFromRow(row)
+	ctx := context.Background()
+
+	// Alternate endpoints: every step-up shares the same failure counter
+	attempts := []func(password string) error{
+		func(password string) error {
+			return svc.ChangeUsername(ctx, requester, domain.ChangeUsernameRequest{Username: "mallory", CurrentPassword: password})
+		},
+		func(password string) error {
+			return svc.RequestEmailChange(ctx, requester, domain.ChangeEmailRequest{NewEmail: "mallory@example.com", CurrentPassword: password})
+		},
+		func(password string) error {
+			return svc.ChangeUsername(ctx, requester, domain.ChangeUsernameRequest{Username: "mallory", CurrentPassword: password})
+		},
+	}
+	for i, attempt := range attempts[:2] {
+		if err := attempt("wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: error = %v, want %v", i+1, err, ErrInvalidCredentials)
+		}
+	}
+	if err := attempts[2]("wrong-password"); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("attempt at threshold: error = %v, want %v", err, ErrAccountLocked)
+	}
+
+	// Once locked, even the right password is refused
+	if err := attempts[0](stepUpTestPassword); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("right password while locked: error = %v, want %v", err, ErrAccountLocked)
+	}
+	if _, err := svc.Login(ctx, domain.LoginRequest{Username: "alice", Password: stepUpTestPassword}, domain.ClientInfo{}); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("login after step-up lockout: error = %v, want %v", err, ErrAccountLocked)
+	}
+
+	got, _ := repos.Users.GetByID(ctx, row.ID)
+	if got.Username != "alice" {
+		t.Errorf("username = %q after failed step-ups, want %q", got.Username, "alice")
+	}
+}
+
+func TestStepUpRightPasswordPasses(t *testing.T) {
+	svc, repos := newTestService(t, Options{LockoutThreshold: 3, LockoutDuration: time.Hour})
+	row := repos.Users.AddUser(t, "alice", "alice@example.com", stepUpTestPassword, bcrypt.MinCost)
+
+	err := svc.ChangeUsername(context.Background(), userFromRow(row), domain.ChangeUsernameRequest{
+		Username:        "alice2",
+		CurrentPassword: stepUpTestPassword,
+	})
+	if err != nil {
+		t.Fatalf("ChangeUsername: %v", err)
+	}
+}
+
+func TestAuthenticateChecks(t *testing.T) {
+	totpKey := bytes.Repeat([]byte{7}, 32)
+	addAlice := func(t *testing.T, _ *AuthService, repos *memory.Repos) {
+		repos.Users.AddUser(t, "alice", "alice@example.com", twoFactorTestPassword, bcrypt.MinCost)
+	}
+	lockAlice := func(t *testing.T, svc *AuthService, repos *memory.Repos) {
+		addAlice(t, svc, repos)
+		row, _ := repos.Users.GetByUsername(context.Background(), "alice")
+		if _, err := repos.Users.RecordFailedLogin(context.Background(), row.ID, 1, time.Hour); err != nil {
+			t.Fatalf("lock alice: %v", err)
+		}
+	}
+	enrollAlice := func(t *testing.T, svc *AuthService, repos *memory.Repos) {
+		enrollTwoFactor(t, svc, repos)
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		setup    func(*testing.T, *AuthService, *memory.Repos)
+		username string
+		password string
+		totpCode string
+		wantErr  error
+		wantNext NextStep
+	}{
+		{name: "unknown user", setup: addAlice, username: "bob",
+			password: twoFactorTestPassword, wantErr: ErrUserNotFound},
+		{name: "not_locked rejects a locked account", setup: lockAlice,
+			password: twoFactorTestPassword, wantErr: ErrAccountLocked},
+		{name: "not_locked can be disabled", opts: Options{LoginChecks: []LoginCheck{CheckPassword}},
+			setup: lockAlice, password: twoFactorTestPassword},
+		{name: "password rejects a wrong password", setup: addAlice,
+			password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "password_age rejects an expired password", opts: Options{PasswordMaxAge: time.Nanosecond},
+			setup: addAlice, password: twoFactorTestPassword, wantErr: ErrPasswordExpired},
+		{name: "password_age runs after the password", opts: Options{PasswordMaxAge: time.Nanosecond},
+			setup: addAlice, password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "totp asks for a code", opts: Options{TOTPEncryptionKey: totpKey},
+			setup: enrollAlice, password: twoFactorTestPassword, wantNext: StepTOTP},
+		{name: "totp rejects a wrong code", opts: Options{TOTPEncryptionKey: totpKey},
+			setup: enrollAlice, password: twoFactorTestPassword, totpCode: "000000", wantErr: ErrInvalidTOTPCode},
+		{name: "totp runs after the password", opts: Options{TOTPEncryptionKey: totpKey},
+			setup: enrollAlice, password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "all checks pass", setup: addAlice, password: twoFactorTestPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, tt.opts)
+			tt.setup(t, svc, repos)
+			username := tt.username
+			if username == "" {
+				username = "alice"
+			}
+
+			decision, err := svc.Authenticate(context.Background(), domain.LoginRequest{
+				Username: username,
+				Password: tt.password,
+				TOTPCode: tt.totpCode,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if decision.Next != tt.wantNext {
+				t.Errorf("next step = %q, want %q", decision.Next, tt.wantNext)
+			}
+			if decision.User == nil || decision.User.Username != "alice" {
+				t.Errorf("decision user = %+v, want alice", decision.User)
+			}
+		})
+	}
+}
+
+func TestAuthenticateTOTPStepKeepsFailedLogins(t *testing.T) {
+	svc, repos := newTestService(t, Options{
+		TOTPEncryptionKey: bytes.Repeat([]byte{7}, 32),
+		LockoutThreshold:  5,
+		LockoutDuration:   time.Minute,
+	})
+	enrollTwoFactor(t, svc, repos)
+	ctx := context.Background()
+
+	if _, err := svc.Authenticate(ctx, domain.LoginRequest{Username: "alice", Password: "wrong-password"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	decision, err := svc.Authenticate(ctx, domain.LoginRequest{Username: "alice", Password: twoFactorTestPassword})
+	if err != nil || decision.Next != StepTOTP {
+		t.Fatalf("right password without code: decision = %+v, error = %v, want StepTOTP", decision, err)
+	}
+
+	// The password alone must not clear failures: the code is still unproven
+	row, _ := repos.Users.GetByUsername(ctx, "alice")
+	if row.FailedLoginAttempts != 1 {
+		t.Errorf("failed login attempts = %d, want 1", row.FailedLoginAttempts)
+	}
+}
@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// backupCodeCount is how many recovery codes each (re)generation issues.
+	backupCodeCount = 10
+	// backupCodeLength is the number of characters per code, shown as xxxxx-xxxxx.
+	backupCodeLength = 10
+	// backupCodeAlphabet leaves out look-alike characters (0/o, 1/l/i) so codes
+	// can be copied from paper without mistakes.
+	backupCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// RegenerateBackupCodes replaces the requester's backup codes with a fresh set
+// and returns the plaintext codes, which are never retrievable again.
+// Returns ErrTwoFactorNotEnrolled unless the requester has confirmed 2FA.
+func (s *AuthService) RegenerateBackupCodes(ctx context.Context, requester *domain.User) ([]string, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.regenerate_backup_codes", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	enrollment, err := s.totp.GetByUserID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("get totp enrollment for user %s: %w", requester.ID, err)
+	}
+	if enrollment == nil || enrollment.ConfirmedAt == nil {
+		return nil, fmt.Errorf("regenerate backup codes for user %s: %w", requester.ID, ErrTwoFactorNotEnrolled)
+	}
+
+	codes, err := s.issueBackupCodes(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("regenerate backup codes for user %s: %w", requester.ID, err)
+	}
+
+	middleware.RecordSecurityEvent(ctx, "backup_codes_regenerated", attribute.String("user.id", requester.ID))
+	return codes, nil
+}
+
+// issueBackupCodes generates a new set of backup codes for the user, stores
+// their hashes (invalidating any previous set) and returns the plaintext codes.
+func (s *AuthService) issueBackupCodes(ctx context.Context, userID int) ([]string, error) {
+	codes := make([]string, backupCodeCount)
+	hashes := make([]string, backupCodeCount)
+	for i := range codes {
+		code, err := newBackupCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizeBackupCode(code)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("hash backup code: %w", err)
+		}
+		codes[i], hashes[i] = code, string(hash)
+	}
+
+	if err := s.backupCodes.Replace(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("store backup codes: %w", err)
+	}
+	return codes, nil
+}
+
+// useBackupCode consumes the user's backup code matching code.
+// Returns false when no unused code matches.
+func (s *AuthService) useBackupCode(ctx context.Context, userID int, code string) (bool, error) {
+	stored, err := s.backupCodes.ListUnused(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("list backup codes: %w", err)
+	}
+
+	normalized := []byte(normalizeBackupCode(code))
+	for _, candidate := range stored {
+		if bcrypt.CompareHashAndPassword([]byte(candidate.CodeHash), normalized) != nil {
+			continue
+		}
+		// Consume atomically so two concurrent logins cannot share one code
+		ok, err := s.backupCodes.Consume(ctx, candidate.ID)
+		if err != nil {
+			return false, fmt.Errorf("consume backup code: %w", err)
+		}
+		return ok, nil
+	}
+	return false, nil
+}
+
+// newBackupCode returns a random code formatted as xxxxx-xxxxx.
+func newBackupCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(backupCodeAlphabet)))
+
+	var sb strings.Builder
+	for i := range backupCodeLength {
+		if i == backupCodeLength/2 {
+			sb.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", fmt.Errorf("generate backup code: %w", err)
+		}
+		sb.WriteByte(backupCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// normalizeBackupCode makes codes case-, space- and dash-insensitive.
+func normalizeBackupCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
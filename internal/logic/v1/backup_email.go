@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// A backup email is a second address used only for account recovery: when the
+// primary mailbox is lost, forgot-password accepts the backup address and sends
+// the reset link there (Options.PasswordResetBackupEmail). It is stored on the
+// user only once confirmed, so an unconfirmed address can never receive a reset.
+
+// RequestBackupEmail sends a confirmation link to the requested backup address.
+// The address takes effect only when VerifyBackupEmail redeems the link; until
+// then any earlier backup email stays in place.
+// Returns ErrInvalidCredentials for a wrong password and ErrEmailExists when the
+// address is the user's primary email or registered to another account.
+func (s *AuthService) RequestBackupEmail(
+	ctx context.Context, requester *domain.User, req domain.SetBackupEmailRequest,
+) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.request_backup_email", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	req.BackupEmail = normalizeEmail(req.BackupEmail)
+
+	row, err := s.users.GetByID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query user %s: %w", requester.ID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("lookup user %s: %w", requester.ID, ErrUserNotFound)
+	}
+
+	if err := s.verifyStepUp(ctx, span, row, req.CurrentPassword); err != nil {
+		return fmt.Errorf("set backup email for user %s: %w", requester.ID, err)
+	}
+	if strings.EqualFold(req.BackupEmail, row.BackupEmail) {
+		return nil
+	}
+
+	// Recovery lookups try primary emails first, so a backup equal to any
+	// primary address would be unreachable or, worse, point at another account
+	if strings.EqualFold(req.BackupEmail, row.Email) {
+		return fmt.Errorf("set backup email for user %s: %w", requester.ID, ErrEmailExists)
+	}
+	exists, err := s.users.ExistsByEmail(ctx, req.BackupEmail)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("check email: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("set backup email for user %s: %w", requester.ID, ErrEmailExists)
+	}
+
+	go s.deliverEmailChange(context.WithoutCancel(ctx), row, domain.EmailChangeBackup, req.BackupEmail)
+
+	span.AddEvent("backup_email.requested")
+	return nil
+}
+
+// VerifyBackupEmail redeems a backup email confirmation token and stores the
+// address as the owner's backup email. Returns ErrInvalidVerificationToken for
+// unknown, expired or used tokens, and ErrEmailExists when another account
+// confirmed the same backup address first.
+func (s *AuthService) VerifyBackupEmail(ctx context.Context, token string) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.verify_backup_email", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	change, err := s.emailChanges.Consume(ctx, domain.EmailChangeBackup, hashOpaqueToken(token))
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("consume backup email token: %w", err)
+	}
+	if change == nil {
+		span.SetAttributes(attribute.Bool("verification.valid", false))
+		return fmt.Errorf("verify backup email: %w", ErrInvalidVerificationToken)
+	}
+	span.SetAttributes(attribute.Int("user.id", change.UserID))
+
+	set, err := s.users.SetBackupEmail(ctx, change.UserID, change.NewEmail)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("set backup email for user %d: %w", change.UserID, err)
+	}
+	if !set {
+		return fmt.Errorf("verify backup email for user %d: %w", change.UserID, ErrEmailExists)
+	}
+
+	middleware.RecordSecurityEvent(ctx, "backup_email_set", attribute.Int("user_id", change.UserID))
+	return nil
+}
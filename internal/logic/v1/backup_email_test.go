@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	backupTestPassword = "correct-horse-battery-staple"
+	backupTestPrimary  = "alice@example.com"
+	backupTestAddress  = "alice.recovery@example.org"
+)
+
+// requestBackupEmail asks to add alice's backup address and returns the emailed token.
+func requestBackupEmail(t *testing.T, svc *AuthService, repos *memory.Repos) string {
+	t.Helper()
+
+	row := repos.Users.AddUser(t, "alice", backupTestPrimary, backupTestPassword, bcrypt.MinCost)
+	err := svc.RequestBackupEmail(context.Background(), userFromRow(row), domain.SetBackupEmailRequest{
+		BackupEmail:     backupTestAddress,
+		CurrentPassword: backupTestPassword,
+	})
+	if err != nil {
+		t.Fatalf("request backup email: %v", err)
+	}
+	return repos.Notifier.WaitFor(t, "backup_email", backupTestAddress).Token
+}
+
+func TestPasswordResetIgnoresUnconfirmedBackupEmail(t *testing.T) {
+	svc, repos := newTestService(t, Options{PasswordResetBackupEmail: true})
+	requestBackupEmail(t, svc, repos)
+	ctx := context.Background()
+
+	// RequestPasswordReset hands everything to deliverPasswordReset in the
+	// background; call it directly to know it has finished.
+	svc.deliverPasswordReset(ctx, backupTestAddress)
+	if sent := repos.Notifier.Messages("password_reset"); len(sent) != 0 {
+		t.Errorf("password resets sent = %v, want none", sent)
+	}
+}
+
+func TestPasswordResetUsesConfirmedBackupEmail(t *testing.T) {
+	svc, repos := newTestService(t, Options{PasswordResetBackupEmail: true})
+	token := requestBackupEmail(t, svc, repos)
+	ctx := context.Background()
+
+	if err := svc.VerifyBackupEmail(ctx, token); err != nil {
+		t.Fatalf("verify backup email: %v", err)
+	}
+	svc.RequestPasswordReset(ctx, backupTestAddress)
+	repos.Notifier.WaitFor(t, "password_reset", backupTestAddress)
+}
+
+func TestPasswordResetBackupEmailDisabled(t *testing.T) {
+	svc, repos := newTestService(t, Options{PasswordResetBackupEmail: false})
+	token := requestBackupEmail(t, svc, repos)
+	ctx := context.Background()
+
+	if err := svc.VerifyBackupEmail(ctx, token); err != nil {
+		t.Fatalf("verify backup email: %v", err)
+	}
+	svc.deliverPasswordReset(ctx, backupTestAddress)
+	if sent := repos.Notifier.Messages("password_reset"); len(sent) != 0 {
+		t.Errorf("password resets sent = %v, want none", sent)
+	}
+	svc.RequestPasswordReset(ctx, backupTestPrimary)
+	repos.Notifier.WaitFor(t, "password_reset", backupTestPrimary)
+}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// calibrationPassword is hashed while calibrating; its value does not affect timing.
+const calibrationPassword = "bcrypt-calibration-password"
+
+// CalibrateBcryptCost picks the highest bcrypt cost whose hash time stays within
+// target on this machine, never going below minCost. It returns the chosen cost
+// and the hash time measured for it.
+//
+// Each cost step doubles the work, so costs are tried upward from minCost and
+// the search stops at the first one over budget. When even minCost exceeds the
+// target, minCost is returned anyway: the floor is a security setting and wins
+// over latency. Run it once at startup; it takes up to about twice target.
+func CalibrateBcryptCost(target time.Duration, minCost int) (int, time.Duration) {
+	minCost = max(minCost, bcrypt.MinCost)
+
+	cost := minCost
+	took := timeBcrypt(cost)
+	for cost < bcrypt.MaxCost {
+		// Skip the next measurement when it is bound to exceed the budget
+		if took*2 > target {
+			break
+		}
+		next := timeBcrypt(cost + 1)
+		if next > target {
+			break
+		}
+		cost, took = cost+1, next
+	}
+	return cost, took
+}
+
+// timeBcrypt measures one hash at cost.
+func timeBcrypt(cost int) time.Duration {
+	start := time.Now()
+	_, _ = bcrypt.GenerateFromPassword([]byte(calibrationPassword), cost)
+	return time.Since(start)
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCalibrateBcryptCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  time.Duration
+		minCost int
+		// wantFloor expects exactly the floor back: no cost fits the target
+		wantFloor bool
+	}{
+		{"floor wins over an impossible target", time.Nanosecond, 6, true},
+		{"floor below bcrypt minimum is raised", time.Nanosecond, 1, true},
+		{"budget allows more than the floor", 50 * time.Millisecond, bcrypt.MinCost, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, took := CalibrateBcryptCost(tt.target, tt.minCost)
+
+			floor := max(tt.minCost, bcrypt.MinCost)
+			if tt.wantFloor && cost != floor {
+				t.Fatalf("cost = %d, want the floor %d", cost, floor)
+			}
+			if cost < floor || cost > bcrypt.MaxCost {
+				t.Fatalf("cost = %d, want between %d and %d", cost, floor, bcrypt.MaxCost)
+			}
+			if took <= 0 {
+				t.Errorf("measured hash time = %s, want > 0", took)
+			}
+			// Going above the floor is only allowed while the hash fits the budget
+			if cost > floor && took > tt.target {
+				t.Errorf("cost %d took %s, over the %s target", cost, took, tt.target)
+			}
+		})
+	}
+}
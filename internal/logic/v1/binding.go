@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// BindingMode controls how strictly a session is tied to the client it was issued to.
+type BindingMode string
+
+const (
+	// BindingOff never records or checks a client binding.
+	BindingOff BindingMode = "off"
+	// BindingLax rejects a bound session only when the client presents a different
+	// device ID; requests without a device ID are still accepted.
+	BindingLax BindingMode = "lax"
+	// BindingStrict rejects a bound session unless the client presents the same device ID.
+	BindingStrict BindingMode = "strict"
+)
+
+// sessionBinding returns the value stored on a new session for this client.
+// The device ID is hashed so the raw identifier never lands in the database.
+func (m BindingMode) sessionBinding(client domain.ClientInfo) string {
+	if m == BindingOff || m == "" || client.DeviceID == "" {
+		return ""
+	}
+	return hashDeviceID(client.DeviceID)
+}
+
+// allows reports whether a session with the stored binding may be used by this client.
+// Sessions created without a binding are never rejected, so enabling binding does
+// not log out existing users. The device ID is stable across network changes,
+// which avoids false rejections when a legitimate client roams between IPs.
+func (m BindingMode) allows(binding string, client domain.ClientInfo) bool {
+	if m == BindingOff || m == "" || binding == "" {
+		return true
+	}
+	if client.DeviceID == "" {
+		return m != BindingStrict
+	}
+	return subtle.ConstantTimeCompare([]byte(binding), []byte(hashDeviceID(client.DeviceID))) == 1
+}
+
+func hashDeviceID(deviceID string) string {
+	sum := sha256.Sum256([]byte(deviceID))
+	return hex.EncodeToString(sum[:])
+}
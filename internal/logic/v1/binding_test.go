@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSessionBinding(t *testing.T) {
+	const device, otherDevice = "device-1", "device-2"
+
+	tests := []struct {
+		name     string
+		mode     BindingMode
+		issuedTo string // device ID at login
+		usedFrom string // device ID presented later
+		wantErr  error
+	}{
+		{"off ignores another device", BindingOff, device, otherDevice, nil},
+		{"lax accepts the same device", BindingLax, device, device, nil},
+		{"lax rejects another device", BindingLax, device, otherDevice, ErrSessionBindingMismatch},
+		{"lax accepts a missing device ID", BindingLax, device, "", nil},
+		{"strict accepts the same device", BindingStrict, device, device, nil},
+		{"strict rejects another device", BindingStrict, device, otherDevice, ErrSessionBindingMismatch},
+		{"strict rejects a missing device ID", BindingStrict, device, "", ErrSessionBindingMismatch},
+		{"unbound session is never rejected", BindingStrict, "", otherDevice, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, Options{SessionBinding: tt.mode})
+			repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+			ctx := context.Background()
+
+			result, err := svc.Login(ctx, domain.LoginRequest{Username: "alice", Password: "correct-horse-battery"},
+				domain.ClientInfo{DeviceID: tt.issuedTo, IP: "192.0.2.1"})
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+
+			// The IP changes too: only the device ID may decide
+			_, err = svc.GetUserByToken(ctx, result.Session.Token, domain.ClientInfo{DeviceID: tt.usedFrom, IP: "198.51.100.7"})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("GetUserByToken: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUserByToken: error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSessionBindingStoresOnlyHash(t *testing.T) {
+	binding := BindingLax.sessionBinding(domain.ClientInfo{DeviceID: "device-1"})
+	if binding == "" || binding == "device-1" {
+		t.Errorf("binding = %q, want a hash of the device ID", binding)
+	}
+}
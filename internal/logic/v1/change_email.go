@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestEmailChange starts a two-step email change: it stores the new address
+// as pending and sends a confirmation link to it. The current address stays in
+// use until VerifyEmailChange redeems the link, so a typo or an attacker with a
+// stolen session cannot take over where account mail goes.
+// Returns ErrInvalidCredentials for a wrong password and ErrEmailExists when
+// the new address is already registered.
+func (s *AuthService) RequestEmailChange(
+	ctx context.Context, requester *domain.User, req domain.ChangeEmailRequest,
+) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.request_email_change", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	row, err := s.users.GetByID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query user %s: %w", requester.ID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("lookup user %s: %w", requester.ID, ErrUserNotFound)
+	}
+
+	if err := s.verifyStepUp(ctx, span, row, req.CurrentPassword); err != nil {
+		return fmt.Errorf("change email for user %s: %w", requester.ID, err)
+	}
+	if _, err := s.startEmailChange(ctx, row, normalizeEmail(req.NewEmail)); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("change email for user %s: %w", requester.ID, err)
+	}
+	return nil
+}
+
+// startEmailChange sends a confirmation link for newEmail on behalf of a user whose
+// password was already re-checked. Reports whether a change is now pending:
+// changing to the current address is a no-op.
+func (s *AuthService) startEmailChange(ctx context.Context, row *domain.UserRow, newEmail string) (bool, error) {
+	if strings.EqualFold(newEmail, row.Email) {
+		return false, nil
+	}
+
+	exists, err := s.users.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return false, fmt.Errorf("check email: %w", err)
+	}
+	if exists {
+		return false, ErrEmailExists
+	}
+
+	// Send in the background, like verification emails, so mail latency never blocks the request
+	go s.deliverEmailChange(context.WithoutCancel(ctx), row, domain.EmailChangePrimary, newEmail)
+
+	trace.SpanFromContext(ctx).AddEvent("email_change.requested")
+	return true, nil
+}
+
+// deliverEmailChange stores a pending change of the purpose address to newEmail
+// and sends its confirmation token to newEmail. It runs after the request has
+// been answered, so failures can only be logged; the user can request it again.
+func (s *AuthService) deliverEmailChange(
+	ctx context.Context, row *domain.UserRow, purpose domain.EmailChangePurpose, newEmail string,
+) {
+	ctx, cancel := context.WithTimeout(ctx, tokenDeliveryTimeout)
+	defer cancel()
+
+	ctx, span := middleware.StartSpan(ctx, "auth.deliver_email_change", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", row.PublicID),
+		attribute.String("email_change.purpose", string(purpose)),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Email change token generation failed")
+		return
+	}
+
+	ttl := s.opts.EmailVerificationTTL
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	if err := s.emailChanges.Create(ctx, row.ID, purpose, newEmail, tokenHash, expiresAt); err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Email change storage failed")
+		return
+	}
+
+	send := s.notifier.SendEmailChangeVerification
+	if purpose == domain.EmailChangeBackup {
+		send = s.notifier.SendBackupEmailVerification
+	}
+	if err := send(ctx, newEmail, token, expiresAt); err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Email change confirmation delivery failed")
+		return
+	}
+
+	span.AddEvent("email_change.sent")
+}
+
+// VerifyEmailChange redeems an email change token: the pending address replaces
+// the current one and is marked verified. Returns ErrInvalidVerificationToken
+// for unknown, expired or used tokens, and ErrEmailExists when the address was
+// registered by someone else in the meantime.
+func (s *AuthService) VerifyEmailChange(ctx context.Context, token string) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.verify_email_change", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	change, err := s.emailChanges.Consume(ctx, domain.EmailChangePrimary, hashOpaqueToken(token))
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("consume email change token: %w", err)
+	}
+	if change == nil {
+		span.SetAttributes(attribute.Bool("verification.valid", false))
+		return fmt.Errorf("verify email change: %w", ErrInvalidVerificationToken)
+	}
+	span.SetAttributes(attribute.Int("user.id", change.UserID))
+
+	changed, err := s.users.ChangeEmail(ctx, change.UserID, change.NewEmail)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("update email for user %d: %w", change.UserID, err)
+	}
+	if !changed {
+		return fmt.Errorf("verify email change for user %d: %w", change.UserID, ErrEmailExists)
+	}
+
+	middleware.RecordSecurityEvent(ctx, "email_changed", attribute.Int("user_id", change.UserID))
+	return nil
+}
@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const changeEmailTestPassword = "correct-horse-battery-staple"
+
+// requestEmailChange asks to move alice to newEmail and returns the emailed token.
+func requestEmailChange(t *testing.T, svc *AuthService, repos *memory.Repos, newEmail string) (*domain.UserRow, string) {
+	t.Helper()
+
+	row := repos.Users.AddUser(t, "alice", "alice@example.com", changeEmailTestPassword, bcrypt.MinCost)
+	err := svc.RequestEmailChange(context.Background(), userFromRow(row), domain.ChangeEmailRequest{
+		NewEmail:        newEmail,
+		CurrentPassword: changeEmailTestPassword,
+	})
+	if err != nil {
+		t.Fatalf("request email change: %v", err)
+	}
+	return row, repos.Notifier.WaitFor(t, "email_change", newEmail).Token
+}
+
+func TestVerifyEmailChange(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	row, token := requestEmailChange(t, svc, repos, "alice@new.example.com")
+	ctx := context.Background()
+
+	// Nothing changes before the link is followed
+	if current, _ := repos.Users.GetByID(ctx, row.ID); current.Email != "alice@example.com" {
+		t.Fatalf("email before confirmation = %q, want the old address", current.Email)
+	}
+
+	if err := svc.VerifyEmailChange(ctx, token); err != nil {
+		t.Fatalf("verify email change: %v", err)
+	}
+	current, _ := repos.Users.GetByID(ctx, row.ID)
+	if current.Email != "alice@new.example.com" || !current.EmailVerified {
+		t.Errorf("after confirmation: email = %q, verified = %v", current.Email, current.EmailVerified)
+	}
+	if err := svc.VerifyEmailChange(ctx, token); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Errorf("second use of the token: error = %v, want %v", err, ErrInvalidVerificationToken)
+	}
+}
+
+func TestVerifyEmailChangeExpiredKeepsOldEmail(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	row, token := requestEmailChange(t, svc, repos, "alice@new.example.com")
+	repos.EmailChanges.ExpireAll()
+	ctx := context.Background()
+
+	if err := svc.VerifyEmailChange(ctx, token); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Fatalf("expired token: error = %v, want %v", err, ErrInvalidVerificationToken)
+	}
+	current, _ := repos.Users.GetByID(ctx, row.ID)
+	if current.Email != "alice@example.com" {
+		t.Errorf("email after an expired confirmation = %q, want the old address", current.Email)
+	}
+}
+
+func TestRequestEmailChangeWrongPassword(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	row := repos.Users.AddUser(t, "alice", "alice@example.com", changeEmailTestPassword, bcrypt.MinCost)
+
+	err := svc.RequestEmailChange(context.Background(), userFromRow(row), domain.ChangeEmailRequest{
+		NewEmail:        "mallory@example.com",
+		CurrentPassword: "wrong-password",
+	})
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if sent := repos.Notifier.Messages("email_change"); len(sent) != 0 {
+		t.Errorf("confirmation sent despite a wrong password: %v", sent)
+	}
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ChangePassword rotates the requester's password after verifying the current one.
+// token is the requester's access token: with RevokeOtherSessions, every session
+// except the one behind it is revoked.
+// Returns ErrInvalidCredentials for a wrong current password, ErrPasswordReused
+// when the new password equals the current one, and ErrWeakPassword on policy failure.
+func (s *AuthService) ChangePassword(
+	ctx context.Context, requester *domain.User, token string, req domain.ChangePasswordRequest,
+) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.change_password", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+		attribute.Bool("revoke_other_sessions", req.RevokeOtherSessions),
+	))
+	defer span.End()
+
+	row, err := s.users.GetByID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query user %s: %w", requester.ID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("lookup user %s: %w", requester.ID, ErrUserNotFound)
+	}
+
+	if err := s.verifyStepUp(ctx, span, row, req.CurrentPassword); err != nil {
+		return fmt.Errorf("change password for user %s: %w", requester.ID, err)
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return fmt.Errorf("change password for user %s: %w", requester.ID, ErrPasswordReused)
+	}
+	if err := s.opts.PasswordPolicy.ValidatePassword(req.NewPassword, row.Username, row.Email); err != nil {
+		return fmt.Errorf("change password for user %s: %w", requester.ID, err)
+	}
+
+	passwordHash, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("hash password: %w", err)
+	}
+	if err := s.users.UpdatePassword(ctx, row.ID, passwordHash); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("update password for user %s: %w", requester.ID, err)
+	}
+
+	if req.RevokeOtherSessions {
+		claims, err := s.tokens.ParseAndValidate(token)
+		if err != nil {
+			return fmt.Errorf("parse current token: %w", err)
+		}
+		revoked, err := s.sessions.DeleteOthersByUserID(ctx, row.ID, claims.ID)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("revoke other sessions for user %s: %w", requester.ID, err)
+		}
+		span.SetAttributes(attribute.Int64("sessions.revoked", revoked))
+	}
+
+	span.AddEvent("password.changed")
+	return nil
+}
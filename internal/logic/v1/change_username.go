@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// usernamePattern is the format accepted for new usernames: 3-32 letters,
+// digits, dots, dashes or underscores, starting with a letter or digit.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{2,31}$`)
+
+// reservedUsernames cannot be taken by users because they could pass for
+// staff or system accounts. Compared case-insensitively.
+var reservedUsernames = map[string]struct{}{
+	"admin":         {},
+	"administrator": {},
+	"root":          {},
+	"system":        {},
+	"support":       {},
+	"security":      {},
+	"staff":         {},
+	"moderator":     {},
+	"auth":          {},
+	"api":           {},
+	"me":            {},
+	"null":          {},
+	"undefined":     {},
+}
+
+// ChangeUsername renames the requester after re-checking their password.
+// The new username must match the format rules, must not be reserved, taken,
+// or released by another user within Options.UsernameChangeInterval, and the
+// requester may rename at most once per UsernameChangeInterval.
+// Returns ErrInvalidCredentials for a wrong password, ErrInvalidUsername,
+// ErrUsernameReserved, ErrUsernameExists, or a *RetryAfterError when renamed too recently.
+func (s *AuthService) ChangeUsername(
+	ctx context.Context, requester *domain.User, req domain.ChangeUsernameRequest,
+) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.change_username", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	req.Username = normalizeUsername(req.Username)
+	if err := validateUsername(req.Username); err != nil {
+		return fmt.Errorf("change username for user %s: %w", requester.ID, err)
+	}
+
+	row, err := s.users.GetByID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query user %s: %w", requester.ID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("lookup user %s: %w", requester.ID, ErrUserNotFound)
+	}
+
+	if err := s.verifyStepUp(ctx, span, row, req.CurrentPassword); err != nil {
+		return fmt.Errorf("change username for user %s: %w", requester.ID, err)
+	}
+	if err := s.renameUser(ctx, row, req.Username); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("change username for user %s: %w", requester.ID, err)
+	}
+	return nil
+}
+
+// renameUser applies a username change for a user whose password was already
+// re-checked and whose new username passed validateUsername. Renaming to the
+// current username is a no-op.
+func (s *AuthService) renameUser(ctx context.Context, row *domain.UserRow, username string) error {
+	if username == row.Username {
+		return nil
+	}
+
+	if interval := s.opts.UsernameChangeInterval; interval > 0 {
+		if err := s.checkUsernameChangeAllowed(ctx, row, username, interval); err != nil {
+			return err
+		}
+	}
+
+	changed, err := s.users.ChangeUsername(ctx, row.ID, username)
+	if err != nil {
+		return fmt.Errorf("update username: %w", err)
+	}
+	if !changed {
+		return ErrUsernameExists
+	}
+
+	middleware.RecordSecurityEvent(ctx, "username_changed", attribute.String("user.id", row.PublicID))
+	return nil
+}
+
+// checkUsernameChangeAllowed enforces the rename frequency limit and keeps a
+// username released by someone else unavailable for the same interval.
+func (s *AuthService) checkUsernameChangeAllowed(
+	ctx context.Context, row *domain.UserRow, username string, interval time.Duration,
+) error {
+	lastChange, ok, err := s.users.LastUsernameChange(ctx, row.ID)
+	if err != nil {
+		return fmt.Errorf("get last username change: %w", err)
+	}
+	if wait := time.Until(lastChange.Add(interval)); ok && wait > 0 {
+		return &RetryAfterError{RetryAfter: wait}
+	}
+
+	released, err := s.users.UsernameReleasedSince(ctx, username, row.ID, time.Now().Add(-interval))
+	if err != nil {
+		return fmt.Errorf("check released username: %w", err)
+	}
+	if released {
+		// Reported as taken: the name still belongs to its previous owner for now
+		return ErrUsernameExists
+	}
+	return nil
+}
+
+// validateUsername checks the format and reserved-name rules.
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return ErrInvalidUsername
+	}
+	if _, reserved := reservedUsernames[strings.ToLower(username)]; reserved {
+		return ErrUsernameReserved
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const usernameTestPassword = "correct-horse-battery"
+
+func TestUsernamesDifferingOnlyInCaseCollide(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	repos.Users.AddUser(t, "alice", "alice@example.com", usernameTestPassword, bcrypt.MinCost)
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, domain.RegisterRequest{
+		Username: "Alice",
+		Email:    "other@example.com",
+		Password: usernameTestPassword,
+	}, domain.ClientInfo{})
+	if !errors.Is(err, ErrUsernameExists) {
+		t.Fatalf("register Alice: error = %v, want %v", err, ErrUsernameExists)
+	}
+
+	bob := repos.Users.AddUser(t, "bob", "bob@example.com", usernameTestPassword, bcrypt.MinCost)
+	err = svc.ChangeUsername(ctx, userFromRow(bob), domain.ChangeUsernameRequest{
+		Username:        "ALICE",
+		CurrentPassword: usernameTestPassword,
+	})
+	if !errors.Is(err, ErrUsernameExists) {
+		t.Fatalf("rename bob to ALICE: error = %v, want %v", err, ErrUsernameExists)
+	}
+}
+
+func TestChangeUsernameCaseOnly(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	row := repos.Users.AddUser(t, "alice", "alice@example.com", usernameTestPassword, bcrypt.MinCost)
+	ctx := context.Background()
+
+	// Alice's own name does not block her from changing its case
+	err := svc.ChangeUsername(ctx, userFromRow(row), domain.ChangeUsernameRequest{
+		Username:        "Alice",
+		CurrentPassword: usernameTestPassword,
+	})
+	if err != nil {
+		t.Fatalf("rename alice to Alice: %v", err)
+	}
+	got, _ := repos.Users.GetByID(ctx, row.ID)
+	if got.Username != "Alice" {
+		t.Errorf("username = %q, want %q", got.Username, "Alice")
+	}
+}
+
+func TestChangeUsernameRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantErr  error
+	}{
+		{"available", "alice.smith", nil},
+		{"taken", "bob", ErrUsernameExists},
+		{"reserved", "Admin", ErrUsernameReserved},
+		{"too short", "al", ErrInvalidUsername},
+		{"bad characters", "alice smith", ErrInvalidUsername},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, Options{})
+			alice := repos.Users.AddUser(t, "alice", "alice@example.com", usernameTestPassword, bcrypt.MinCost)
+			repos.Users.AddUser(t, "bob", "bob@example.com", usernameTestPassword, bcrypt.MinCost)
+			ctx := context.Background()
+
+			err := svc.ChangeUsername(ctx, userFromRow(alice), domain.ChangeUsernameRequest{
+				Username:        tt.username,
+				CurrentPassword: usernameTestPassword,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			want := tt.username
+			if tt.wantErr != nil {
+				want = "alice"
+			}
+			if got, _ := repos.Users.GetByID(ctx, alice.ID); got.Username != want {
+				t.Errorf("username = %q, want %q", got.Username, want)
+			}
+		})
+	}
+}
+
+func TestChangeUsernameFrequencyLimit(t *testing.T) {
+	const interval = 30 * 24 * time.Hour
+
+	svc, repos := newTestService(t, Options{UsernameChangeInterval: interval})
+	alice := repos.Users.AddUser(t, "alice", "alice@example.com", usernameTestPassword, bcrypt.MinCost)
+	bob := repos.Users.AddUser(t, "bob", "bob@example.com", usernameTestPassword, bcrypt.MinCost)
+	ctx := context.Background()
+	rename := func(row *domain.UserRow, username string) error {
+		return svc.ChangeUsername(ctx, userFromRow(row), domain.ChangeUsernameRequest{
+			Username:        username,
+			CurrentPassword: usernameTestPassword,
+		})
+	}
+
+	if err := rename(alice, "alice2"); err != nil {
+		t.Fatalf("first rename: %v", err)
+	}
+
+	var retryErr *RetryAfterError
+	if err := rename(alice, "alice3"); !errors.As(err, &retryErr) {
+		t.Fatalf("second rename: error = %v, want a *RetryAfterError", err)
+	}
+	if retryErr.RetryAfter <= interval-time.Minute || retryErr.RetryAfter > interval {
+		t.Errorf("RetryAfter = %s, want about %s", retryErr.RetryAfter, interval)
+	}
+
+	// The name alice gave up stays hers for the interval
+	if err := rename(bob, "alice"); !errors.Is(err, ErrUsernameExists) {
+		t.Errorf("bob taking alice's old name: error = %v, want %v", err, ErrUsernameExists)
+	}
+	if err := rename(bob, "robert"); err != nil {
+		t.Errorf("bob's own first rename: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeleteAccount soft-deletes the requester's account after re-checking their
+// password, then revokes all of their sessions. The user row is kept, so its
+// username and email stay taken; later logins fail as ErrUserNotFound.
+// Returns ErrInvalidCredentials for a wrong password.
+func (s *AuthService) DeleteAccount(ctx context.Context, requester *domain.User, req domain.DeleteAccountRequest) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.delete_account", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	row, err := s.users.GetByID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query user %s: %w", requester.ID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("lookup user %s: %w", requester.ID, ErrUserNotFound)
+	}
+
+	if err := s.verifyStepUp(ctx, span, row, req.CurrentPassword); err != nil {
+		return fmt.Errorf("delete account of user %s: %w", requester.ID, err)
+	}
+
+	if err := s.users.SoftDelete(ctx, row.ID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("delete user %s: %w", requester.ID, err)
+	}
+
+	revoked, err := s.sessions.DeleteByUserID(ctx, row.ID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("revoke sessions of user %s: %w", requester.ID, err)
+	}
+
+	span.SetAttributes(attribute.Int64("sessions.revoked", revoked))
+	middleware.RecordSecurityEvent(ctx, "account_deleted", attribute.String("user.id", requester.ID))
+	return nil
+}
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultEmailVerificationTTL is used when Options.EmailVerificationTTL is not set.
+const defaultEmailVerificationTTL = 24 * time.Hour
+
+// deliverEmailVerification stores a new verification token for the user and
+// hands it to the notifier. It runs after the request has been answered, so
+// failures can only be logged; the user can ask for a new email.
+func (s *AuthService) deliverEmailVerification(ctx context.Context, row *domain.UserRow) {
+	ctx, cancel := context.WithTimeout(ctx, tokenDeliveryTimeout)
+	defer cancel()
+
+	ctx, span := middleware.StartSpan(ctx, "auth.deliver_email_verification", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", row.PublicID),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Verification token generation failed")
+		return
+	}
+
+	ttl := s.opts.EmailVerificationTTL
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	if err := s.userTokens.ReplaceToken(ctx, row.ID, domain.TokenEmailVerification, tokenHash, expiresAt); err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Verification token storage failed")
+		return
+	}
+
+	if err := s.notifier.SendEmailVerification(ctx, row.Email, token, expiresAt); err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Verification email delivery failed")
+		return
+	}
+
+	span.AddEvent("email_verification.sent")
+}
+
+// ResendEmailVerification issues a fresh verification token for the requester and
+// sends it again. It is a silent no-op when the email is already verified, and
+// returns a *RetryAfterError when the previous token was issued less than
+// Options.EmailVerificationResendInterval ago.
+func (s *AuthService) ResendEmailVerification(ctx context.Context, requester *domain.User) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.resend_email_verification", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	row, err := s.users.GetByID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("get user %q: %w", requester.ID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("get user %q: %w", requester.ID, ErrUserNotFound)
+	}
+	if row.EmailVerified {
+		span.SetAttributes(attribute.Bool("email.verified", true))
+		return nil
+	}
+
+	if interval := s.opts.EmailVerificationResendInterval; interval > 0 {
+		lastSent, ok, err := s.verifications.LastCreatedAt(ctx, row.ID)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("get last verification for user %q: %w", requester.ID, err)
+		}
+		if wait := time.Until(lastSent.Add(interval)); ok && wait > 0 {
+			span.SetAttributes(attribute.Bool("verification.throttled", true))
+			return fmt.Errorf("resend verification for user %q: %w", requester.ID, &RetryAfterError{RetryAfter: wait})
+		}
+	}
+
+	// Same background delivery as registration so mail latency never blocks the request
+	go s.deliverEmailVerification(context.WithoutCancel(ctx), row)
+
+	span.AddEvent("email_verification.resent")
+	return nil
+}
+
+// VerifyEmail redeems an email verification token and marks the owner's email as verified.
+// Returns ErrInvalidVerificationToken for unknown, expired or used tokens.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.verify_email", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	userID, ok, err := s.verifications.Consume(ctx, hashOpaqueToken(token))
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("consume verification token: %w", err)
+	}
+	if !ok {
+		span.SetAttributes(attribute.Bool("verification.valid", false))
+		return fmt.Errorf("verify email: %w", ErrInvalidVerificationToken)
+	}
+	span.SetAttributes(attribute.Int("user.id", userID))
+
+	if err := s.users.MarkEmailVerified(ctx, userID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("mark email verified for user %d: %w", userID, err)
+	}
+
+	span.AddEvent("email.verified")
+	return nil
+}
@@ -17,17 +17,22 @@
 //
 // Error Checking (in handlers):
 //
-//	switch {
-//	case errors.Is(err, logicv1.ErrInvalidCredentials):
-//	    c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
-//	case errors.Is(err, logicv1.ErrUserNotFound):
-//	    c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
-//	default:
-//	    c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-//	}
+// Handlers do not switch on these errors themselves. The Web layer maps every
+// sentinel to an HTTP status, code and message in one table (errorMappings in
+// internal/web/v1/errors.go); handlers just call:
+//
+//	h.respondError(c, err)
+//
+// When adding a sentinel here, add its mapping there as well.
 package v1
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
 
 // Sentinel errors for authentication operations.
 // These errors should be wrapped with context using fmt.Errorf("%w") when returned.
@@ -48,13 +53,25 @@ var (
 	// HTTP Status: 403 Forbidden
 	ErrAccountLocked = errors.New("account locked")
 
-	// ErrUnauthorized indicates the user is not authorized to perform the operation.
+	// ErrForbidden indicates the authenticated user may not perform the operation.
 	// HTTP Status: 403 Forbidden
-	ErrUnauthorized = errors.New("unauthorized access")
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrUsernameExists indicates the username is already taken by another user.
+	// HTTP Status: 409 Conflict
+	ErrUsernameExists = errors.New("username already exists")
+
+	// ErrInvalidUsername indicates the username does not match the allowed format.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidUsername = errors.New("invalid username")
+
+	// ErrUsernameReserved indicates the username is reserved for system or staff use.
+	// HTTP Status: 400 Bad Request
+	ErrUsernameReserved = errors.New("username reserved")
 
-	// ErrUserExists indicates the username or email already exists in the system.
+	// ErrEmailExists indicates the email is already registered to another user.
 	// HTTP Status: 409 Conflict
-	ErrUserExists = errors.New("user already exists")
+	ErrEmailExists = errors.New("email already exists")
 
 	// ErrSessionNotFound indicates the session token does not exist.
 	// HTTP Status: 401 Unauthorized
@@ -63,4 +80,83 @@ var (
 	// ErrSessionExpired indicates the session token has expired.
 	// HTTP Status: 401 Unauthorized
 	ErrSessionExpired = errors.New("session expired")
+
+	// ErrInvalidToken indicates the access token is malformed or its signature does not verify.
+	// HTTP Status: 401 Unauthorized
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrSessionBindingMismatch indicates a bound session was presented by a different client.
+	// HTTP Status: 401 Unauthorized
+	ErrSessionBindingMismatch = errors.New("session bound to a different client")
+
+	// ErrInvalidRefreshToken indicates the refresh token is unknown, expired, or already used.
+	// HTTP Status: 401 Unauthorized
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRegistrationClosed indicates self-service registration is disabled (REGISTRATION_MODE=closed).
+	// HTTP Status: 403 Forbidden
+	ErrRegistrationClosed = errors.New("registration closed")
+
+	// ErrInvalidInvite indicates the invite token is missing, unknown, expired, or already used.
+	// HTTP Status: 403 Forbidden
+	ErrInvalidInvite = errors.New("invalid invite")
+
+	// ErrInvalidResetToken indicates the password reset token is unknown, expired, or already used.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidResetToken = errors.New("invalid reset token")
+
+	// ErrInvalidVerificationToken indicates the email verification token is unknown, expired, or already used.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+
+	// ErrTwoFactorUnavailable indicates two-factor authentication is not configured on this server.
+	// HTTP Status: 501 Not Implemented
+	ErrTwoFactorUnavailable = errors.New("two-factor authentication unavailable")
+
+	// ErrTwoFactorAlreadyEnabled indicates the user already has confirmed two-factor authentication.
+	// HTTP Status: 409 Conflict
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+
+	// ErrTwoFactorNotEnrolled indicates there is no pending two-factor enrollment to confirm.
+	// HTTP Status: 400 Bad Request
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment not started")
+
+	// ErrInvalidTOTPCode indicates the TOTP code does not match the user's secret.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidTOTPCode = errors.New("invalid totp code")
+
+	// ErrServiceUnavailable indicates a dependency is saturated or down (e.g., database pool exhausted).
+	// Core implementations return it wrapped; it is re-exported here so handlers match it like other sentinels.
+	// HTTP Status: 503 Service Unavailable (with Retry-After)
+	ErrServiceUnavailable = domain.ErrServiceUnavailable
+
+	// ErrTooManyRequests indicates the caller must wait before retrying the operation.
+	// Returned wrapped in *RetryAfterError, which carries the wait time.
+	// HTTP Status: 429 Too Many Requests
+	ErrTooManyRequests = errors.New("too many requests")
+
+	// ErrPasswordReused indicates the new password is the same as the current one.
+	// HTTP Status: 400 Bad Request
+	ErrPasswordReused = errors.New("new password must differ from current password")
+
+	// ErrWeakPassword indicates the password does not satisfy the configured policy.
+	// Returned wrapped in *PasswordPolicyError, which carries per-rule violations.
+	// HTTP Status: 400 Bad Request
+	ErrWeakPassword = errors.New("password does not meet policy")
 )
+
+// RetryAfterError reports that an operation is throttled and when it may be retried.
+// It unwraps to ErrTooManyRequests so handlers can match it with errors.Is.
+type RetryAfterError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrTooManyRequests, e.RetryAfter)
+}
+
+// Unwrap returns ErrTooManyRequests.
+func (e *RetryAfterError) Unwrap() error {
+	return ErrTooManyRequests
+}
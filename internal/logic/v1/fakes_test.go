@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testTokenSecret signs access tokens in tests.
+const testTokenSecret = "test-secret-test-secret-test-secret-00"
+
+// repositories wires the in-memory repositories into AuthService.
+func repositories(r *memory.Repos) Repositories {
+	return Repositories{
+		Users:         r.Users,
+		Sessions:      r.Sessions,
+		Invites:       r.Invites,
+		Resets:        r.Resets,
+		Verifications: r.Verifications,
+		Tokens:        r.Tokens,
+		EmailChanges:  r.EmailChanges,
+		TOTP:          r.TOTP,
+		BackupCodes:   r.BackupCodes,
+		RefreshTokens: r.RefreshTokens,
+	}
+}
+
+// newTestService returns an AuthService backed by fresh in-memory repositories.
+// Passwords are hashed at bcrypt.MinCost unless opts sets a cost, to keep tests fast.
+func newTestService(tb testing.TB, opts Options) (*AuthService, *memory.Repos) {
+	tb.Helper()
+
+	if opts.BcryptCost == 0 {
+		opts.BcryptCost = bcrypt.MinCost
+	}
+	repos := memory.New()
+	tokens := NewTokenIssuer(testTokenSecret, time.Hour)
+	return NewAuthService(repositories(repos), tokens, repos.Notifier, opts), repos
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// External ID representation: users are identified outside the service only by
+// their public_id, a random UUID serialized as a JSON string (domain.User.ID,
+// the JWT sub claim, path params). The serial users.id is internal: it never
+// appears in responses or tokens, so user counts and neighbouring IDs cannot be
+// enumerated. Build domain.User only through these helpers to keep it that way.
+
+// userFromRow converts a user record to its API representation.
+func userFromRow(row *domain.UserRow) *domain.User {
+	return &domain.User{
+		ID:            row.PublicID,
+		InternalID:    row.ID,
+		Username:      row.Username,
+		Email:         row.Email,
+		EmailVerified: row.EmailVerified,
+		Role:          row.Role,
+	}
+}
+
+// userFromSession converts the owner of a session to its API representation.
+func userFromSession(row *domain.SessionRow) *domain.User {
+	return &domain.User{
+		ID:            row.UserPublicID,
+		InternalID:    row.UserID,
+		Username:      row.Username,
+		Email:         row.Email,
+		EmailVerified: row.EmailVerified,
+		Role:          row.Role,
+	}
+}
+
+// isPublicID reports whether id is a well-formed public ID (users and sessions).
+// Checked before querying so malformed input never reaches the UUID column.
+func isPublicID(id string) bool {
+	return uuid.Validate(id) == nil
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// inactiveToken returns the whole introspection response for a token that is not active.
+func inactiveToken() *domain.IntrospectionResponse {
+	return &domain.IntrospectionResponse{Active: false}
+}
+
+// Introspect reports whether an access token is active, for gateways validating
+// tokens on behalf of their upstreams (RFC 7662). A token is active when its
+// signature and exp verify and its session exists, has not expired and is not idle.
+//
+// The caller is a gateway, not the token's client, so device and subnet bindings
+// are not checked here. Unknown, forged, expired and revoked tokens all return an
+// inactive response rather than an error; errors are reserved for failures such
+// as an unavailable database.
+func (s *AuthService) Introspect(ctx context.Context, token string) (*domain.IntrospectionResponse, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.introspect", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	claims, err := s.tokens.ParseAndValidate(token)
+	if err != nil {
+		span.SetAttributes(attribute.Bool("token.active", false))
+		return inactiveToken(), nil
+	}
+
+	row, err := s.sessions.GetUserByToken(ctx, claims.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("query session: %w", err)
+	}
+	if row == nil || row.UserPublicID != claims.Subject {
+		span.SetAttributes(attribute.Bool("token.active", false))
+		return inactiveToken(), nil
+	}
+
+	if err := s.checkSessionLifetime(ctx, span, row); err != nil {
+		span.SetAttributes(attribute.Bool("token.active", false))
+		return inactiveToken(), nil
+	}
+
+	// Gateway validation is use of the session, as with /auth/v1/private/me
+	if _, touchErr := s.sessions.TouchLastUsed(ctx, row.ID, s.opts.SessionTouchInterval); touchErr != nil {
+		span.RecordError(fmt.Errorf("touch session: %w", touchErr))
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", row.UserPublicID),
+		attribute.Bool("token.active", true),
+	)
+
+	return &domain.IntrospectionResponse{
+		Active:    true,
+		Subject:   row.UserPublicID,
+		Username:  row.Username,
+		ExpiresAt: claims.ExpiresAt,
+	}, nil
+}
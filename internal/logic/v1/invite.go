@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RegistrationMode controls who may create an account.
+type RegistrationMode string
+
+const (
+	// RegistrationOpen allows anyone to register (default).
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationInvite requires a valid single-use invite token.
+	RegistrationInvite RegistrationMode = "invite"
+	// RegistrationClosed rejects all self-service registrations.
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+// defaultInviteTTL is used when Options.InviteTTL is not set.
+const defaultInviteTTL = 7 * 24 * time.Hour
+
+// IssueInvite creates a single-use registration invite on behalf of inviter, an admin.
+// When email is set, only that address can redeem the invite.
+func (s *AuthService) IssueInvite(ctx context.Context, inviter *domain.User, email string) (*domain.InviteResponse, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.issue_invite", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", inviter.ID),
+		attribute.Bool("invite.restricted", email != ""),
+	))
+	defer span.End()
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	email = normalizeEmail(email)
+	ttl := s.opts.InviteTTL
+	if ttl <= 0 {
+		ttl = defaultInviteTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	if err := s.invites.Create(ctx, tokenHash, email, inviter.InternalID, expiresAt); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("create invite: %w", err)
+	}
+
+	span.AddEvent("invite.issued")
+	return &domain.InviteResponse{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// checkRegistrationMode rejects registrations the configured mode does not allow.
+// It does no I/O so it can run before any expensive work.
+func (s *AuthService) checkRegistrationMode(req domain.RegisterRequest) error {
+	switch s.opts.RegistrationMode {
+	case RegistrationOpen, "":
+		return nil
+	case RegistrationInvite:
+		if req.InviteToken == "" {
+			return ErrInvalidInvite
+		}
+		return nil
+	case RegistrationClosed:
+		return ErrRegistrationClosed
+	default:
+		return fmt.Errorf("unknown registration mode %q: %w", s.opts.RegistrationMode, ErrRegistrationClosed)
+	}
+}
+
+// redeemInvite consumes the invite in invite mode. It runs after the uniqueness
+// checks so an invite is not burned by a registration that would be rejected anyway.
+func (s *AuthService) redeemInvite(ctx context.Context, req domain.RegisterRequest) error {
+	if s.opts.RegistrationMode != RegistrationInvite {
+		return nil
+	}
+	ok, err := s.invites.Consume(ctx, hashOpaqueToken(req.InviteToken), normalizeEmail(req.Email))
+	if err != nil {
+		return fmt.Errorf("consume invite: %w", err)
+	}
+	if !ok {
+		return ErrInvalidInvite
+	}
+	return nil
+}
+
+// discardRegistration deletes a user created by register whose session could
+// not be issued, and hands back its invite, so retrying the registration is not
+// blocked by a half-finished account. Failures are only logged.
+func (s *AuthService) discardRegistration(ctx context.Context, row *domain.UserRow, req domain.RegisterRequest) {
+	if err := s.users.Delete(context.WithoutCancel(ctx), row.ID); err != nil {
+		trace.SpanFromContext(ctx).RecordError(fmt.Errorf("delete user %q: %w", row.PublicID, err))
+		pkgzerolog.FromContext(ctx).Error().Err(err).Str("user_id", row.PublicID).
+			Msg("Deleting user of a failed registration failed")
+	}
+	s.releaseInvite(ctx, req)
+}
+
+// releaseInvite hands back the invite redeemInvite consumed when the user could
+// not be created, so a transient failure does not burn it. Failures are only
+// logged: the registration error is what the caller reports.
+func (s *AuthService) releaseInvite(ctx context.Context, req domain.RegisterRequest) {
+	if s.opts.RegistrationMode != RegistrationInvite {
+		return
+	}
+	if err := s.invites.Release(context.WithoutCancel(ctx), hashOpaqueToken(req.InviteToken)); err != nil {
+		trace.SpanFromContext(ctx).RecordError(fmt.Errorf("release invite: %w", err))
+		pkgzerolog.FromContext(ctx).Error().Err(err).Msg("Invite release failed")
+	}
+}
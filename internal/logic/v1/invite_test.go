@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// issueTestInvite issues an invite as an admin and returns its token.
+func issueTestInvite(t *testing.T, svc *AuthService, email string) string {
+	t.Helper()
+
+	admin := &domain.User{ID: "admin", InternalID: 1, Role: domain.RoleAdmin}
+	invite, err := svc.IssueInvite(context.Background(), admin, email)
+	if err != nil {
+		t.Fatalf("issue invite: %v", err)
+	}
+	return invite.Token
+}
+
+func registerRequest(username, inviteToken string) domain.RegisterRequest {
+	return domain.RegisterRequest{
+		Username:    username,
+		Email:       username + "@example.com",
+		Password:    "correct-horse-battery-staple",
+		InviteToken: inviteToken,
+	}
+}
+
+func TestRegisterRegistrationModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       RegistrationMode
+		withInvite bool
+		wantErr    error
+	}{
+		{"open", RegistrationOpen, false, nil},
+		{"open by default", "", false, nil},
+		{"closed", RegistrationClosed, false, ErrRegistrationClosed},
+		{"closed ignores invites", RegistrationClosed, true, ErrRegistrationClosed},
+		{"invite without token", RegistrationInvite, false, ErrInvalidInvite},
+		{"invite with token", RegistrationInvite, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, Options{RegistrationMode: tt.mode})
+			token := ""
+			if tt.withInvite {
+				token = issueTestInvite(t, svc, "")
+			}
+
+			resp, err := svc.Register(context.Background(), registerRequest("alice", token), domain.ClientInfo{})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Register error = %v, want %v", err, tt.wantErr)
+				}
+				if n, _ := repos.Users.Count(context.Background()); n != 0 {
+					t.Errorf("rejected registration created %d users", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+			if resp.Token == "" || resp.User.Username != "alice" {
+				t.Errorf("unexpected response: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestRegisterInviteIsSingleUse(t *testing.T) {
+	svc, _ := newTestService(t, Options{RegistrationMode: RegistrationInvite})
+	token := issueTestInvite(t, svc, "")
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, registerRequest("alice", token), domain.ClientInfo{}); err != nil {
+		t.Fatalf("first registration: %v", err)
+	}
+	_, err := svc.Register(ctx, registerRequest("bob", token), domain.ClientInfo{})
+	if !errors.Is(err, ErrInvalidInvite) {
+		t.Fatalf("second registration with the same invite: error = %v, want %v", err, ErrInvalidInvite)
+	}
+}
+
+func TestRegisterCreateFailureKeepsInvite(t *testing.T) {
+	svc, repos := newTestService(t, Options{RegistrationMode: RegistrationInvite})
+	token := issueTestInvite(t, svc, "")
+	ctx := context.Background()
+
+	repos.Users.CreateErr = errors.New("connection reset")
+	if _, err := svc.Register(ctx, registerRequest("alice", token), domain.ClientInfo{}); err == nil {
+		t.Fatal("registration succeeded despite a failing insert")
+	}
+
+	repos.Users.CreateErr = nil
+	if _, err := svc.Register(ctx, registerRequest("alice", token), domain.ClientInfo{}); err != nil {
+		t.Fatalf("retry with the same invite: %v", err)
+	}
+}
+
+func TestRegisterSessionFailureUndoesRegistration(t *testing.T) {
+	svc, repos := newTestService(t, Options{RegistrationMode: RegistrationInvite})
+	token := issueTestInvite(t, svc, "")
+	ctx := context.Background()
+
+	repos.Sessions.CreateErr = errors.New("connection reset")
+	if _, err := svc.Register(ctx, registerRequest("alice", token), domain.ClientInfo{}); err == nil {
+		t.Fatal("registration succeeded despite a failing session insert")
+	}
+	if row, _ := repos.Users.GetByUsername(ctx, "alice"); row != nil {
+		t.Errorf("failed registration left user %s behind", row.PublicID)
+	}
+
+	// Neither the username nor the invite is burned by the failed attempt
+	repos.Sessions.CreateErr = nil
+	resp, err := svc.Register(ctx, registerRequest("alice", token), domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("retry with the same username and invite: %v", err)
+	}
+	if n := repos.Sessions.Count(); n != 1 {
+		t.Errorf("sessions = %d, want 1", n)
+	}
+	if _, err := svc.GetUserByToken(ctx, resp.Token, domain.ClientInfo{}); err != nil {
+		t.Errorf("token of the retried registration: %v", err)
+	}
+}
+
+func TestRegisterInviteRestrictedToEmail(t *testing.T) {
+	svc, _ := newTestService(t, Options{RegistrationMode: RegistrationInvite})
+	token := issueTestInvite(t, svc, "Carol@Example.com")
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, registerRequest("mallory", token), domain.ClientInfo{})
+	if !errors.Is(err, ErrInvalidInvite) {
+		t.Fatalf("registration with another email: error = %v, want %v", err, ErrInvalidInvite)
+	}
+	// The rejected attempt must not have used up the invite
+	if _, err := svc.Register(ctx, registerRequest("carol", token), domain.ClientInfo{}); err != nil {
+		t.Fatalf("registration with the invited email: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package v1
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Access tokens are JWTs signed with HS256 (HMAC-SHA256, RFC 7518 §3.2).
+// Only this service verifies them, so a shared secret is sufficient; other
+// services validate tokens through /auth/v1/private/me rather than locally.
+//
+// Secret rotation: deploy the new secret as JWT_SECRET and move the old one to
+// JWT_PREVIOUS_SECRET. New tokens are signed with the new secret while tokens
+// signed with the old one keep verifying. Remove JWT_PREVIOUS_SECRET once the
+// longest token TTL has passed since the rollout.
+//
+// Each token carries a unique jti that is stored as the session key, so a
+// token can still be revoked server-side before it expires.
+//
+// The role claim is the user's role when the token was issued. It lets clients
+// adapt their UI; authorization decisions re-read the role from the database,
+// so a role change takes effect before old tokens expire.
+
+// jwtHeader is the fixed, pre-encoded JOSE header for every issued token.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims are the registered JWT claims carried by an access token.
+type Claims struct {
+	Subject   string `json:"sub"` // user public ID
+	IssuedAt  int64  `json:"iat"` // Unix seconds
+	ExpiresAt int64  `json:"exp"` // Unix seconds
+	ID        string `json:"jti"` // session key, stored in sessions.token
+	Role      string `json:"role,omitempty"`
+}
+
+// Expiry returns the exp claim as a time.
+func (c *Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// TokenIssuer signs and verifies HS256 access tokens.
+type TokenIssuer struct {
+	secret   []byte
+	previous [][]byte
+	ttl      time.Duration
+}
+
+// NewTokenIssuer creates a TokenIssuer that signs with secret and issues tokens
+// valid for ttl. previousSecrets are accepted for verification only (rotation).
+func NewTokenIssuer(secret string, ttl time.Duration, previousSecrets ...string) *TokenIssuer {
+	t := &TokenIssuer{secret: []byte(secret), ttl: ttl}
+	for _, s := range previousSecrets {
+		if s != "" {
+			t.previous = append(t.previous, []byte(s))
+		}
+	}
+	return t
+}
+
+// Issue creates a signed token for the user (by public ID and role) with the
+// default TTL, returning it with its claims.
+func (t *TokenIssuer) Issue(subject, role string) (string, *Claims, error) {
+	return t.IssueWithTTL(subject, role, t.ttl)
+}
+
+// IssueWithTTL is Issue with an explicit lifetime. Callers are responsible for
+// bounding ttl (see AuthService.sessionTTL).
+func (t *TokenIssuer) IssueWithTTL(subject, role string, ttl time.Duration) (string, *Claims, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", nil, fmt.Errorf("generate jti: %w", err)
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Subject:   subject,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+		ID:        hex.EncodeToString(jti),
+		Role:      role,
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode claims: %w", err)
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + sign(t.secret, signingInput), claims, nil
+}
+
+// ParseAndValidate verifies the token signature and expiry and returns its claims.
+// Returns ErrInvalidToken for malformed or forged tokens and ErrSessionExpired
+// for tokens past their exp claim.
+func (t *TokenIssuer) ParseAndValidate(token string) (*Claims, error) {
+	claims, err := t.parseVerified(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !time.Now().Before(claims.Expiry()) {
+		return nil, fmt.Errorf("token expired at %v: %w", claims.Expiry(), ErrSessionExpired)
+	}
+
+	return claims, nil
+}
+
+// parseVerified verifies the token signature and returns its claims without
+// checking expiry. Only for acting on tokens known to be expired (e.g. to clean
+// up their session); use ParseAndValidate to authenticate.
+func (t *TokenIssuer) parseVerified(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("parse token: %w", ErrInvalidToken)
+	}
+	// Only our own fixed header is accepted, which rules out alg=none and
+	// algorithm-confusion attacks without parsing the header.
+	if parts[0] != jwtHeader {
+		return nil, fmt.Errorf("unexpected token header: %w", ErrInvalidToken)
+	}
+	if !t.verify(parts[0]+"."+parts[1], parts[2]) {
+		return nil, fmt.Errorf("verify token signature: %w", ErrInvalidToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode token payload: %w", ErrInvalidToken)
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("decode token claims: %w", ErrInvalidToken)
+	}
+	if claims.ID == "" || claims.Subject == "" {
+		return nil, fmt.Errorf("token missing sub or jti: %w", ErrInvalidToken)
+	}
+
+	return &claims, nil
+}
+
+// verify checks the signature against the current and previous secrets.
+func (t *TokenIssuer) verify(signingInput, signature string) bool {
+	if hmac.Equal([]byte(signature), []byte(sign(t.secret, signingInput))) {
+		return true
+	}
+	for _, secret := range t.previous {
+		if hmac.Equal([]byte(signature), []byte(sign(secret, signingInput))) {
+			return true
+		}
+	}
+	return false
+}
+
+func sign(secret []byte, signingInput string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
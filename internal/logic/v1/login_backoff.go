@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// loginBackoffFreeAttempts is how many consecutive failures a username gets
+// before backoff starts, so ordinary typos are never delayed.
+const loginBackoffFreeAttempts = 3
+
+// loginBackoff slows down repeated failed logins against one username,
+// complementing per-IP rate limiting against attacks spread over many IPs.
+// After loginBackoffFreeAttempts failures, each further attempt must wait
+// base * 2^(n-1), capped at max, counted from the latest failure.
+//
+// Usernames are tracked whether or not they exist, and the check runs before
+// the user lookup, so the delay reveals nothing about which accounts exist.
+// State is per process; the database lockout (LOGIN_LOCKOUT_*) is the hard limit.
+type loginBackoff struct {
+	base time.Duration
+	max  time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*loginBackoffEntry
+	lastSweep time.Time
+}
+
+// loginBackoffEntry tracks the consecutive failures of one username.
+type loginBackoffEntry struct {
+	failures    int
+	nextAllowed time.Time
+}
+
+func newLoginBackoff(base, max time.Duration) *loginBackoff {
+	return &loginBackoff{base: base, max: max, entries: make(map[string]*loginBackoffEntry)}
+}
+
+// wait returns how long username must wait before the next attempt (0 if none).
+func (b *loginBackoff) wait(username string, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if e, ok := b.entries[loginBackoffKey(username)]; ok && now.Before(e.nextAllowed) {
+		return e.nextAllowed.Sub(now)
+	}
+	return 0
+}
+
+// fail records a failed attempt for username.
+func (b *loginBackoff) fail(username string, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweepLocked(now)
+
+	key := loginBackoffKey(username)
+	e, ok := b.entries[key]
+	if !ok {
+		e = &loginBackoffEntry{}
+		b.entries[key] = e
+	}
+	e.failures++
+	e.nextAllowed = now.Add(b.delay(e.failures))
+}
+
+// succeed forgets the failures of username.
+func (b *loginBackoff) succeed(username string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.entries, loginBackoffKey(username))
+}
+
+// delay returns the wait imposed after the given number of consecutive failures.
+func (b *loginBackoff) delay(failures int) time.Duration {
+	n := failures - loginBackoffFreeAttempts
+	if n <= 0 {
+		return 0
+	}
+	d := b.base
+	for i := 1; i < n && d < b.max; i++ {
+		d *= 2
+	}
+	return min(d, b.max)
+}
+
+// sweepLocked drops entries whose last failure is more than max old: their
+// next attempt is already allowed, and an attacker spraying usernames must not
+// grow the map without bound. Runs at most once per max. b.mu must be held.
+func (b *loginBackoff) sweepLocked(now time.Time) {
+	if now.Sub(b.lastSweep) < b.max {
+		return
+	}
+	b.lastSweep = now
+
+	for key, e := range b.entries {
+		if now.Sub(e.nextAllowed) > b.max {
+			delete(b.entries, key)
+		}
+	}
+}
+
+func loginBackoffKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
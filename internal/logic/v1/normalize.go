@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Identifier normalization: usernames and emails are normalized once, where a
+// request enters the Logic layer, so every lookup and every stored value uses
+// the same form. Both are trimmed and converted to Unicode NFC, so visually
+// identical input composed differently matches. Emails are also lowercased;
+// the local part is case-sensitive in theory but not at any real mail provider.
+// Usernames keep their case, and they are unique regardless of case. Whether a
+// lookup for "Alice" finds "alice" is decided by the user repository
+// (USERNAME_CASE_SENSITIVE), so the display case is preserved either way.
+
+// normalizeEmail returns the canonical form of an email address.
+func normalizeEmail(email string) string {
+	return strings.ToLower(norm.NFC.String(strings.TrimSpace(email)))
+}
+
+// normalizeUsername returns the canonical form of a username.
+func normalizeUsername(username string) string {
+	return norm.NFC.String(strings.TrimSpace(username))
+}
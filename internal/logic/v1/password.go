@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// personalInfoMinLength is the shortest username or email local part that the
+// personal-info rule looks for; shorter ones would match too many passwords.
+const personalInfoMinLength = 3
+
+// maxPasswordBytes is the longest password accepted, in bytes. bcrypt refuses
+// longer input, so the limit applies whatever the policy or the hasher.
+const maxPasswordBytes = 72
+
+// PasswordPolicy holds the configurable password rules enforced on
+// register, change-password and reset-password.
+// A zero value for any minimum disables that rule. The maximum length
+// (maxPasswordBytes) is always enforced.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters (runes, not bytes).
+	MinLength    int
+	MinUppercase int
+	MinLowercase int
+	MinDigits    int
+	MinSymbols   int
+	// RejectPersonalInfo rejects passwords containing the username or the local
+	// part of the email address (case-insensitive).
+	RejectPersonalInfo bool
+}
+
+// PasswordViolation describes a single password rule the input failed,
+// so the frontend can show field-level guidance.
+type PasswordViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a password failed.
+// It unwraps to ErrWeakPassword so handlers can match it with errors.Is.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+// Error implements the error interface.
+func (e *PasswordPolicyError) Error() string {
+	rules := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		rules = append(rules, v.Rule)
+	}
+	return fmt.Sprintf("%s: %s", ErrWeakPassword, strings.Join(rules, ", "))
+}
+
+// Unwrap returns ErrWeakPassword.
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// ValidatePassword checks the password against every rule of the policy; username
+// and email belong to the account it is set for (empty skips the personal-info rule).
+// Returns a *PasswordPolicyError listing all failed rules, or nil.
+func (p PasswordPolicy) ValidatePassword(password, username, email string) error {
+	var upper, lower, digits, symbols int
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbols++
+		}
+	}
+
+	var violations []PasswordViolation
+	if p.MinLength > 0 && utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Field:   "password",
+			Rule:    "min_length",
+			Message: fmt.Sprintf("must be at least %d characters long", p.MinLength),
+		})
+	}
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, PasswordViolation{
+			Field:   "password",
+			Rule:    "max_length",
+			Message: fmt.Sprintf("must be at most %d bytes long (fewer characters outside ASCII)", maxPasswordBytes),
+		})
+	}
+
+	check := func(rule, class string, got, want int) {
+		if want > 0 && got < want {
+			violations = append(violations, PasswordViolation{
+				Field:   "password",
+				Rule:    rule,
+				Message: fmt.Sprintf("must contain at least %d %s", want, class),
+			})
+		}
+	}
+	check("min_uppercase", "uppercase letter(s)", upper, p.MinUppercase)
+	check("min_lowercase", "lowercase letter(s)", lower, p.MinLowercase)
+	check("min_digits", "digit(s)", digits, p.MinDigits)
+	check("min_symbols", "symbol(s)", symbols, p.MinSymbols)
+
+	if p.RejectPersonalInfo {
+		localPart, _, _ := strings.Cut(email, "@")
+		contains := func(rule, what, value string) {
+			value = strings.ToLower(value)
+			if utf8.RuneCountInString(value) >= personalInfoMinLength &&
+				strings.Contains(strings.ToLower(password), value) {
+				violations = append(violations, PasswordViolation{
+					Field:   "password",
+					Rule:    rule,
+					Message: "must not contain your " + what,
+				})
+			}
+		}
+		contains("contains_username", "username", username)
+		contains("contains_email", "email address", localPart)
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Stored password hashes identify their own algorithm: bcrypt hashes start with
+// "$2a$"/"$2b$"/"$2y$", and Argon2id hashes use the PHC string format
+// "$argon2id$v=19$m=<KiB>,t=<passes>,p=<lanes>$<salt>$<key>". New passwords are
+// hashed with the configured algorithm (PASSWORD_HASHER) while verification
+// always follows the stored prefix, so switching algorithms never locks out
+// users whose hash predates the switch. After a successful login, a hash made
+// with another algorithm or other parameters is transparently upgraded
+// (see AuthService.rehashPassword).
+
+// HashAlgorithm names a password hashing backend.
+type HashAlgorithm string
+
+const (
+	// HashBcrypt hashes with bcrypt (cost Options.BcryptCost, default
+	// bcrypt.DefaultCost). Passwords longer than 72 bytes are rejected by bcrypt.
+	HashBcrypt HashAlgorithm = "bcrypt"
+	// HashArgon2id hashes with Argon2id (RFC 9106) and has no length limit.
+	HashArgon2id HashAlgorithm = "argon2id"
+)
+
+// Argon2id parameters for new hashes (OWASP minimum: 19 MiB, 2 passes, 1 lane).
+// Existing hashes keep the parameters encoded in them.
+const (
+	argon2Memory  = 19 * 1024
+	argon2Time    = 2
+	argon2Threads = 1
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// errPasswordMismatch is returned by PasswordHasher.Compare for a wrong password.
+var errPasswordMismatch = errors.New("password does not match hash")
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password, including its algorithm prefix.
+	Hash(password string) (string, error)
+	// Compare returns nil when password matches hash, and an error otherwise.
+	Compare(hash, password string) error
+	// NeedsRehash reports whether hash was made with another algorithm or other
+	// parameters than this hasher would use now.
+	NeedsRehash(hash string) bool
+}
+
+// newPasswordHasher returns the backend for algorithm, defaulting to bcrypt.
+// bcryptCost applies to bcrypt only; 0 means bcrypt.DefaultCost.
+func newPasswordHasher(algorithm HashAlgorithm, bcryptCost int) PasswordHasher {
+	if algorithm == HashArgon2id {
+		return argon2idHasher{}
+	}
+	if bcryptCost == 0 {
+		bcryptCost = bcrypt.DefaultCost
+	}
+	return bcryptHasher{cost: bcryptCost}
+}
+
+// hasherForHash picks the backend that produced a stored hash from its prefix.
+// Unknown formats fall back to bcrypt, which rejects them.
+func hasherForHash(hash string) PasswordHasher {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return argon2idHasher{}
+	}
+	return bcryptHasher{cost: bcrypt.DefaultCost}
+}
+
+// comparePassword verifies password against a stored hash of any supported algorithm.
+func comparePassword(hash, password string) error {
+	return hasherForHash(hash).Compare(hash, password)
+}
+
+// bcryptHasher implements PasswordHasher with bcrypt at the given cost.
+type bcryptHasher struct {
+	cost int
+}
+
+func (h bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (bcryptHasher) Compare(hash, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return errPasswordMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+func (h bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// argon2idHasher implements PasswordHasher with Argon2id in PHC string format.
+type argon2idHasher struct{}
+
+func (argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return argon2idPrefix() +
+		base64.RawStdEncoding.EncodeToString(salt) + "$" +
+		base64.RawStdEncoding.EncodeToString(key), nil
+}
+
+func (argon2idHasher) NeedsRehash(hash string) bool {
+	return !strings.HasPrefix(hash, argon2idPrefix())
+}
+
+// argon2idPrefix is the PHC prefix (algorithm, version, parameters) of new hashes.
+func argon2idPrefix() string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$", argon2.Version, argon2Memory, argon2Time, argon2Threads)
+}
+
+func (argon2idHasher) Compare(hash, password string) error {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return errors.New("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return fmt.Errorf("unsupported argon2id version %q", parts[2])
+	}
+	var memory, passes uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &passes, &threads); err != nil {
+		return fmt.Errorf("parse argon2id parameters: %w", err)
+	}
+	if memory == 0 || passes == 0 || threads == 0 {
+		return fmt.Errorf("invalid argon2id parameters %q", parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return fmt.Errorf("decode argon2id salt: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return errors.New("decode argon2id key")
+	}
+
+	got := argon2.IDKey([]byte(password), salt, passes, memory, threads, uint32(len(want)))
+	if subtle.ConstantTimeCompare(got, want) != 1 {
+		return errPasswordMismatch
+	}
+	return nil
+}
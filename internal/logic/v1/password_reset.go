@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultPasswordResetTTL is used when Options.PasswordResetTTL is not set.
+const defaultPasswordResetTTL = time.Hour
+
+// tokenDeliveryTimeout bounds the background insert and delivery of emailed tokens.
+const tokenDeliveryTimeout = 30 * time.Second
+
+// RequestPasswordReset starts the forgot-password flow for email.
+// The request does no work of its own: looking the email up, creating the token
+// and delivering it all happen in the background, so neither the response nor
+// its timing depends on whether the email is registered or how it matched.
+// With Options.PasswordResetBackupEmail, email may also be a user's confirmed
+// backup email; the token is then sent to the backup address.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) {
+	ctx, span := middleware.StartSpan(ctx, "auth.request_password_reset", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	// Detach from the request so delivery is not cancelled when the response is sent
+	go s.deliverPasswordReset(context.WithoutCancel(ctx), normalizeEmail(email))
+}
+
+// deliverPasswordReset finds the account for email, stores a new reset token for
+// it and hands the token to the notifier, addressed to the primary or backup
+// email that matched. The token is generated before the lookup so unknown
+// emails cost the same. It runs after the request has been answered, so
+// failures can only be logged.
+func (s *AuthService) deliverPasswordReset(ctx context.Context, email string) {
+	ctx, cancel := context.WithTimeout(ctx, tokenDeliveryTimeout)
+	defer cancel()
+
+	ctx, span := middleware.StartSpan(ctx, "auth.deliver_password_reset", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Msg("Password reset token generation failed")
+		return
+	}
+
+	row, to, err := s.lookupPasswordResetUser(ctx, span, email)
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Msg("Password reset user lookup failed")
+		return
+	}
+	if row == nil {
+		span.AddEvent("password_reset.unknown_email")
+		return
+	}
+	span.SetAttributes(attribute.String("user.id", row.PublicID))
+
+	ttl := s.opts.PasswordResetTTL
+	if ttl <= 0 {
+		ttl = defaultPasswordResetTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	if err := s.userTokens.ReplaceToken(ctx, row.ID, domain.TokenPasswordReset, tokenHash, expiresAt); err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Password reset token storage failed")
+		return
+	}
+
+	if err := s.notifier.SendPasswordReset(ctx, to, token, expiresAt); err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Password reset delivery failed")
+		return
+	}
+
+	span.AddEvent("password_reset.requested")
+}
+
+// lookupPasswordResetUser returns the user whose primary email, or with
+// Options.PasswordResetBackupEmail confirmed backup email, is email, and the
+// address to send the token to. Returns a nil row when no user matches.
+func (s *AuthService) lookupPasswordResetUser(
+	ctx context.Context, span trace.Span, email string,
+) (*domain.UserRow, string, error) {
+	row, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, "", fmt.Errorf("query user by email: %w", err)
+	}
+	if row != nil || !s.opts.PasswordResetBackupEmail {
+		return row, email, nil
+	}
+
+	row, err = s.users.GetByBackupEmail(ctx, email)
+	if err != nil {
+		return nil, "", fmt.Errorf("query user by backup email: %w", err)
+	}
+	if row == nil {
+		return nil, "", nil
+	}
+	span.SetAttributes(attribute.Bool("reset.backup_email", true))
+	return row, row.BackupEmail, nil
+}
+
+// ResetPassword completes the forgot-password flow: it redeems the reset token,
+// sets the new password, and revokes every existing session of the user so
+// anyone holding the old credentials is logged out.
+// Returns ErrInvalidResetToken for unknown, expired or used tokens, and
+// ErrWeakPassword (without consuming the token) when the new password fails the policy.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.reset_password", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	// Validate and hash before consuming the token so a rejected password does
+	// not burn it; the policy needs the account's username and email.
+	tokenHash := hashOpaqueToken(token)
+	userID, ok, err := s.resets.Lookup(ctx, tokenHash)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("look up reset token: %w", err)
+	}
+	if !ok {
+		span.SetAttributes(attribute.Bool("reset.valid", false))
+		return fmt.Errorf("reset password: %w", ErrInvalidResetToken)
+	}
+	row, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query user %d: %w", userID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("reset password: %w", ErrInvalidResetToken)
+	}
+	if err := s.opts.PasswordPolicy.ValidatePassword(newPassword, row.Username, row.Email); err != nil {
+		return fmt.Errorf("reset password: %w", err)
+	}
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	userID, ok, err = s.resets.Consume(ctx, tokenHash)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("consume reset token: %w", err)
+	}
+	if !ok {
+		span.SetAttributes(attribute.Bool("reset.valid", false))
+		return fmt.Errorf("reset password: %w", ErrInvalidResetToken)
+	}
+	span.SetAttributes(attribute.Int("user.id", userID))
+
+	if err := s.users.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("update password for user %d: %w", userID, err)
+	}
+
+	// The new password proves control of the email, so lift any lockout (best-effort)
+	if err := s.users.ResetFailedLogins(ctx, userID); err != nil {
+		span.RecordError(fmt.Errorf("reset failed logins: %w", err))
+	}
+
+	if _, err := s.sessions.DeleteByUserID(ctx, userID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("revoke sessions for user %d: %w", userID, err)
+	}
+
+	if s.opts.PasswordResetNotify {
+		// Alert the owner in case they did not initiate the reset
+		go s.notifyPasswordChanged(context.WithoutCancel(ctx), userID, time.Now())
+	}
+
+	span.AddEvent("password_reset.completed")
+	return nil
+}
+
+// notifyPasswordChanged tells the user their password was changed so the real
+// owner can secure the account if they did not do it. Like token delivery it
+// runs after the response, so failures are only logged.
+func (s *AuthService) notifyPasswordChanged(ctx context.Context, userID int, changedAt time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, tokenDeliveryTimeout)
+	defer cancel()
+
+	ctx, span := middleware.StartSpan(ctx, "auth.notify_password_changed", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("user.id", userID),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	row, err := s.users.GetByID(ctx, userID)
+	if err == nil && row == nil {
+		err = ErrUserNotFound
+	}
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Int("user_id", userID).Msg("Password change notification lookup failed")
+		return
+	}
+
+	if err := s.notifier.SendPasswordChanged(ctx, row.Email, changedAt); err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", row.PublicID).Msg("Password change notification delivery failed")
+		return
+	}
+
+	span.AddEvent("password_changed.notified")
+}
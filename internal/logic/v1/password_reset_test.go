@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// slowUsers delays email lookups like a loaded database would.
+type slowUsers struct {
+	*memory.Users
+	delay time.Duration
+}
+
+func (u slowUsers) GetByEmail(ctx context.Context, email string) (*domain.UserRow, error) {
+	time.Sleep(u.delay)
+	return u.Users.GetByEmail(ctx, email)
+}
+
+func (u slowUsers) GetByBackupEmail(ctx context.Context, email string) (*domain.UserRow, error) {
+	time.Sleep(u.delay)
+	return u.Users.GetByBackupEmail(ctx, email)
+}
+
+func TestRequestPasswordResetTimingDoesNotRevealAccounts(t *testing.T) {
+	const lookupDelay = 200 * time.Millisecond
+
+	repos := memory.New()
+	repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+	r := repositories(repos)
+	r.Users = slowUsers{Users: repos.Users, delay: lookupDelay}
+	svc := NewAuthService(r, NewTokenIssuer(testTokenSecret, time.Hour), repos.Notifier,
+		Options{BcryptCost: bcrypt.MinCost, PasswordResetBackupEmail: true})
+
+	// An unknown email takes two lookups (primary, then backup) and a known one
+	// takes one; neither may happen while the caller waits.
+	elapsed := func(email string) time.Duration {
+		start := time.Now()
+		svc.RequestPasswordReset(context.Background(), email)
+		return time.Since(start)
+	}
+	known := elapsed("alice@example.com")
+	unknown := elapsed("nobody@example.com")
+
+	for name, took := range map[string]time.Duration{"known": known, "unknown": unknown} {
+		if took >= lookupDelay/2 {
+			t.Errorf("%s email: RequestPasswordReset took %s, want well under one lookup (%s)", name, took, lookupDelay)
+		}
+	}
+
+	repos.Notifier.WaitFor(t, "password_reset", "alice@example.com")
+}
+
+func TestDeliverPasswordResetUnknownEmail(t *testing.T) {
+	svc, repos := newTestService(t, Options{PasswordResetBackupEmail: true})
+	repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+
+	svc.deliverPasswordReset(context.Background(), "nobody@example.com")
+	if sent := repos.Notifier.Messages("password_reset"); len(sent) != 0 {
+		t.Errorf("password resets sent = %v, want none", sent)
+	}
+}
+
+func TestResetPasswordRevokesSessionsAndNotifies(t *testing.T) {
+	tests := []struct {
+		name   string
+		notify bool
+	}{
+		{"notification enabled", true},
+		{"notification disabled", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, Options{PasswordResetNotify: tt.notify})
+			repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+			ctx := context.Background()
+
+			var tokens []string
+			for range 2 {
+				result, err := svc.Login(ctx, domain.LoginRequest{Username: "alice", Password: "correct-horse-battery"},
+					domain.ClientInfo{})
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				tokens = append(tokens, result.Session.Token)
+			}
+			svc.deliverPasswordReset(ctx, "alice@example.com")
+			resetToken := repos.Notifier.WaitFor(t, "password_reset", "alice@example.com").Token
+
+			if err := svc.ResetPassword(ctx, resetToken, "new-correct-horse-battery"); err != nil {
+				t.Fatalf("reset: %v", err)
+			}
+
+			for _, token := range tokens {
+				if _, err := svc.GetUserByToken(ctx, token, domain.ClientInfo{}); err == nil {
+					t.Error("session still valid after the reset")
+				}
+			}
+			if tt.notify {
+				repos.Notifier.WaitFor(t, "password_changed", "alice@example.com")
+			} else if sent := repos.Notifier.Messages("password_changed"); len(sent) != 0 {
+				t.Errorf("password-changed notices = %v, want none", sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidatePassword(t *testing.T) {
+	strict := PasswordPolicy{
+		MinLength:          10,
+		MinUppercase:       1,
+		MinLowercase:       1,
+		MinDigits:          1,
+		MinSymbols:         1,
+		RejectPersonalInfo: true,
+	}
+
+	tests := []struct {
+		name      string
+		policy    PasswordPolicy
+		password  string
+		wantRules []string
+	}{
+		{name: "zero policy accepts anything short", password: "a"},
+		{name: "meets every rule", policy: strict, password: "Tr0ub4dor&3x"},
+		{name: "too short in runes", policy: PasswordPolicy{MinLength: 4}, password: "ééé", wantRules: []string{"min_length"}},
+		{name: "multibyte runes count once", policy: PasswordPolicy{MinLength: 4}, password: "éééé"},
+		{name: "missing classes", policy: strict, password: "alllowercase",
+			wantRules: []string{"min_uppercase", "min_digits", "min_symbols"}},
+		{name: "contains username", policy: strict, password: "Alice-Rocks-42", wantRules: []string{"contains_username"}},
+		{name: "contains email local part", policy: strict, password: "X!9wonderland-x", wantRules: []string{"contains_email"}},
+		{name: "72 bytes is accepted", password: strings.Repeat("a", 72)},
+		{name: "73 bytes is too long", password: strings.Repeat("a", 73), wantRules: []string{"max_length"}},
+		{name: "length limit counts bytes", password: strings.Repeat("é", 37), wantRules: []string{"max_length"}},
+		{name: "too long even when the policy is met", policy: strict, password: "Tr0ub4dor&3x" + strings.Repeat("z", 61),
+			wantRules: []string{"max_length"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.ValidatePassword(tt.password, "alice", "wonderland@example.com")
+			if len(tt.wantRules) == 0 {
+				if err != nil {
+					t.Fatalf("ValidatePassword: %v", err)
+				}
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			if !errors.As(err, &policyErr) || !errors.Is(err, ErrWeakPassword) {
+				t.Fatalf("error = %v, want a *PasswordPolicyError wrapping %v", err, ErrWeakPassword)
+			}
+			var rules []string
+			for _, v := range policyErr.Violations {
+				rules = append(rules, v.Rule)
+			}
+			if !slices.Equal(rules, tt.wantRules) {
+				t.Errorf("violated rules = %v, want %v", rules, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestPasswordCompositionRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantRule string
+	}{
+		{"uppercase", PasswordPolicy{MinUppercase: 2}, "Only-one-upper", "min_uppercase"},
+		{"lowercase", PasswordPolicy{MinLowercase: 1}, "NO-LOWER-123", "min_lowercase"},
+		{"digits", PasswordPolicy{MinDigits: 2}, "just-1-digit", "min_digits"},
+		{"symbols", PasswordPolicy{MinSymbols: 1}, "NoSymbolsHere42", "min_symbols"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var policyErr *PasswordPolicyError
+			if err := tt.policy.ValidatePassword(tt.password, "", ""); !errors.As(err, &policyErr) {
+				t.Fatalf("error = %v, want a *PasswordPolicyError", err)
+			}
+			if len(policyErr.Violations) != 1 {
+				t.Fatalf("violations = %+v, want only %s", policyErr.Violations, tt.wantRule)
+			}
+			v := policyErr.Violations[0]
+			if v.Rule != tt.wantRule || v.Field != "password" || v.Message == "" {
+				t.Errorf("violation = %+v, want rule %s on field password with a message", v, tt.wantRule)
+			}
+
+			// One more character of the missing class complies
+			if err := tt.policy.ValidatePassword(tt.password+"Aa1!", "", ""); err != nil {
+				t.Errorf("compliant password: %v", err)
+			}
+		})
+	}
+
+	compliant := PasswordPolicy{MinUppercase: 2, MinLowercase: 1, MinDigits: 2, MinSymbols: 1}
+	if err := compliant.ValidatePassword("TWO-upper-42", "", ""); err != nil {
+		t.Errorf("password meeting every composition rule: %v", err)
+	}
+}
+
+func TestPasswordPolicyAppliesToEveryFlow(t *testing.T) {
+	const weak = "alllowercase"
+	ctx := context.Background()
+	svc, repos := newTestService(t, Options{PasswordPolicy: PasswordPolicy{MinDigits: 1}})
+	repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery-1", bcrypt.MinCost)
+
+	result, err := svc.Login(ctx, domain.LoginRequest{Username: "alice", Password: "correct-horse-battery-1"}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	requester, err := svc.GetUserByToken(ctx, result.Session.Token, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	svc.deliverPasswordReset(ctx, "alice@example.com")
+	resetToken := repos.Notifier.WaitFor(t, "password_reset", "alice@example.com").Token
+
+	flows := map[string]func() error{
+		"register": func() error {
+			_, err := svc.Register(ctx, domain.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: weak},
+				domain.ClientInfo{})
+			return err
+		},
+		"change password": func() error {
+			return svc.ChangePassword(ctx, requester, result.Session.Token,
+				domain.ChangePasswordRequest{CurrentPassword: "correct-horse-battery-1", NewPassword: weak})
+		},
+		"reset password": func() error {
+			return svc.ResetPassword(ctx, resetToken, weak)
+		},
+	}
+	for name, flow := range flows {
+		t.Run(name, func(t *testing.T) {
+			var policyErr *PasswordPolicyError
+			if err := flow(); !errors.As(err, &policyErr) {
+				t.Fatalf("error = %v, want a *PasswordPolicyError", err)
+			}
+			if policyErr.Violations[0].Rule != "min_digits" {
+				t.Errorf("violations = %+v, want min_digits", policyErr.Violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Refresh tokens let access tokens stay short-lived without forcing a new login.
+// Each session carries one usable refresh token at a time: a refresh uses it up,
+// re-keys the session to a new access token (the previous one stops working) and
+// returns a new refresh token valid for Options.RefreshTokenTTL. The session's
+// expiry follows the newest refresh token, so an active session slides forward
+// while an abandoned one expires RefreshTokenTTL after its last refresh.
+//
+// Every refresh token rotated from one login shares a family ID. A token is
+// only ever presented twice if it was copied, so presenting an already-used
+// token is treated as theft and revokes the whole family, together with the
+// sessions holding it; the thief and the victim both have to log in again.
+// With Options.RefreshTokenBinding, the family is also bound to the device it
+// was issued to, and a refresh from another device revokes it the same way.
+
+// Refresh exchanges a refresh token for a new access token and refresh token.
+// The client is checked against the session binding like any other token use,
+// and against the refresh token's own device binding.
+// Returns ErrInvalidRefreshToken when the token is unknown, expired or already used,
+// or was presented from another device; reuse and a device mismatch also revoke
+// the token's family.
+func (s *AuthService) Refresh(
+	ctx context.Context, refreshToken string, client domain.ClientInfo,
+) (*domain.AuthResponse, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.refresh", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	tokenHash := hashOpaqueToken(refreshToken)
+	consumed, err := s.refreshTokens.Consume(ctx, tokenHash)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("consume refresh token: %w", err)
+	}
+	if consumed == nil {
+		span.SetAttributes(attribute.Bool("refresh.success", false))
+		s.detectRefreshReuse(ctx, span, tokenHash)
+		return nil, fmt.Errorf("refresh session: %w", ErrInvalidRefreshToken)
+	}
+
+	row, err := s.sessions.GetByPublicID(ctx, consumed.SessionID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("query session %s: %w", consumed.SessionID, err)
+	}
+	if row == nil {
+		// Revoked between consuming the token and loading the session
+		span.SetAttributes(attribute.Bool("refresh.success", false))
+		return nil, fmt.Errorf("lookup session %s: %w", consumed.SessionID, ErrInvalidRefreshToken)
+	}
+
+	// A refresh token presented from another device was stolen: revoke its family
+	if !s.opts.RefreshTokenBinding.allows(consumed.Binding, client) {
+		span.SetAttributes(attribute.Bool("refresh.success", false))
+		s.revokeRefreshFamily(ctx, span, consumed.FamilyID)
+		middleware.RecordSecurityEvent(ctx, "refresh_token_device_mismatch",
+			attribute.String("user.id", row.UserPublicID),
+			attribute.String("session.id", row.PublicID),
+			attribute.String("refresh.family_id", consumed.FamilyID),
+		)
+		return nil, fmt.Errorf("refresh session %s from another device: %w", row.PublicID, ErrInvalidRefreshToken)
+	}
+
+	if err := s.checkSession(ctx, span, row, client); err != nil {
+		return nil, err
+	}
+
+	token, claims, err := s.tokens.Issue(row.UserPublicID, string(row.Role))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("issue token: %w", err)
+	}
+	refreshExpiresAt := s.refreshTokenExpiry()
+
+	if err := s.sessions.Rotate(ctx, row.ID, claims.ID, laterOf(claims.Expiry(), refreshExpiresAt)); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rotate session %d: %w", row.ID, err)
+	}
+
+	// The new token stays in the family and inherits its device binding
+	newRefreshToken, err := s.issueRefreshToken(ctx, domain.NewRefreshToken{
+		SessionID: row.PublicID,
+		FamilyID:  consumed.FamilyID,
+		ExpiresAt: refreshExpiresAt,
+		Binding:   consumed.Binding,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	user := userFromSession(row)
+
+	span.SetAttributes(
+		attribute.String("user.id", user.ID),
+		attribute.Bool("refresh.success", true),
+	)
+
+	return &domain.AuthResponse{
+		Token:        token,
+		TokenType:    domain.TokenTypeBearer,
+		ExpiresAt:    claims.Expiry().UTC(),
+		RefreshToken: newRefreshToken,
+		SessionID:    row.PublicID,
+		User:         *user,
+	}, nil
+}
+
+// refreshTokenExpiry returns the expiry for a refresh token issued now, or the
+// zero time when refresh tokens are disabled.
+func (s *AuthService) refreshTokenExpiry() time.Time {
+	if s.opts.RefreshTokenTTL <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(s.opts.RefreshTokenTTL)
+}
+
+// issueRefreshToken generates a new refresh token, stores it with the fields of
+// token (TokenHash is filled in here) and returns it.
+// Returns "" when refresh tokens are disabled.
+func (s *AuthService) issueRefreshToken(ctx context.Context, token domain.NewRefreshToken) (string, error) {
+	if s.opts.RefreshTokenTTL <= 0 {
+		return "", nil
+	}
+
+	refreshToken, hash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	token.TokenHash = hash
+	if err := s.refreshTokens.Create(ctx, token); err != nil {
+		return "", fmt.Errorf("create refresh token: %w", err)
+	}
+	return refreshToken, nil
+}
+
+// detectRefreshReuse revokes the family of a refresh token that was presented
+// after it had already been rotated. Best-effort: the caller rejects the refresh
+// either way, and an unknown or merely expired token is left alone.
+func (s *AuthService) detectRefreshReuse(ctx context.Context, span trace.Span, tokenHash string) {
+	token, err := s.refreshTokens.GetByHash(ctx, tokenHash)
+	if err != nil {
+		span.RecordError(fmt.Errorf("look up refresh token: %w", err))
+		return
+	}
+	if token == nil || !token.Used {
+		return
+	}
+
+	revoked := s.revokeRefreshFamily(ctx, span, token.FamilyID)
+	middleware.RecordSecurityEvent(ctx, "refresh_token_reused",
+		attribute.String("session.id", token.SessionID),
+		attribute.String("refresh.family_id", token.FamilyID),
+		attribute.Int64("session.revoked", revoked),
+	)
+}
+
+// revokeRefreshFamily revokes every session holding a token of the family, and
+// with them the family itself. Returns the number of sessions revoked.
+// Best-effort: the caller rejects the refresh either way.
+func (s *AuthService) revokeRefreshFamily(ctx context.Context, span trace.Span, familyID string) int64 {
+	revoked, err := s.refreshTokens.RevokeFamily(ctx, familyID)
+	if err != nil {
+		span.RecordError(fmt.Errorf("revoke refresh family %s: %w", familyID, err))
+		return 0
+	}
+	span.AddEvent("refresh.family_revoked", trace.WithAttributes(attribute.Int64("session.revoked", revoked)))
+	return revoked
+}
+
+// laterOf returns the later of two times.
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const refreshTestPassword = "correct-horse-battery-staple"
+
+// newRefreshTestService returns a service with refresh tokens enabled and one user, alice.
+func newRefreshTestService(t *testing.T) (*AuthService, *memory.Repos) {
+	t.Helper()
+
+	svc, repos := newTestService(t, Options{RefreshTokenTTL: 24 * time.Hour})
+	repos.Users.AddUser(t, "alice", "alice@example.com", refreshTestPassword, bcrypt.MinCost)
+	return svc, repos
+}
+
+func loginAlice(t *testing.T, svc *AuthService) *domain.AuthResponse {
+	t.Helper()
+
+	result, err := svc.Login(context.Background(),
+		domain.LoginRequest{Username: "alice", Password: refreshTestPassword}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	resp := result.Session
+	if resp.RefreshToken == "" {
+		t.Fatal("login returned no refresh token")
+	}
+	return resp
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	svc, _ := newRefreshTestService(t)
+	ctx := context.Background()
+	login := loginAlice(t, svc)
+
+	refreshed, err := svc.Refresh(ctx, login.RefreshToken, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == login.RefreshToken {
+		t.Errorf("refresh did not rotate the refresh token")
+	}
+	if refreshed.SessionID != login.SessionID {
+		t.Errorf("refresh moved the session: %s, want %s", refreshed.SessionID, login.SessionID)
+	}
+	if _, err := svc.GetUserByToken(ctx, login.Token, domain.ClientInfo{}); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("previous access token after refresh: error = %v, want %v", err, ErrSessionNotFound)
+	}
+	if _, err := svc.GetUserByToken(ctx, refreshed.Token, domain.ClientInfo{}); err != nil {
+		t.Errorf("new access token: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	svc, repos := newRefreshTestService(t)
+	ctx := context.Background()
+	login := loginAlice(t, svc)
+	other := loginAlice(t, svc) // a second login is a separate family
+
+	refreshed, err := svc.Refresh(ctx, login.RefreshToken, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// Replaying the rotated token signals theft
+	if _, err := svc.Refresh(ctx, login.RefreshToken, domain.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("reused refresh token: error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	// Every token of the family is dead, including the legitimate latest one
+	if _, err := svc.Refresh(ctx, refreshed.RefreshToken, domain.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("latest refresh token of a revoked family: error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if _, err := svc.GetUserByToken(ctx, refreshed.Token, domain.ClientInfo{}); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("access token of a revoked family: error = %v, want %v", err, ErrSessionNotFound)
+	}
+
+	// Other families of the same user are untouched
+	if _, err := svc.GetUserByToken(ctx, other.Token, domain.ClientInfo{}); err != nil {
+		t.Errorf("other session after family revocation: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, other.RefreshToken, domain.ClientInfo{}); err != nil {
+		t.Errorf("other family after revocation: %v", err)
+	}
+	if n := repos.Sessions.Count(); n != 1 {
+		t.Errorf("sessions left = %d, want 1", n)
+	}
+}
+
+func TestRefreshUnknownTokenRevokesNothing(t *testing.T) {
+	svc, repos := newRefreshTestService(t)
+	login := loginAlice(t, svc)
+
+	if _, err := svc.Refresh(context.Background(), "not-a-refresh-token", domain.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("unknown refresh token: error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if _, err := svc.GetUserByToken(context.Background(), login.Token, domain.ClientInfo{}); err != nil {
+		t.Errorf("session after an unknown refresh token: %v", err)
+	}
+	if n := repos.Sessions.Count(); n != 1 {
+		t.Errorf("sessions left = %d, want 1", n)
+	}
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// registrationDedup collapses identical registration submissions that arrive
+// within a short window (double clicks, client retries after a timeout) into a
+// single registration. Duplicates wait for the first attempt and receive its
+// result instead of a confusing username_exists conflict.
+//
+// State is per process: duplicates spread across replicas are still caught by
+// the unique constraints, just with the regular 409 response.
+type registrationDedup struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*registrationAttempt
+}
+
+// registrationAttempt is one in-flight or recently completed registration.
+type registrationAttempt struct {
+	done      chan struct{}
+	resp      *domain.AuthResponse
+	err       error
+	expiresAt time.Time // zero while in flight
+}
+
+func newRegistrationDedup(window time.Duration) *registrationDedup {
+	return &registrationDedup{window: window, entries: make(map[string]*registrationAttempt)}
+}
+
+// do runs register once per key within the window. Only successful results are
+// kept after completion; a failed attempt is shared with callers already waiting
+// on it, but the next submission runs again.
+func (d *registrationDedup) do(
+	key string, register func() (*domain.AuthResponse, error),
+) (resp *domain.AuthResponse, shared bool, err error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	d.pruneLocked(now)
+	if attempt, ok := d.entries[key]; ok {
+		d.mu.Unlock()
+		<-attempt.done
+		return attempt.resp, true, attempt.err
+	}
+	attempt := &registrationAttempt{done: make(chan struct{})}
+	d.entries[key] = attempt
+	d.mu.Unlock()
+
+	attempt.resp, attempt.err = register()
+
+	d.mu.Lock()
+	if attempt.err != nil {
+		delete(d.entries, key)
+	} else {
+		attempt.expiresAt = time.Now().Add(d.window)
+	}
+	d.mu.Unlock()
+	close(attempt.done)
+
+	return attempt.resp, false, attempt.err
+}
+
+// pruneLocked drops completed entries whose window has passed. d.mu must be held.
+func (d *registrationDedup) pruneLocked(now time.Time) {
+	for key, attempt := range d.entries {
+		if !attempt.expiresAt.IsZero() && now.After(attempt.expiresAt) {
+			delete(d.entries, key)
+		}
+	}
+}
+
+// registrationDedupKey identifies a submission by its normalized identifiers
+// plus a hash of the full body, so a retry with a different password or invite
+// is treated as a new registration. Only the digest is kept in memory.
+func registrationDedupKey(req domain.RegisterRequest) string {
+	h := sha256.New()
+	for _, part := range []string{
+		strings.ToLower(strings.TrimSpace(req.Username)),
+		strings.ToLower(strings.TrimSpace(req.Email)),
+		req.Password,
+		req.InviteToken,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+func dedupRegisterRequest(password string) domain.RegisterRequest {
+	return domain.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: password}
+}
+
+func TestRegisterDedupReplaysWithinWindow(t *testing.T) {
+	svc, repos := newTestService(t, Options{RegistrationDedupWindow: time.Minute})
+	ctx := context.Background()
+
+	first, err := svc.Register(ctx, dedupRegisterRequest("correct-horse-battery"), domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	// Case and whitespace differences in the identifiers are the same submission
+	retry := dedupRegisterRequest("correct-horse-battery")
+	retry.Username, retry.Email = " Alice ", "ALICE@example.com"
+	replayed, err := svc.Register(ctx, retry, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("identical registration within the window: %v", err)
+	}
+	if replayed != first {
+		t.Errorf("identical registration got a new result %+v, want the first one %+v", replayed, first)
+	}
+	if n := repos.Sessions.Count(); n != 1 {
+		t.Errorf("sessions = %d, want 1", n)
+	}
+}
+
+func TestRegisterDedupDifferentPasswordDoesNotReplay(t *testing.T) {
+	svc, _ := newTestService(t, Options{RegistrationDedupWindow: time.Minute})
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, dedupRegisterRequest("correct-horse-battery"), domain.ClientInfo{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	// Replaying here would hand the first registrant's session to whoever
+	// guessed the username and email.
+	_, err := svc.Register(ctx, dedupRegisterRequest("another-horse-battery"), domain.ClientInfo{})
+	if !errors.Is(err, ErrUsernameExists) {
+		t.Errorf("different password within the window: error = %v, want %v", err, ErrUsernameExists)
+	}
+}
+
+func TestRegisterDedupExpiresAfterWindow(t *testing.T) {
+	const window = 50 * time.Millisecond
+	svc, _ := newTestService(t, Options{RegistrationDedupWindow: window})
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, dedupRegisterRequest("correct-horse-battery"), domain.ClientInfo{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	time.Sleep(2 * window)
+
+	_, err := svc.Register(ctx, dedupRegisterRequest("correct-horse-battery"), domain.ClientInfo{})
+	if !errors.Is(err, ErrUsernameExists) {
+		t.Errorf("identical registration after the window: error = %v, want %v", err, ErrUsernameExists)
+	}
+}
+
+func TestRegistrationDedupSharesInFlightAttempt(t *testing.T) {
+	d := newRegistrationDedup(time.Minute)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	register := func() (*domain.AuthResponse, error) {
+		calls.Add(1)
+		<-release
+		return &domain.AuthResponse{SessionID: "s1"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*domain.AuthResponse, 3)
+	for i := range results {
+		wg.Go(func() {
+			resp, _, err := d.do("key", register)
+			if err != nil {
+				t.Errorf("do: %v", err)
+			}
+			results[i] = resp
+		})
+	}
+	// Let every caller reach the dedup before the first attempt completes
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("register ran %d times, want 1", n)
+	}
+	for i, resp := range results {
+		if resp != results[0] {
+			t.Errorf("caller %d got %+v, want the shared %+v", i, resp, results[0])
+		}
+	}
+}
+
+func TestRegistrationDedupForgetsFailures(t *testing.T) {
+	d := newRegistrationDedup(time.Minute)
+	failure := errors.New("database down")
+
+	if _, _, err := d.do("key", func() (*domain.AuthResponse, error) { return nil, failure }); !errors.Is(err, failure) {
+		t.Fatalf("first attempt: error = %v, want %v", err, failure)
+	}
+	resp, shared, err := d.do("key", func() (*domain.AuthResponse, error) {
+		return &domain.AuthResponse{SessionID: "s1"}, nil
+	})
+	if err != nil || shared || resp == nil {
+		t.Errorf("retry after a failure = %+v, shared %v, error %v; want a fresh success", resp, shared, err)
+	}
+}
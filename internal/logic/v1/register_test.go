@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRegisterUniquenessPerColumn(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     domain.RegisterRequest
+		wantErr error
+	}{
+		{"username equal to another user's email",
+			domain.RegisterRequest{Username: "bob@example.com", Email: "robert@example.com"}, nil},
+		{"email equal to another user's username",
+			domain.RegisterRequest{Username: "carol", Email: "legacy@example.com"}, nil},
+		{"username taken", domain.RegisterRequest{Username: "bob", Email: "new@example.com"}, ErrUsernameExists},
+		{"email taken", domain.RegisterRequest{Username: "newbie", Email: "bob@example.com"}, ErrEmailExists},
+		{"both taken reports the username", domain.RegisterRequest{Username: "bob", Email: "bob@example.com"}, ErrUsernameExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, Options{})
+			repos.Users.AddUser(t, "bob", "bob@example.com", "correct-horse-battery", bcrypt.MinCost)
+			// An account from before the username rules, named like an email address
+			repos.Users.AddUser(t, "legacy@example.com", "someone@example.com", "correct-horse-battery", bcrypt.MinCost)
+
+			tt.req.Password = "correct-horse-battery-staple"
+			_, err := svc.Register(context.Background(), tt.req, domain.ClientInfo{})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Register: %v, want no conflict", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Register: error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
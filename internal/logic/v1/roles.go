@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AuthorizeRole checks that user holds role. user must come from GetUserByToken,
+// which reads the role from the database on every request, so demoting a user
+// takes effect immediately even though their token still carries the old role.
+// Returns ErrForbidden when the role does not match.
+func (s *AuthService) AuthorizeRole(ctx context.Context, user *domain.User, role domain.Role) error {
+	if user.Role == role {
+		return nil
+	}
+
+	middleware.RecordSecurityEvent(ctx, "role_denied",
+		attribute.String("user.id", user.ID),
+		attribute.String("role.required", string(role)),
+		attribute.String("role.actual", string(user.Role)),
+	)
+	return fmt.Errorf("user %s requires role %q: %w", user.ID, role, ErrForbidden)
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRotateAllUserSessions(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	alice := repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+	repos.Users.AddUser(t, "bob", "bob@example.com", "correct-horse-battery", bcrypt.MinCost)
+
+	// The security event is logged through the logger carried by the context
+	var logs bytes.Buffer
+	ctx := zerolog.New(&logs).WithContext(context.Background())
+
+	login := func(username string) string {
+		t.Helper()
+		result, err := svc.Login(ctx, domain.LoginRequest{Username: username, Password: "correct-horse-battery"},
+			domain.ClientInfo{})
+		if err != nil {
+			t.Fatalf("login %s: %v", username, err)
+		}
+		return result.Session.Token
+	}
+	aliceTokens := []string{login("alice"), login("alice")}
+	bobToken := login("bob")
+	logs.Reset()
+
+	revoked, err := svc.RotateAllUserSessions(ctx, alice.ID, "new_device_burst")
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("revoked = %d, want 2", revoked)
+	}
+
+	for _, token := range aliceTokens {
+		if _, err := svc.GetUserByToken(ctx, token, domain.ClientInfo{}); err == nil {
+			t.Errorf("alice's session still valid after rotation")
+		}
+	}
+	if _, err := svc.GetUserByToken(ctx, bobToken, domain.ClientInfo{}); err != nil {
+		t.Errorf("bob's session after rotating alice's: %v", err)
+	}
+
+	var event struct {
+		Level           string `json:"level"`
+		SecurityEvent   string `json:"security_event"`
+		UserID          int    `json:"user_id"`
+		Reason          string `json:"reason"`
+		SessionsRevoked int64  `json:"sessions_revoked"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &event); err != nil {
+		t.Fatalf("security event log %q: %v", logs.String(), err)
+	}
+	if event.Level != "warn" || event.SecurityEvent != "sessions_rotated" || event.UserID != alice.ID ||
+		event.Reason != "new_device_burst" || event.SessionsRevoked != 2 {
+		t.Errorf("security event = %+v, want sessions_rotated for user %d, reason new_device_burst, 2 revoked",
+			event, alice.ID)
+	}
+}
@@ -2,15 +2,15 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/duynhne/auth-service/internal/core/domain"
 	"github.com/duynhne/auth-service/middleware"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthService implements authentication business rules.
@@ -19,68 +19,200 @@ import (
 type AuthService struct {
 	users    domain.UserRepository
 	sessions domain.SessionRepository
+	invites  domain.InviteRepository
+	resets   domain.PasswordResetRepository
+	// verifications stores email verification tokens
+	verifications domain.EmailVerificationRepository
+	// userTokens issues reset and verification tokens, replacing earlier ones
+	userTokens domain.UserTokenRepository
+	// emailChanges stores pending email changes awaiting confirmation
+	emailChanges domain.EmailChangeRepository
+	// totp stores encrypted TOTP two-factor secrets
+	totp domain.TOTPRepository
+	// backupCodes stores hashed 2FA recovery codes
+	backupCodes domain.BackupCodeRepository
+	// refreshTokens stores hashed refresh tokens of sessions
+	refreshTokens domain.RefreshTokenRepository
+	tokens        *TokenIssuer
+	// hasher hashes new passwords with the configured algorithm
+	hasher PasswordHasher
+	// registrations deduplicates rapid identical registrations (nil when disabled)
+	registrations *registrationDedup
+	// loginBackoff delays repeated failed logins per username (nil when disabled)
+	loginBackoff *loginBackoff
+	notifier     domain.Notifier
+	opts         Options
 }
 
-// NewAuthService creates a new AuthService with the given repository dependencies.
-func NewAuthService(users domain.UserRepository, sessions domain.SessionRepository) *AuthService {
-	return &AuthService{
-		users:    users,
-		sessions: sessions,
+// Repositories groups the repository dependencies of AuthService.
+type Repositories struct {
+	Users    domain.UserRepository
+	Sessions domain.SessionRepository
+	Invites  domain.InviteRepository
+	Resets   domain.PasswordResetRepository
+	// Verifications stores email verification tokens
+	Verifications domain.EmailVerificationRepository
+	// Tokens issues reset and verification tokens, replacing earlier ones
+	Tokens domain.UserTokenRepository
+	// EmailChanges stores pending email changes awaiting confirmation
+	EmailChanges domain.EmailChangeRepository
+	// TOTP stores encrypted TOTP two-factor secrets
+	TOTP domain.TOTPRepository
+	// BackupCodes stores hashed 2FA recovery codes
+	BackupCodes domain.BackupCodeRepository
+	// RefreshTokens stores hashed refresh tokens of sessions
+	RefreshTokens domain.RefreshTokenRepository
+}
+
+// Options holds the tunable business rules for AuthService.
+// The zero value keeps every optional policy disabled.
+type Options struct {
+	PasswordPolicy PasswordPolicy
+	// PasswordHasher is the algorithm for newly set passwords (default: bcrypt).
+	// Stored hashes are always verified with the algorithm they were created with.
+	PasswordHasher HashAlgorithm
+	// BcryptCost is the bcrypt cost for new hashes (0 = bcrypt.DefaultCost); see CalibrateBcryptCost.
+	// Existing hashes at another cost are upgraded on their next successful login.
+	BcryptCost int
+	// PasswordMaxAge forces a reset once a password is this old (0 disables expiry).
+	PasswordMaxAge time.Duration
+	SessionBinding BindingMode
+	// SessionSubnetBinding rejects a session used from outside the /24 (IPv4) or /64 (IPv6) it was issued from.
+	SessionSubnetBinding bool
+	// SessionTouchInterval throttles last_used_at updates to at most one per interval.
+	SessionTouchInterval time.Duration
+	// LastLoginInterval throttles users.last_login updates to at most one per interval.
+	LastLoginInterval time.Duration
+	// SessionIdleTimeout expires a session unused for this long (0 disables idle expiry).
+	SessionIdleTimeout time.Duration
+	// SessionLazyCleanup deletes an expired or idle session as soon as its token is
+	// presented, in addition to the periodic cleanup job.
+	SessionLazyCleanup bool
+	// RegistrationMode controls self-service registration (open, invite, closed).
+	RegistrationMode RegistrationMode
+	// InviteTTL is how long an issued invite stays redeemable (default: 7 days).
+	InviteTTL time.Duration
+	// PasswordResetTTL is how long a password reset token stays valid (default: 1 hour).
+	PasswordResetTTL time.Duration
+	// PasswordResetNotify emails the user after a successful password reset.
+	PasswordResetNotify bool
+	// PasswordResetBackupEmail lets forgot-password target a user's confirmed backup email.
+	PasswordResetBackupEmail bool
+	// EmailVerificationTTL is how long an email verification or email change link stays valid (default: 24 hours).
+	EmailVerificationTTL time.Duration
+	// EmailVerificationResendInterval is the minimum time between verification emails
+	// for one user (0 disables the throttle).
+	EmailVerificationResendInterval time.Duration
+	// RegistrationDedupWindow is how long a successful registration is replayed to
+	// identical resubmissions instead of failing with a conflict (0 disables).
+	RegistrationDedupWindow time.Duration
+	// UsernameChangeInterval is the minimum time between username changes of one
+	// user, and how long a released username stays unavailable to others (0 disables).
+	UsernameChangeInterval time.Duration
+	// TOTPIssuer labels accounts in authenticator apps (default: "auth-service").
+	TOTPIssuer string
+	// TOTPEncryptionKey is the 32-byte AES-256 key for TOTP secrets at rest.
+	// Empty disables two-factor enrollment.
+	TOTPEncryptionKey []byte
+	// SessionTTLMin and SessionTTLMax bound a client-requested session lifetime (0 = unbounded).
+	SessionTTLMin time.Duration
+	SessionTTLMax time.Duration
+	// RefreshTokenTTL is how long a refresh token stays valid; each refresh issues a
+	// new one and extends the session to match (0 disables refresh tokens).
+	RefreshTokenTTL time.Duration
+	// RefreshTokenBinding ties refresh tokens to the device (X-Device-ID) they were
+	// issued to; a refresh from another device revokes the session.
+	RefreshTokenBinding BindingMode
+	// LoginChecks orders the pre-session checks run by Authenticate (default: DefaultLoginChecks).
+	LoginChecks []LoginCheck
+	// LockoutThreshold locks an account after this many consecutive bad passwords (0 disables lockout).
+	LockoutThreshold int
+	// LockoutDuration is how long a locked account stays locked.
+	LockoutDuration time.Duration
+	// LoginBackoffBase is the first delay imposed on a username after repeated failed
+	// logins, doubling per further failure up to LoginBackoffMax (0 disables backoff).
+	LoginBackoffBase time.Duration
+	LoginBackoffMax  time.Duration
+}
+
+// NewAuthService creates a new AuthService with the given repository dependencies,
+// the issuer used to sign access tokens, and the notifier that delivers reset tokens.
+func NewAuthService(repos Repositories, tokens *TokenIssuer, notifier domain.Notifier, opts Options) *AuthService {
+	s := &AuthService{
+		users:         repos.Users,
+		sessions:      repos.Sessions,
+		invites:       repos.Invites,
+		resets:        repos.Resets,
+		verifications: repos.Verifications,
+		userTokens:    repos.Tokens,
+		emailChanges:  repos.EmailChanges,
+		totp:          repos.TOTP,
+		backupCodes:   repos.BackupCodes,
+		refreshTokens: repos.RefreshTokens,
+		tokens:        tokens,
+		hasher:        newPasswordHasher(opts.PasswordHasher, opts.BcryptCost),
+		notifier:      notifier,
+		opts:          opts,
+	}
+	if opts.RegistrationDedupWindow > 0 {
+		s.registrations = newRegistrationDedup(opts.RegistrationDedupWindow)
+	}
+	if opts.LoginBackoffBase > 0 {
+		s.loginBackoff = newLoginBackoff(opts.LoginBackoffBase, opts.LoginBackoffMax)
 	}
+	return s
+}
+
+// LoginResult is the outcome of a login that was not rejected. Either Session
+// holds the new session (Next is StepNone), or Session is nil and Next names the
+// step the client must complete before logging in again, e.g. StepTOTP.
+type LoginResult struct {
+	Next    NextStep
+	Session *domain.AuthResponse
 }
 
 // Login handles user login business logic.
-func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest) (*domain.AuthResponse, error) {
+// Rejected logins return an error; a login waiting on another step returns a
+// LoginResult without a session.
+func (s *AuthService) Login(
+	ctx context.Context, req domain.LoginRequest, client domain.ClientInfo,
+) (*LoginResult, error) {
 	ctx, span := middleware.StartSpan(ctx, "auth.login", trace.WithAttributes(
 		attribute.String("layer", "logic"),
 		attribute.String("username", req.Username),
 	))
 	defer span.End()
 
-	// Lookup user by username via repository
-	row, err := s.users.GetByUsername(ctx, req.Username)
+	// Run the pre-session checks (lockout, password, password age)
+	decision, err := s.Authenticate(ctx, req)
 	if err != nil {
-		span.RecordError(err)
-		return nil, fmt.Errorf("query user %q: %w", req.Username, err)
-	}
-	if row == nil {
 		span.SetAttributes(attribute.Bool("auth.success", false))
-		span.AddEvent("authentication.failed")
-		return nil, fmt.Errorf("authenticate user %q: %w", req.Username, ErrUserNotFound)
+		return nil, err
 	}
-
-	// Verify password
-	err = bcrypt.CompareHashAndPassword([]byte(row.PasswordHash), []byte(req.Password))
-	if err != nil {
-		span.SetAttributes(attribute.Bool("auth.success", false))
-		span.AddEvent("authentication.failed")
-		return nil, fmt.Errorf("authenticate user %q: %w", req.Username, ErrInvalidCredentials)
+	if decision.Next != StepNone {
+		span.SetAttributes(
+			attribute.Bool("auth.success", false),
+			attribute.String("auth.next_step", string(decision.Next)),
+		)
+		return &LoginResult{Next: decision.Next}, nil
 	}
+	row := decision.User
 
 	// Update last_login timestamp (best-effort, don't fail login)
-	if updateErr := s.users.UpdateLastLogin(ctx, row.ID); updateErr != nil {
+	if _, updateErr := s.users.UpdateLastLogin(ctx, row.ID, s.opts.LastLoginInterval); updateErr != nil {
 		span.RecordError(fmt.Errorf("update last_login: %w", updateErr))
 	}
 
-	// Create session token (simplified stub - in production use JWT)
-	token := fmt.Sprintf("jwt-token-v1-%d-%d", row.ID, time.Now().Unix())
-
-	// Persist session (best-effort, don't fail login)
-	expiresAt := time.Now().Add(24 * time.Hour)
-	if sessErr := s.sessions.Create(ctx, row.ID, token, expiresAt); sessErr != nil {
-		span.RecordError(fmt.Errorf("create session: %w", sessErr))
-	}
-
-	user := domain.User{
-		ID:       strconv.Itoa(row.ID),
-		Username: row.Username,
-		Email:    row.Email,
+	// Issue signed token and persist its session
+	ttl := s.sessionTTL(time.Duration(req.ExpiresIn) * time.Second)
+	response, err := s.issueSession(ctx, row, client, ttl)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	response := &domain.AuthResponse{
-		Token: token,
-		User:  user,
-	}
+	user := userFromRow(row)
+	response.User = *user
 
 	span.SetAttributes(
 		attribute.String("user.id", user.ID),
@@ -88,11 +220,17 @@ func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest) (*doma
 	)
 	span.AddEvent("user.authenticated")
 
-	return response, nil
+	return &LoginResult{Next: StepNone, Session: response}, nil
 }
 
 // Register handles user registration business logic.
-func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest) (*domain.AuthResponse, error) {
+// Identical submissions within Options.RegistrationDedupWindow share one result.
+func (s *AuthService) Register(
+	ctx context.Context, req domain.RegisterRequest, client domain.ClientInfo,
+) (*domain.AuthResponse, error) {
+	req.Username = normalizeUsername(req.Username)
+	req.Email = normalizeEmail(req.Email)
+
 	ctx, span := middleware.StartSpan(ctx, "auth.register", trace.WithAttributes(
 		attribute.String("layer", "logic"),
 		attribute.String("username", req.Username),
@@ -100,51 +238,87 @@ func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest)
 	))
 	defer span.End()
 
+	if s.registrations == nil {
+		return s.register(ctx, span, req, client)
+	}
+
+	resp, shared, err := s.registrations.do(registrationDedupKey(req), func() (*domain.AuthResponse, error) {
+		return s.register(ctx, span, req, client)
+	})
+	span.SetAttributes(attribute.Bool("registration.deduplicated", shared))
+	return resp, err
+}
+
+// register performs a single registration; see Register.
+func (s *AuthService) register(
+	ctx context.Context, span trace.Span, req domain.RegisterRequest, client domain.ClientInfo,
+) (*domain.AuthResponse, error) {
+	// Enforce registration mode and password policy before doing any expensive work
+	if err := s.checkRegistrationMode(req); err != nil {
+		span.SetAttributes(attribute.Bool("registration.success", false))
+		return nil, fmt.Errorf("register user %q: %w", req.Username, err)
+	}
+	if err := s.opts.PasswordPolicy.ValidatePassword(req.Password, req.Username, req.Email); err != nil {
+		span.SetAttributes(attribute.Bool("registration.success", false))
+		return nil, fmt.Errorf("register user %q: %w", req.Username, err)
+	}
+
 	// Hash password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	passwordHash, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("hash password: %w", err)
 	}
 
-	// Check if username or email already exists
-	exists, err := s.users.ExistsByUsernameOrEmail(ctx, req.Username, req.Email)
+	// Check username and email uniqueness separately so each conflict is reported distinctly
+	usernameTaken, err := s.users.ExistsByUsername(ctx, req.Username)
 	if err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("check existing user: %w", err)
+		return nil, fmt.Errorf("check existing username: %w", err)
 	}
-	if exists {
+	if usernameTaken {
 		span.SetAttributes(attribute.Bool("registration.success", false))
-		return nil, fmt.Errorf("register user %q: %w", req.Username, ErrUserExists)
+		return nil, fmt.Errorf("register user %q: %w", req.Username, ErrUsernameExists)
 	}
 
-	// Insert new user
-	userID, err := s.users.Create(ctx, req.Username, req.Email, string(passwordHash))
+	emailTaken, err := s.users.ExistsByEmail(ctx, req.Email)
 	if err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("insert user: %w", err)
+		return nil, fmt.Errorf("check existing email: %w", err)
+	}
+	if emailTaken {
+		span.SetAttributes(attribute.Bool("registration.success", false))
+		return nil, fmt.Errorf("register user %q: %w", req.Username, ErrEmailExists)
 	}
 
-	// Create session token (simplified stub)
-	token := fmt.Sprintf("jwt-token-v1-%d-%d", userID, time.Now().Unix())
-
-	// Persist session (best-effort)
-	expiresAt := time.Now().Add(24 * time.Hour)
-	if sessErr := s.sessions.Create(ctx, userID, token, expiresAt); sessErr != nil {
-		span.RecordError(fmt.Errorf("create session: %w", sessErr))
+	if err := s.redeemInvite(ctx, req); err != nil {
+		span.SetAttributes(attribute.Bool("registration.success", false))
+		return nil, fmt.Errorf("register user %q: %w", req.Username, err)
 	}
 
-	user := domain.User{
-		ID:       strconv.Itoa(userID),
-		Username: req.Username,
-		Email:    req.Email,
+	// Insert new user
+	row, err := s.users.Create(ctx, req.Username, req.Email, passwordHash)
+	if err != nil {
+		span.RecordError(err)
+		s.releaseInvite(ctx, req)
+		return nil, fmt.Errorf("insert user: %w", err)
 	}
 
-	response := &domain.AuthResponse{
-		Token: token,
-		User:  user,
+	// Issue signed token and persist its session
+	response, err := s.issueSession(ctx, row, client, s.sessionTTL(0))
+	if err != nil {
+		span.RecordError(err)
+		s.discardRegistration(ctx, row, req)
+		return nil, err
 	}
 
+	// Send the verification email in the background so mail latency or outages
+	// never fail a registration; the user can request a new email later
+	go s.deliverEmailVerification(context.WithoutCancel(ctx), row)
+
+	user := userFromRow(row)
+	response.User = *user
+
 	span.SetAttributes(
 		attribute.String("user.id", user.ID),
 		attribute.Bool("registration.success", true),
@@ -154,35 +328,91 @@ func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest)
 	return response, nil
 }
 
+// issueSession signs a new access token valid for ttl and persists its session,
+// keyed by the token's jti so it can be revoked server-side. With refresh tokens
+// enabled, the session also gets a refresh token and lives as long as it does.
+// Returns the response describing the token and session; the caller fills in User.
+func (s *AuthService) issueSession(
+	ctx context.Context, user *domain.UserRow, client domain.ClientInfo, ttl time.Duration,
+) (*domain.AuthResponse, error) {
+	token, claims, err := s.tokens.IssueWithTTL(user.PublicID, string(user.Role), ttl)
+	if err != nil {
+		return nil, fmt.Errorf("issue token: %w", err)
+	}
+	refreshExpiresAt := s.refreshTokenExpiry()
+
+	// A token without a session row would be rejected by GetUserByToken, so fail here
+	sessionID, err := s.sessions.Create(ctx, domain.NewSession{
+		UserID:    user.ID,
+		Token:     claims.ID,
+		ExpiresAt: laterOf(claims.Expiry(), refreshExpiresAt),
+		Binding:   s.opts.SessionBinding.sessionBinding(client),
+		Subnet:    s.sessionSubnet(client),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+
+	// A login starts a new refresh token family
+	refreshToken, err := s.issueRefreshToken(ctx, domain.NewRefreshToken{
+		SessionID: sessionID,
+		FamilyID:  uuid.NewString(),
+		ExpiresAt: refreshExpiresAt,
+		Binding:   s.opts.RefreshTokenBinding.sessionBinding(client),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthResponse{
+		Token:        token,
+		TokenType:    domain.TokenTypeBearer,
+		ExpiresAt:    claims.Expiry().UTC(),
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
+	}, nil
+}
+
 // GetUserByToken retrieves user info from a session token (for /auth/me endpoint).
-func (s *AuthService) GetUserByToken(ctx context.Context, token string) (*domain.User, error) {
+// The client is checked against the session binding when binding is enabled.
+func (s *AuthService) GetUserByToken(ctx context.Context, token string, client domain.ClientInfo) (*domain.User, error) {
 	ctx, span := middleware.StartSpan(ctx, "auth.get_user_by_token", trace.WithAttributes(
 		attribute.String("layer", "logic"),
 	))
 	defer span.End()
 
-	row, err := s.sessions.GetUserByToken(ctx, token)
+	// Verify signature and expiry before touching the database
+	claims, err := s.tokens.ParseAndValidate(token)
+	if err != nil {
+		span.SetAttributes(attribute.Bool("session.valid", false))
+		if errors.Is(err, ErrSessionExpired) {
+			s.deleteExpiredTokenSession(ctx, span, token)
+		}
+		return nil, err
+	}
+
+	// The session is keyed by jti, so a revoked token fails here even if still signed
+	row, err := s.sessions.GetUserByToken(ctx, claims.ID)
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("query session: %w", err)
 	}
-	if row == nil {
+	if row == nil || row.UserPublicID != claims.Subject {
 		span.SetAttributes(attribute.Bool("session.valid", false))
 		return nil, fmt.Errorf("lookup session: %w", ErrSessionNotFound)
 	}
 
-	// Check if session has expired
-	if time.Now().After(row.ExpiresAt) {
-		span.SetAttributes(attribute.Bool("session.valid", false))
-		return nil, fmt.Errorf("session expired at %v: %w", row.ExpiresAt, ErrSessionExpired)
+	if err := s.checkSession(ctx, span, row, client); err != nil {
+		return nil, err
 	}
 
-	user := &domain.User{
-		ID:       strconv.Itoa(row.UserID),
-		Username: row.Username,
-		Email:    row.Email,
+	// Record activity (best-effort, throttled in the repository)
+	if _, touchErr := s.sessions.TouchLastUsed(ctx, row.ID, s.opts.SessionTouchInterval); touchErr != nil {
+		span.RecordError(fmt.Errorf("touch session: %w", touchErr))
 	}
 
+	user := userFromSession(row)
+
 	span.SetAttributes(
 		attribute.String("user.id", user.ID),
 		attribute.Bool("session.valid", true),
@@ -190,3 +420,215 @@ func (s *AuthService) GetUserByToken(ctx context.Context, token string) (*domain
 
 	return user, nil
 }
+
+// checkSession rejects a session that has expired, gone idle, or is presented by a
+// client or network other than the one it is bound to.
+func (s *AuthService) checkSession(
+	ctx context.Context, span trace.Span, row *domain.SessionRow, client domain.ClientInfo,
+) error {
+	if err := s.checkSessionLifetime(ctx, span, row); err != nil {
+		return err
+	}
+
+	// Reject a bound session presented by a different client (stolen token)
+	if !s.opts.SessionBinding.allows(row.Binding, client) {
+		span.SetAttributes(attribute.Bool("session.valid", false))
+		span.AddEvent("session.binding_mismatch")
+		return fmt.Errorf("session %d: %w", row.ID, ErrSessionBindingMismatch)
+	}
+
+	// Reject a session used from a different network when subnet binding is enabled
+	if s.opts.SessionSubnetBinding && !subnetAllows(row.Subnet, client.IP) {
+		span.SetAttributes(attribute.Bool("session.valid", false))
+		span.AddEvent("session.subnet_mismatch")
+		return fmt.Errorf("session %d: %w", row.ID, ErrSessionBindingMismatch)
+	}
+
+	return nil
+}
+
+// checkSessionLifetime rejects a session that has expired or gone idle.
+func (s *AuthService) checkSessionLifetime(ctx context.Context, span trace.Span, row *domain.SessionRow) error {
+	// Check if session has expired
+	if time.Now().After(row.ExpiresAt) {
+		span.SetAttributes(attribute.Bool("session.valid", false))
+		s.deleteExpiredSession(ctx, span, row.ID)
+		return fmt.Errorf("session expired at %v: %w", row.ExpiresAt, ErrSessionExpired)
+	}
+
+	// Check if session has been idle for too long
+	if s.opts.SessionIdleTimeout > 0 && time.Since(row.LastActiveAt) > s.opts.SessionIdleTimeout {
+		span.SetAttributes(attribute.Bool("session.valid", false), attribute.Bool("session.idle", true))
+		s.deleteExpiredSession(ctx, span, row.ID)
+		return fmt.Errorf("session idle since %v: %w", row.LastActiveAt, ErrSessionExpired)
+	}
+
+	return nil
+}
+
+// GetUserByID loads a user by ID for internal lookups (admin, webhooks).
+// Returns ErrUserNotFound when no such user exists.
+func (s *AuthService) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.get_user_by_id", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("user.id", id),
+	))
+	defer span.End()
+
+	row, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("query user %d: %w", id, err)
+	}
+	if row == nil {
+		return nil, fmt.Errorf("lookup user %d: %w", id, ErrUserNotFound)
+	}
+
+	return userFromRow(row), nil
+}
+
+// GetUserByPublicID loads a user by the public UUID used in API paths.
+// Returns ErrUserNotFound when the ID is malformed or no such user exists.
+func (s *AuthService) GetUserByPublicID(ctx context.Context, publicID string) (*domain.User, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.get_user_by_public_id", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", publicID),
+	))
+	defer span.End()
+
+	if !isPublicID(publicID) {
+		return nil, fmt.Errorf("lookup user %q: %w", publicID, ErrUserNotFound)
+	}
+
+	row, err := s.users.GetByPublicID(ctx, publicID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("query user %q: %w", publicID, err)
+	}
+	if row == nil {
+		return nil, fmt.Errorf("lookup user %q: %w", publicID, ErrUserNotFound)
+	}
+
+	return userFromRow(row), nil
+}
+
+// Logout revokes the session behind the given access token.
+// It is idempotent: an unknown or already-expired token is not an error, so
+// clients can safely retry. A token whose signature does not verify is rejected
+// with ErrInvalidToken, since its jti cannot be trusted.
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.logout", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	claims, err := s.tokens.ParseAndValidate(token)
+	if errors.Is(err, ErrSessionExpired) {
+		// Nothing left to revoke
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessions.DeleteByToken(ctx, claims.ID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("delete session for user %s: %w", claims.Subject, err)
+	}
+
+	span.SetAttributes(attribute.String("user.id", claims.Subject))
+	span.AddEvent("session.logout")
+	return nil
+}
+
+// RotateAllUserSessions invalidates every session of the user so all devices must
+// re-authenticate. Unlike a user-initiated logout-all, it is meant for security
+// automation (e.g., suspicious new-device logins, self-lock) and always emits a
+// "sessions_rotated" security event carrying the reason.
+// Returns the number of sessions revoked.
+func (s *AuthService) RotateAllUserSessions(ctx context.Context, userID int, reason string) (int64, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.rotate_all_user_sessions", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("user.id", userID),
+	))
+	defer span.End()
+
+	revoked, err := s.sessions.DeleteByUserID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("rotate sessions for user %d: %w", userID, err)
+	}
+
+	middleware.RecordSecurityEvent(ctx, "sessions_rotated",
+		attribute.Int("user_id", userID),
+		attribute.String("reason", reason),
+		attribute.Int64("sessions_revoked", revoked),
+	)
+
+	return revoked, nil
+}
+
+// ListSessions returns the requester's active sessions, newest first.
+func (s *AuthService) ListSessions(ctx context.Context, requester *domain.User) ([]domain.SessionInfo, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.list_sessions", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	sessions, err := s.sessions.ListByUserID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("list sessions for user %s: %w", requester.ID, err)
+	}
+
+	span.SetAttributes(attribute.Int("session.count", len(sessions)))
+	return sessions, nil
+}
+
+// DeleteSession revokes a single session by public ID on behalf of the requester.
+// The session must belong to the requester unless the requester is an admin;
+// otherwise ErrForbidden is returned so one user cannot revoke another user's
+// sessions (IDOR). Malformed IDs are reported as ErrSessionNotFound.
+func (s *AuthService) DeleteSession(ctx context.Context, requester *domain.User, sessionID string) error {
+	ctx, span := middleware.StartSpan(ctx, "auth.delete_session", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+		attribute.String("session.id", sessionID),
+	))
+	defer span.End()
+
+	if !isPublicID(sessionID) {
+		return fmt.Errorf("lookup session %q: %w", sessionID, ErrSessionNotFound)
+	}
+
+	row, err := s.sessions.GetByPublicID(ctx, sessionID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("query session %s: %w", sessionID, err)
+	}
+	if row == nil {
+		return fmt.Errorf("lookup session %s: %w", sessionID, ErrSessionNotFound)
+	}
+
+	owner := row.UserID == requester.InternalID
+	span.SetAttributes(attribute.Bool("session.owner", owner))
+	if !owner && requester.Role != domain.RoleAdmin {
+		return fmt.Errorf("delete session %s for user %s: %w", sessionID, requester.ID, ErrForbidden)
+	}
+
+	if err := s.sessions.DeleteByID(ctx, row.ID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("delete session %s: %w", sessionID, err)
+	}
+
+	if !owner {
+		middleware.RecordSecurityEvent(ctx, "session_revoked_by_admin",
+			attribute.String("admin.id", requester.ID),
+			attribute.String("user.id", row.UserPublicID),
+			attribute.String("session.id", sessionID),
+		)
+	}
+	span.AddEvent("session.revoked")
+	return nil
+}
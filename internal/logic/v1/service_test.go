@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Benchmarks of the login hot path against in-memory repositories, so the numbers
+// isolate bcrypt and token handling from the database. Compare bcrypt costs with:
+//
+//	go test ./internal/logic/v1 -run '^$' -bench 'Login|GetUserByToken'
+
+const benchPassword = "correct-horse-battery-staple"
+
+func BenchmarkLogin(b *testing.B) {
+	for _, cost := range []int{bcrypt.MinCost, bcrypt.DefaultCost, 12} {
+		b.Run(fmt.Sprintf("bcrypt_cost=%d", cost), func(b *testing.B) {
+			svc, repos := newTestService(b, Options{BcryptCost: cost})
+			repos.Users.AddUser(b, "bench", "bench@example.com", benchPassword, cost)
+			req := domain.LoginRequest{Username: "bench", Password: benchPassword}
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := svc.Login(ctx, req, domain.ClientInfo{}); err != nil {
+					b.Fatalf("login: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetUserByToken(b *testing.B) {
+	svc, repos := newTestService(b, Options{})
+	repos.Users.AddUser(b, "bench", "bench@example.com", benchPassword, bcrypt.MinCost)
+	ctx := context.Background()
+
+	result, err := svc.Login(ctx, domain.LoginRequest{Username: "bench", Password: benchPassword}, domain.ClientInfo{})
+	if err != nil {
+		b.Fatalf("login: %v", err)
+	}
+	resp := result.Session
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := svc.GetUserByToken(ctx, resp.Token, domain.ClientInfo{}); err != nil {
+			b.Fatalf("get user by token: %v", err)
+		}
+	}
+}
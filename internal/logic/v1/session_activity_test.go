@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// lastUsedAt returns the last activity of the requester's only session.
+func lastUsedAt(t *testing.T, svc *AuthService, requester *domain.User) time.Time {
+	t.Helper()
+
+	sessions, err := svc.ListSessions(context.Background(), requester)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("sessions = %d, want 1", len(sessions))
+	}
+	return sessions[0].LastUsedAt
+}
+
+func TestSessionLastUsedThrottled(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	svc, repos := newTestService(t, Options{SessionTouchInterval: interval})
+	repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+	ctx := context.Background()
+
+	result, err := svc.Login(ctx, domain.LoginRequest{Username: "alice", Password: "correct-horse-battery"}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	use := func() *domain.User {
+		t.Helper()
+		user, err := svc.GetUserByToken(ctx, result.Session.Token, domain.ClientInfo{})
+		if err != nil {
+			t.Fatalf("GetUserByToken: %v", err)
+		}
+		return user
+	}
+
+	requester := use()
+	first := lastUsedAt(t, svc, requester)
+
+	// Requests within the interval do not write again
+	for range 3 {
+		time.Sleep(time.Millisecond)
+		use()
+	}
+	if got := lastUsedAt(t, svc, requester); !got.Equal(first) {
+		t.Errorf("last_used_at moved within the throttle window: %s, want %s", got, first)
+	}
+
+	time.Sleep(interval)
+	use()
+	if got := lastUsedAt(t, svc, requester); !got.After(first) {
+		t.Errorf("last_used_at after the throttle window = %s, want after %s", got, first)
+	}
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	const idle = 60 * time.Millisecond
+	svc, repos := newTestService(t, Options{SessionIdleTimeout: idle})
+	repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+	ctx := context.Background()
+
+	login := func() string {
+		t.Helper()
+		result, err := svc.Login(ctx, domain.LoginRequest{Username: "alice", Password: "correct-horse-battery"}, domain.ClientInfo{})
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		return result.Session.Token
+	}
+	active, abandoned := login(), login()
+
+	// The active session is used well within the timeout, for longer than the timeout overall
+	for range 4 {
+		time.Sleep(idle / 3)
+		if _, err := svc.GetUserByToken(ctx, active, domain.ClientInfo{}); err != nil {
+			t.Fatalf("recently used session: %v", err)
+		}
+	}
+
+	if _, err := svc.GetUserByToken(ctx, abandoned, domain.ClientInfo{}); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("session idle beyond the timeout: error = %v, want %v", err, ErrSessionExpired)
+	}
+}
+
+func TestLastLoginThrottled(t *testing.T) {
+	const interval = 200 * time.Millisecond
+
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		wantWrites int // writes after the burst of logins
+	}{
+		{"throttled", interval, 1},
+		{"disabled writes every login", 0, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, Options{LastLoginInterval: tt.interval})
+			repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+			login := func() {
+				t.Helper()
+				if _, err := svc.Login(context.Background(),
+					domain.LoginRequest{Username: "alice", Password: "correct-horse-battery"}, domain.ClientInfo{}); err != nil {
+					t.Fatalf("login: %v", err)
+				}
+			}
+
+			for range 5 {
+				login()
+			}
+			if got := repos.Users.LastLoginWrites(); got != tt.wantWrites {
+				t.Fatalf("last_login writes after 5 rapid logins = %d, want %d", got, tt.wantWrites)
+			}
+
+			// Once the window has passed the next login is recorded again
+			time.Sleep(interval)
+			login()
+			if got := repos.Users.LastLoginWrites(); got != tt.wantWrites+1 {
+				t.Errorf("last_login writes after the window = %d, want %d", got, tt.wantWrites+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/middleware"
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// sessionsPurged counts expired sessions deleted by the cleanup job.
+	sessionsPurged = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sessions_purged_total",
+		Help: "Number of expired sessions deleted by the session cleanup job",
+	})
+
+	// refreshTokensPurged counts expired refresh tokens deleted by the cleanup job.
+	refreshTokensPurged = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "refresh_tokens_purged_total",
+		Help: "Number of expired refresh tokens deleted by the session cleanup job",
+	})
+)
+
+// PurgeExpiredSessions deletes every session past its expiry and returns how many
+// were deleted. Expired sessions are already rejected on use; this only reclaims space.
+func (s *AuthService) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.purge_expired_sessions", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	deleted, err := s.sessions.DeleteExpired(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("delete expired sessions: %w", err)
+	}
+
+	sessionsPurged.Add(float64(deleted))
+	span.SetAttributes(attribute.Int64("session.purged", deleted))
+	return deleted, nil
+}
+
+// PurgeExpiredRefreshTokens deletes every refresh token past its expiry and
+// returns how many were deleted. Rotation leaves one used row per refresh; they
+// are kept until they expire so a replay is still caught as reuse. Tokens of
+// revoked sessions and families are already gone with their sessions.
+func (s *AuthService) PurgeExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.purge_expired_refresh_tokens", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	deleted, err := s.refreshTokens.DeleteExpired(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("delete expired refresh tokens: %w", err)
+	}
+
+	refreshTokensPurged.Add(float64(deleted))
+	span.SetAttributes(attribute.Int64("refresh_token.purged", deleted))
+	return deleted, nil
+}
+
+// RunSessionCleanup purges expired sessions and refresh tokens once at start and
+// then every interval, until ctx is canceled. Failures are logged and retried on
+// the next tick. Every replica may run it: the DELETEs are idempotent.
+func (s *AuthService) RunSessionCleanup(ctx context.Context, interval time.Duration) {
+	logger := pkgzerolog.FromContext(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := s.PurgeExpiredSessions(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.Error().Err(err).Msg("Session cleanup failed")
+		case deleted > 0:
+			logger.Info().Int64("deleted", deleted).Msg("Expired sessions purged")
+		}
+
+		deleted, err = s.PurgeExpiredRefreshTokens(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.Error().Err(err).Msg("Refresh token cleanup failed")
+		case deleted > 0:
+			logger.Info().Int64("deleted", deleted).Msg("Expired refresh tokens purged")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteExpiredSession deletes a session found expired or idle on access, when
+// Options.SessionLazyCleanup is set. Best-effort: the caller rejects the token
+// either way and the cleanup job catches anything left behind.
+func (s *AuthService) deleteExpiredSession(ctx context.Context, span trace.Span, sessionID int) {
+	if !s.opts.SessionLazyCleanup {
+		return
+	}
+	if err := s.sessions.DeleteByID(ctx, sessionID); err != nil {
+		span.RecordError(fmt.Errorf("delete expired session: %w", err))
+		return
+	}
+	span.AddEvent("session.lazily_deleted")
+}
+
+// deleteExpiredTokenSession is deleteExpiredSession for a token that failed its
+// exp check, whose session is found by jti. Only tokens with a valid signature
+// are acted on, so a forged token cannot delete someone else's session.
+// With refresh tokens the session outlives its access tokens, so it is left alone.
+func (s *AuthService) deleteExpiredTokenSession(ctx context.Context, span trace.Span, token string) {
+	if !s.opts.SessionLazyCleanup || s.opts.RefreshTokenTTL > 0 {
+		return
+	}
+	claims, err := s.tokens.parseVerified(token)
+	if err != nil {
+		return
+	}
+	if err := s.sessions.DeleteByToken(ctx, claims.ID); err != nil {
+		span.RecordError(fmt.Errorf("delete expired session: %w", err))
+		return
+	}
+	span.AddEvent("session.lazily_deleted")
+}
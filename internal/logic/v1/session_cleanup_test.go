@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPurgeExpiredRefreshTokens(t *testing.T) {
+	svc, repos := newRefreshTestService(t)
+	ctx := context.Background()
+
+	// Each rotation leaves the presented token behind, used.
+	expiredUsed := loginAlice(t, svc)
+	liveUsed, err := svc.Refresh(ctx, expiredUsed.RefreshToken, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	liveUnused, err := svc.Refresh(ctx, liveUsed.RefreshToken, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	expiredUnused := loginAlice(t, svc)
+
+	repos.RefreshTokens.Expire(hashOpaqueToken(expiredUsed.RefreshToken))
+	repos.RefreshTokens.Expire(hashOpaqueToken(expiredUnused.RefreshToken))
+
+	deleted, err := svc.PurgeExpiredRefreshTokens(ctx)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted = %d, want 2", deleted)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		kept  bool
+	}{
+		{"expired used", expiredUsed.RefreshToken, false},
+		{"expired unused", expiredUnused.RefreshToken, false},
+		{"live used", liveUsed.RefreshToken, true},
+		{"live unused", liveUnused.RefreshToken, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := repos.RefreshTokens.GetByHash(ctx, hashOpaqueToken(tt.token))
+			if err != nil {
+				t.Fatalf("get by hash: %v", err)
+			}
+			if kept := row != nil; kept != tt.kept {
+				t.Errorf("kept = %v, want %v", kept, tt.kept)
+			}
+		})
+	}
+
+	// A kept used token is still caught as reuse and revokes its family.
+	if _, err := svc.Refresh(ctx, liveUsed.RefreshToken, domain.ClientInfo{}); err == nil {
+		t.Error("replayed used token after purge: refresh succeeded")
+	}
+	if row, _ := repos.RefreshTokens.GetByHash(ctx, hashOpaqueToken(liveUnused.RefreshToken)); row != nil {
+		t.Error("latest token survived the reuse of a purge-kept used token")
+	}
+}
+
+func TestPurgeExpiredRefreshTokensNothingExpired(t *testing.T) {
+	svc, _ := newRefreshTestService(t)
+	loginAlice(t, svc)
+
+	deleted, err := svc.PurgeExpiredRefreshTokens(context.Background())
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("deleted = %d, want 0", deleted)
+	}
+}
+
+func TestLazySessionCleanup(t *testing.T) {
+	// Each case returns a rejected token of alice's only session
+	cases := map[string]func(t *testing.T, svc *AuthService, repos *memory.Repos) string{
+		"session past expires_at": func(t *testing.T, svc *AuthService, repos *memory.Repos) string {
+			result, err := svc.Login(context.Background(),
+				domain.LoginRequest{Username: "alice", Password: "correct-horse-battery"}, domain.ClientInfo{})
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			repos.Sessions.Expire(result.Session.SessionID)
+			return result.Session.Token
+		},
+		"token past exp": func(t *testing.T, svc *AuthService, repos *memory.Repos) string {
+			alice, _ := repos.Users.GetByUsername(context.Background(), "alice")
+			token, claims, err := svc.tokens.IssueWithTTL(alice.PublicID, string(alice.Role), -time.Minute)
+			if err != nil {
+				t.Fatalf("issue: %v", err)
+			}
+			if _, err := repos.Sessions.Create(context.Background(), domain.NewSession{
+				UserID: alice.ID, Token: claims.ID, ExpiresAt: time.Now().Add(time.Hour),
+			}); err != nil {
+				t.Fatalf("create session: %v", err)
+			}
+			return token
+		},
+	}
+
+	for name, expired := range cases {
+		for _, lazy := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s lazy=%v", name, lazy), func(t *testing.T) {
+				svc, repos := newTestService(t, Options{SessionLazyCleanup: lazy})
+				repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+				token := expired(t, svc, repos)
+
+				if _, err := svc.GetUserByToken(context.Background(), token, domain.ClientInfo{}); !errors.Is(err, ErrSessionExpired) {
+					t.Fatalf("GetUserByToken: error = %v, want %v", err, ErrSessionExpired)
+				}
+				want := 1
+				if lazy {
+					want = 0
+				}
+				if got := repos.Sessions.Count(); got != want {
+					t.Errorf("sessions left = %d, want %d", got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package v1
+
+import "time"
+
+// sessionTTL returns the lifetime of a new session. A requested lifetime of zero
+// uses the issuer's default TTL; any other value is clamped to
+// [SessionTTLMin, SessionTTLMax] so a client cannot ask for a 10-year session
+// (or one that expires before it can be used). Unset bounds are not enforced.
+func (s *AuthService) sessionTTL(requested time.Duration) time.Duration {
+	if requested <= 0 {
+		return s.tokens.ttl
+	}
+	if s.opts.SessionTTLMin > 0 && requested < s.opts.SessionTTLMin {
+		return s.opts.SessionTTLMin
+	}
+	if s.opts.SessionTTLMax > 0 && requested > s.opts.SessionTTLMax {
+		return s.opts.SessionTTLMax
+	}
+	return requested
+}
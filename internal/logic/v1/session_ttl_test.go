@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSessionTTLClamped(t *testing.T) {
+	bounded := Options{SessionTTLMin: 15 * time.Minute, SessionTTLMax: 30 * 24 * time.Hour}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		expiresIn time.Duration // requested at login
+		want      time.Duration
+	}{
+		{"omitted uses the default TTL", bounded, 0, time.Hour},
+		{"within bounds is kept", bounded, 7 * 24 * time.Hour, 7 * 24 * time.Hour},
+		{"too short is raised to the minimum", bounded, time.Minute, 15 * time.Minute},
+		{"ten years is capped at the maximum", bounded, 10 * 365 * 24 * time.Hour, 30 * 24 * time.Hour},
+		{"unset bounds are not enforced", Options{}, 10 * 365 * 24 * time.Hour, 10 * 365 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, tt.opts)
+			repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+
+			before := time.Now()
+			result, err := svc.Login(context.Background(), domain.LoginRequest{
+				Username:  "alice",
+				Password:  "correct-horse-battery",
+				ExpiresIn: int(tt.expiresIn / time.Second),
+			}, domain.ClientInfo{})
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+
+			// Allow for the time the login itself took
+			got := result.Session.ExpiresAt.Sub(before)
+			if got < tt.want-time.Second || got > tt.want+5*time.Second {
+				t.Errorf("session lifetime = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
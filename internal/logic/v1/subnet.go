@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"net/netip"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// Prefix lengths used for subnet binding: a /24 tolerates DHCP churn inside an
+// IPv4 network, a /64 is the smallest prefix normally assigned to an IPv6 site.
+const (
+	subnetBitsIPv4 = 24
+	subnetBitsIPv6 = 64
+)
+
+// clientSubnet returns the /24 (IPv4) or /64 (IPv6) network containing ip in
+// CIDR form, or "" when ip cannot be parsed.
+// IPv4-mapped IPv6 addresses are treated as IPv4.
+func clientSubnet(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	bits := subnetBitsIPv6
+	if addr.Is4() {
+		bits = subnetBitsIPv4
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
+
+// subnetAllows reports whether a session issued from subnet may be used from ip.
+// Sessions without a recorded subnet are never rejected, so enabling subnet
+// binding does not log out existing users.
+func subnetAllows(subnet, ip string) bool {
+	if subnet == "" {
+		return true
+	}
+	return clientSubnet(ip) == subnet
+}
+
+// sessionSubnet returns the subnet recorded on a new session for this client.
+// The subnet is only recorded while subnet binding is enabled.
+func (s *AuthService) sessionSubnet(client domain.ClientInfo) string {
+	if !s.opts.SessionSubnetBinding {
+		return ""
+	}
+	return clientSubnet(client.IP)
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestClientSubnet(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"192.0.2.10", "192.0.2.0/24"},
+		{"::ffff:192.0.2.10", "192.0.2.0/24"},
+		{"2001:db8:1:2:3:4:5:6", "2001:db8:1:2::/64"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := clientSubnet(tt.ip); got != tt.want {
+			t.Errorf("clientSubnet(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestSessionSubnetBinding(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		issuedTo string // client IP at login
+		usedFrom string // client IP presented later
+		wantErr  error
+	}{
+		{"same IPv4 subnet is accepted", true, "192.0.2.10", "192.0.2.99", nil},
+		{"other IPv4 subnet is rejected", true, "192.0.2.10", "198.51.100.7", ErrSessionBindingMismatch},
+		{"same IPv6 /64 is accepted", true, "2001:db8:1:2::10", "2001:db8:1:2:ffff::1", nil},
+		{"other IPv6 /64 is rejected", true, "2001:db8:1:2::10", "2001:db8:1:3::10", ErrSessionBindingMismatch},
+		{"unparsable IP is rejected", true, "192.0.2.10", "", ErrSessionBindingMismatch},
+		{"session without a subnet is accepted", true, "not-an-ip", "198.51.100.7", nil},
+		{"disabled ignores the subnet", false, "192.0.2.10", "198.51.100.7", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repos := newTestService(t, Options{SessionSubnetBinding: tt.enabled})
+			repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+			ctx := context.Background()
+
+			result, err := svc.Login(ctx, domain.LoginRequest{Username: "alice", Password: "correct-horse-battery"},
+				domain.ClientInfo{IP: tt.issuedTo})
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+
+			_, err = svc.GetUserByToken(ctx, result.Session.Token, domain.ClientInfo{IP: tt.usedFrom})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("GetUserByToken: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUserByToken: error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
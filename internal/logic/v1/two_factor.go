@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultTOTPIssuer labels the account in authenticator apps when Options.TOTPIssuer is not set.
+const defaultTOTPIssuer = "auth-service"
+
+// EnrollTOTP generates a new TOTP secret for the requester and stores it, encrypted,
+// as an unconfirmed enrollment. 2FA stays inactive until ConfirmTOTP succeeds.
+// Calling it again before confirming replaces the pending secret.
+// Returns ErrTwoFactorAlreadyEnabled if the requester already has active 2FA.
+func (s *AuthService) EnrollTOTP(ctx context.Context, requester *domain.User) (*domain.TOTPEnrollment, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.enroll_totp", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	sc, err := s.totpCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	sealed, err := sc.seal(requester.InternalID, secret)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("encrypt totp secret: %w", err)
+	}
+
+	stored, err := s.totp.Upsert(ctx, requester.InternalID, sealed)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("store totp secret for user %s: %w", requester.ID, err)
+	}
+	if !stored {
+		return nil, fmt.Errorf("enroll totp for user %s: %w", requester.ID, ErrTwoFactorAlreadyEnabled)
+	}
+
+	issuer := s.opts.TOTPIssuer
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+
+	span.AddEvent("totp.enrollment_started")
+	return &domain.TOTPEnrollment{
+		Secret:     totpEncoding.EncodeToString(secret),
+		OTPAuthURI: totpURI(issuer, requester.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP activates the requester's pending enrollment once they prove
+// their authenticator produces valid codes for the stored secret, and returns
+// a fresh set of backup codes, shown to the user only this once.
+// Returns ErrTwoFactorNotEnrolled without a pending enrollment,
+// ErrTwoFactorAlreadyEnabled if already active, and ErrInvalidTOTPCode for a wrong code.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, requester *domain.User, code string) ([]string, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.confirm_totp", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+	))
+	defer span.End()
+
+	sc, err := s.totpCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.totp.GetByUserID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("get totp enrollment for user %s: %w", requester.ID, err)
+	}
+	if row == nil {
+		return nil, fmt.Errorf("confirm totp for user %s: %w", requester.ID, ErrTwoFactorNotEnrolled)
+	}
+	if row.ConfirmedAt != nil {
+		return nil, fmt.Errorf("confirm totp for user %s: %w", requester.ID, ErrTwoFactorAlreadyEnabled)
+	}
+
+	secret, err := sc.open(row.UserID, row.SecretEncrypted)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	step, ok := validateTOTP(secret, code, time.Now(), row.LastUsedStep)
+	if !ok {
+		span.SetAttributes(attribute.Bool("totp.valid", false))
+		return nil, fmt.Errorf("confirm totp for user %s: %w", requester.ID, ErrInvalidTOTPCode)
+	}
+
+	if err := s.totp.Confirm(ctx, row.UserID, step); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("confirm totp for user %s: %w", requester.ID, err)
+	}
+
+	middleware.RecordSecurityEvent(ctx, "two_factor_enabled", attribute.String("user.id", requester.ID))
+
+	// 2FA is already active, so a failure here must not be reported as a failed
+	// confirmation; the user can regenerate the codes later.
+	codes, err := s.issueBackupCodes(ctx, row.UserID)
+	if err != nil {
+		span.RecordError(err)
+		return []string{}, nil
+	}
+	return codes, nil
+}
+
+// checkLoginTOTP requires a valid TOTP code, or an unused backup code, from
+// users with confirmed 2FA. Returns StepTOTP when neither was sent and
+// ErrInvalidTOTPCode for a wrong or already used code; wrong codes count toward
+// lockout like bad passwords.
+func (s *AuthService) checkLoginTOTP(ctx context.Context, a *loginAttempt) (NextStep, error) {
+	enrollment, err := s.totp.GetByUserID(ctx, a.row.ID)
+	if err != nil {
+		a.span.RecordError(err)
+		return StepNone, fmt.Errorf("get totp enrollment: %w", err)
+	}
+	if enrollment == nil || enrollment.ConfirmedAt == nil {
+		return StepNone, nil
+	}
+
+	if a.req.TOTPCode == "" && a.req.BackupCode == "" {
+		a.span.AddEvent("authentication.two_factor_required")
+		return StepTOTP, nil
+	}
+	if a.req.TOTPCode == "" {
+		return StepNone, s.checkLoginBackupCode(ctx, a)
+	}
+	return StepNone, s.checkLoginTOTPCode(ctx, a, enrollment)
+}
+
+// checkLoginTOTPCode verifies the TOTP code of a login against enrollment and
+// claims its time step.
+func (s *AuthService) checkLoginTOTPCode(ctx context.Context, a *loginAttempt, enrollment *domain.TOTPRow) error {
+
+	sc, err := s.totpCipher()
+	if err != nil {
+		return err
+	}
+	secret, err := sc.open(enrollment.UserID, enrollment.SecretEncrypted)
+	if err != nil {
+		a.span.RecordError(err)
+		return err
+	}
+
+	step, ok := validateTOTP(secret, a.req.TOTPCode, time.Now(), enrollment.LastUsedStep)
+	if ok {
+		// Claim the step atomically so two concurrent logins cannot share one code
+		ok, err = s.totp.UseStep(ctx, enrollment.UserID, step)
+		if err != nil {
+			a.span.RecordError(err)
+			return fmt.Errorf("record totp step: %w", err)
+		}
+	}
+	if !ok {
+		a.span.AddEvent("authentication.two_factor_failed")
+		if s.recordFailedLogin(ctx, a.span, a.row.ID) {
+			return ErrAccountLocked
+		}
+		return ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+// checkLoginBackupCode accepts an unused backup code in place of a TOTP code
+// and consumes it.
+func (s *AuthService) checkLoginBackupCode(ctx context.Context, a *loginAttempt) error {
+	ok, err := s.useBackupCode(ctx, a.row.ID, a.req.BackupCode)
+	if err != nil {
+		a.span.RecordError(err)
+		return err
+	}
+	if !ok {
+		a.span.AddEvent("authentication.two_factor_failed")
+		if s.recordFailedLogin(ctx, a.span, a.row.ID) {
+			return ErrAccountLocked
+		}
+		return ErrInvalidTOTPCode
+	}
+
+	middleware.RecordSecurityEvent(ctx, "backup_code_used", attribute.String("user.id", a.row.PublicID))
+	return nil
+}
+
+// totpCipher returns the cipher for TOTP secrets, or ErrTwoFactorUnavailable
+// when no encryption key is configured.
+func (s *AuthService) totpCipher() (*secretCipher, error) {
+	if len(s.opts.TOTPEncryptionKey) == 0 {
+		return nil, fmt.Errorf("totp encryption key not configured: %w", ErrTwoFactorUnavailable)
+	}
+	return newSecretCipher(s.opts.TOTPEncryptionKey)
+}
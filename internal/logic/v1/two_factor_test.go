@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const twoFactorTestPassword = "correct-horse-battery-staple"
+
+// enrollTwoFactor confirms 2FA for a new user, alice, and returns her TOTP
+// secret and the code that confirmed it.
+func enrollTwoFactor(t *testing.T, svc *AuthService, repos *memory.Repos) ([]byte, string) {
+	t.Helper()
+
+	ctx := context.Background()
+	row := repos.Users.AddUser(t, "alice", "alice@example.com", twoFactorTestPassword, bcrypt.MinCost)
+	user := userFromRow(row)
+
+	enrollment, err := svc.EnrollTOTP(ctx, user)
+	if err != nil {
+		t.Fatalf("enroll: %v", err)
+	}
+	secret, err := totpEncoding.DecodeString(enrollment.Secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	code := totpCode(secret, totpStep(time.Now()))
+	if _, err := svc.ConfirmTOTP(ctx, user, code); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	return secret, code
+}
+
+func loginWithTOTP(svc *AuthService, code string) error {
+	_, err := svc.Login(context.Background(), domain.LoginRequest{
+		Username: "alice",
+		Password: twoFactorTestPassword,
+		TOTPCode: code,
+	}, domain.ClientInfo{})
+	return err
+}
+
+func TestLoginTOTPStepCannotBeReplayed(t *testing.T) {
+	svc, repos := newTestService(t, Options{
+		TOTPEncryptionKey: bytes.Repeat([]byte{7}, 32),
+		LockoutThreshold:  5,
+		LockoutDuration:   time.Minute,
+	})
+	secret, confirmCode := enrollTwoFactor(t, svc, repos)
+
+	// The code used to confirm enrollment is already spent
+	if err := loginWithTOTP(svc, confirmCode); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Fatalf("login with the confirmation code: error = %v, want %v", err, ErrInvalidTOTPCode)
+	}
+
+	// The next step is inside the skew window and unused
+	next := totpCode(secret, totpStep(time.Now())+1)
+	if err := loginWithTOTP(svc, next); err != nil {
+		t.Fatalf("login with a fresh code: %v", err)
+	}
+	if err := loginWithTOTP(svc, next); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Fatalf("replayed code: error = %v, want %v", err, ErrInvalidTOTPCode)
+	}
+
+	// A replay counts toward lockout like a wrong code
+	row, _ := repos.Users.GetByUsername(context.Background(), "alice")
+	if row.FailedLoginAttempts != 1 {
+		t.Errorf("failed login attempts = %d, want 1", row.FailedLoginAttempts)
+	}
+}
+
+func TestValidateTOTPRejectsUsedSteps(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 20)
+	now := time.Unix(1_700_000_000, 0)
+	current := totpStep(now)
+	code := totpCode(secret, current)
+
+	if step, ok := validateTOTP(secret, code, now, current-1); !ok || step != current {
+		t.Fatalf("validateTOTP = (%d, %v), want (%d, true)", step, ok, current)
+	}
+	if _, ok := validateTOTP(secret, code, now, current); ok {
+		t.Error("validateTOTP accepted a step at notAfter")
+	}
+	if _, ok := validateTOTP(secret, totpCode(secret, current-1), now, current); ok {
+		t.Error("validateTOTP accepted a step before notAfter")
+	}
+}
+
+func TestLoginWithoutCodeAsksForTOTP(t *testing.T) {
+	svc, repos := newTestService(t, Options{TOTPEncryptionKey: bytes.Repeat([]byte{7}, 32)})
+	enrollTwoFactor(t, svc, repos)
+
+	result, err := svc.Login(context.Background(), domain.LoginRequest{
+		Username: "alice",
+		Password: twoFactorTestPassword,
+	}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if result.Next != StepTOTP || result.Session != nil {
+		t.Errorf("result = %+v, want StepTOTP without a session", result)
+	}
+	if n := repos.Sessions.Count(); n != 0 {
+		t.Errorf("sessions = %d, want 0", n)
+	}
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UpdateProfile changes the requester's username and/or email after re-checking
+// their password, applying the same rules as ChangeUsername and RequestEmailChange:
+// a new username is renamed immediately, while a new email only becomes pending
+// until the link sent to it is followed. Both fields are checked for conflicts
+// before either is applied, so a rejected email never leaves a half-done rename.
+// Returns ErrInvalidCredentials for a wrong password, ErrInvalidUsername,
+// ErrUsernameReserved, ErrUsernameExists, ErrEmailExists, or a *RetryAfterError
+// when renamed too recently.
+func (s *AuthService) UpdateProfile(
+	ctx context.Context, requester *domain.User, req domain.UpdateProfileRequest,
+) (*domain.UpdateProfileResponse, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.update_profile", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", requester.ID),
+		attribute.Bool("profile.username", req.Username != ""),
+		attribute.Bool("profile.email", req.Email != ""),
+	))
+	defer span.End()
+
+	req.Username = normalizeUsername(req.Username)
+	req.Email = normalizeEmail(req.Email)
+	if req.Username != "" {
+		if err := validateUsername(req.Username); err != nil {
+			return nil, fmt.Errorf("update profile of user %s: %w", requester.ID, err)
+		}
+	}
+
+	row, err := s.users.GetByID(ctx, requester.InternalID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("query user %s: %w", requester.ID, err)
+	}
+	if row == nil {
+		return nil, fmt.Errorf("lookup user %s: %w", requester.ID, ErrUserNotFound)
+	}
+
+	if err := s.verifyStepUp(ctx, span, row, req.CurrentPassword); err != nil {
+		return nil, fmt.Errorf("update profile of user %s: %w", requester.ID, err)
+	}
+
+	if req.Email != "" && !strings.EqualFold(req.Email, row.Email) {
+		exists, err := s.users.ExistsByEmail(ctx, req.Email)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("check email: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("update profile of user %s: %w", requester.ID, ErrEmailExists)
+		}
+	}
+
+	if req.Username != "" {
+		if err := s.renameUser(ctx, row, req.Username); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("update profile of user %s: %w", requester.ID, err)
+		}
+		row.Username = req.Username
+	}
+
+	var pending bool
+	if req.Email != "" {
+		if pending, err = s.startEmailChange(ctx, row, req.Email); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("update profile of user %s: %w", requester.ID, err)
+		}
+	}
+
+	return &domain.UpdateProfileResponse{User: *userFromRow(row), EmailChangePending: pending}, nil
+}
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestGetUserByID(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	alice := repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+	deleted := repos.Users.AddUser(t, "bob", "bob@example.com", "correct-horse-battery", bcrypt.MinCost)
+	ctx := context.Background()
+	if err := repos.Users.SoftDelete(ctx, deleted.ID); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+
+	user, err := svc.GetUserByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("existing user: %v", err)
+	}
+	if user.ID != alice.PublicID || user.InternalID != alice.ID || user.Username != "alice" {
+		t.Errorf("user = %+v, want alice", user)
+	}
+
+	for name, id := range map[string]int{"unknown": alice.ID + 100, "deleted": deleted.ID} {
+		if _, err := svc.GetUserByID(ctx, id); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("%s user: error = %v, want %v", name, err, ErrUserNotFound)
+		}
+	}
+}
+
+func TestGetUserByPublicID(t *testing.T) {
+	svc, repos := newTestService(t, Options{})
+	alice := repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+	ctx := context.Background()
+
+	user, err := svc.GetUserByPublicID(ctx, alice.PublicID)
+	if err != nil {
+		t.Fatalf("existing user: %v", err)
+	}
+	if user.ID != alice.PublicID || user.InternalID != alice.ID {
+		t.Errorf("user = %+v, want alice", user)
+	}
+
+	for name, id := range map[string]string{
+		"serial ID":    strconv.Itoa(alice.ID),
+		"unknown UUID": uuid.NewString(),
+		"malformed":    "not-a-uuid",
+	} {
+		if _, err := svc.GetUserByPublicID(ctx, id); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("%s: error = %v, want %v", name, err, ErrUserNotFound)
+		}
+	}
+}
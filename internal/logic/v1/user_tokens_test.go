@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRepeatedRequestsLeaveOnlyLatestToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("password reset", func(t *testing.T) {
+		svc, repos := newTestService(t, Options{})
+		repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+
+		svc.deliverPasswordReset(ctx, "alice@example.com")
+		svc.deliverPasswordReset(ctx, "alice@example.com")
+		sent := repos.Notifier.Messages("password_reset")
+		if len(sent) != 2 {
+			t.Fatalf("reset emails = %d, want 2", len(sent))
+		}
+
+		if err := svc.ResetPassword(ctx, sent[0].Token, "a-new-correct-horse"); !errors.Is(err, ErrInvalidResetToken) {
+			t.Errorf("superseded token: error = %v, want %v", err, ErrInvalidResetToken)
+		}
+		if err := svc.ResetPassword(ctx, sent[1].Token, "a-new-correct-horse"); err != nil {
+			t.Errorf("latest token: %v", err)
+		}
+	})
+
+	t.Run("email verification", func(t *testing.T) {
+		svc, repos := newTestService(t, Options{})
+		row := repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+
+		svc.deliverEmailVerification(ctx, row)
+		svc.deliverEmailVerification(ctx, row)
+		sent := repos.Notifier.Messages("email_verification")
+		if len(sent) != 2 {
+			t.Fatalf("verification emails = %d, want 2", len(sent))
+		}
+
+		if err := svc.VerifyEmail(ctx, sent[0].Token); !errors.Is(err, ErrInvalidVerificationToken) {
+			t.Errorf("superseded token: error = %v, want %v", err, ErrInvalidVerificationToken)
+		}
+		if err := svc.VerifyEmail(ctx, sent[1].Token); err != nil {
+			t.Errorf("latest token: %v", err)
+		}
+	})
+}
+
+func TestReplaceTokenKeepsOtherPurposesAndUsers(t *testing.T) {
+	ctx := context.Background()
+	svc, repos := newTestService(t, Options{})
+	alice := repos.Users.AddUser(t, "alice", "alice@example.com", "correct-horse-battery", bcrypt.MinCost)
+	repos.Users.AddUser(t, "bob", "bob@example.com", "correct-horse-battery", bcrypt.MinCost)
+
+	svc.deliverPasswordReset(ctx, "alice@example.com")
+	svc.deliverPasswordReset(ctx, "bob@example.com")
+	svc.deliverEmailVerification(ctx, alice)
+
+	resets := repos.Notifier.Messages("password_reset")
+	if len(resets) != 2 {
+		t.Fatalf("reset emails = %d, want 2", len(resets))
+	}
+	for _, m := range resets {
+		if err := svc.ResetPassword(ctx, m.Token, "a-new-correct-horse"); err != nil {
+			t.Errorf("reset token of %s: %v", m.To, err)
+		}
+	}
+	verification := repos.Notifier.WaitFor(t, "email_verification", "alice@example.com")
+	if err := svc.VerifyEmail(ctx, verification.Token); err != nil {
+		t.Errorf("verification token: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package v2 provides authentication business logic for API version 2.
+//
+// v2 changes response shapes only. Every business rule (password checks,
+// lockout, backoff, 2FA, session binding) stays in logic/v1, and v2 delegates
+// to the same *v1.AuthService. Both versions therefore share one set of
+// repositories and can never disagree about an account's state. When v2 needs
+// behavior that v1 lacks, add it to v1 (unexported if v1 must not expose it)
+// and shape it here.
+//
+// Errors are the v1 sentinels, re-exported below so the Web layer only imports
+// this package.
+package v2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	"github.com/duynhne/auth-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sentinel errors returned by v2 (aliases of the v1 sentinels).
+var (
+	ErrInvalidCredentials     = logicv1.ErrInvalidCredentials
+	ErrUserNotFound           = logicv1.ErrUserNotFound
+	ErrPasswordExpired        = logicv1.ErrPasswordExpired
+	ErrAccountLocked          = logicv1.ErrAccountLocked
+	ErrSessionNotFound        = logicv1.ErrSessionNotFound
+	ErrSessionExpired         = logicv1.ErrSessionExpired
+	ErrInvalidToken           = logicv1.ErrInvalidToken
+	ErrSessionBindingMismatch = logicv1.ErrSessionBindingMismatch
+	ErrInvalidTOTPCode        = logicv1.ErrInvalidTOTPCode
+	ErrTwoFactorUnavailable   = logicv1.ErrTwoFactorUnavailable
+	ErrTooManyRequests        = logicv1.ErrTooManyRequests
+	ErrServiceUnavailable     = logicv1.ErrServiceUnavailable
+)
+
+// RetryAfterError is the v1 throttling error, carrying the wait time.
+type RetryAfterError = logicv1.RetryAfterError
+
+// NextStep is the v1 step a login must complete before a session is issued.
+type NextStep = logicv1.NextStep
+
+// Login steps (aliases of the v1 steps).
+const (
+	StepNone = logicv1.StepNone
+	StepTOTP = logicv1.StepTOTP
+)
+
+// LoginResult is the outcome of a login that was not rejected: Session when
+// Next is StepNone, otherwise only the step to complete first.
+type LoginResult struct {
+	Next    NextStep
+	Session *LoginResponse
+}
+
+// AuthService implements the v2 API on top of the v1 business logic.
+type AuthService struct {
+	v1     *logicv1.AuthService
+	tokens *logicv1.TokenIssuer
+}
+
+// NewAuthService creates a v2 AuthService delegating to v1. tokens must be the
+// issuer v1 signs with; v2 reads token metadata from it.
+func NewAuthService(v1 *logicv1.AuthService, tokens *logicv1.TokenIssuer) *AuthService {
+	return &AuthService{v1: v1, tokens: tokens}
+}
+
+// Login authenticates the user through v1 and describes the issued token.
+func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest, client domain.ClientInfo) (*LoginResult, error) {
+	ctx, span := middleware.StartSpan(ctx, "auth.v2.login", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	result, err := s.v1.Login(ctx, req, client)
+	if err != nil {
+		return nil, err
+	}
+	if result.Next != StepNone {
+		return &LoginResult{Next: result.Next}, nil
+	}
+	resp := result.Session
+
+	claims, err := s.tokens.ParseAndValidate(resp.Token)
+	if err != nil {
+		// The token was signed a moment ago by the same issuer
+		span.RecordError(err)
+		return nil, fmt.Errorf("read issued token: %w", err)
+	}
+
+	return &LoginResult{Next: StepNone, Session: &LoginResponse{
+		AccessToken: AccessToken{
+			Token:     resp.Token,
+			TokenType: resp.TokenType,
+			IssuedAt:  time.Unix(claims.IssuedAt, 0).UTC(),
+			ExpiresAt: resp.ExpiresAt,
+		},
+		RefreshToken: resp.RefreshToken,
+		SessionID:    resp.SessionID,
+		User:         resp.User,
+	}}, nil
+}
+
+// GetUserByToken returns the user owning the access token (see v1.GetUserByToken).
+func (s *AuthService) GetUserByToken(ctx context.Context, token string, client domain.ClientInfo) (*domain.User, error) {
+	return s.v1.GetUserByToken(ctx, token, client)
+}
@@ -0,0 +1,26 @@
+package v2
+
+import (
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+)
+
+// AccessToken describes an issued access token so clients can schedule
+// re-authentication without decoding the JWT.
+type AccessToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"` // always "Bearer"
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoginResponse is returned by v2 login. AccessToken.Token and RefreshToken are
+// the only secrets it carries.
+type LoginResponse struct {
+	AccessToken AccessToken `json:"access_token"`
+	// RefreshToken is redeemed at /auth/v1/public/refresh; empty when refresh tokens are disabled
+	RefreshToken string      `json:"refresh_token,omitempty"` // nolint:gosec // G117: This is a refresh token field
+	SessionID    string      `json:"session_id"`
+	User         domain.User `json:"user"`
+}
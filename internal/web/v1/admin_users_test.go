@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestAdminUnlockUser(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{LockoutThreshold: 2, LockoutDuration: time.Hour}, Options{})
+	alice := s.addTestUser(t, "alice")
+	s.addTestUser(t, "bob")
+	admin := s.addTestUser(t, "admin")
+	ctx := context.Background()
+	if err := s.repos.Users.SetRole(ctx, admin.ID, domain.RoleAdmin); err != nil {
+		t.Fatalf("set role: %v", err)
+	}
+	adminToken, bobToken := s.login(t, "admin"), s.login(t, "bob")
+
+	login := func(password string) *http.Response {
+		return s.do(t, http.MethodPost, "/auth/v1/public/login", "",
+			map[string]string{"username": "alice", "password": password}).Result()
+	}
+	login("wrong-password")
+	login("wrong-password")
+	assertError(t, s.do(t, http.MethodPost, "/auth/v1/public/login", "",
+		map[string]string{"username": "alice", "password": testPassword}), http.StatusForbidden, "account_locked")
+
+	unlockPath := "/auth/v1/admin/users/" + alice.PublicID + "/unlock"
+	assertError(t, s.do(t, http.MethodPost, unlockPath, bobToken, nil), http.StatusForbidden, "forbidden")
+
+	if w := s.do(t, http.MethodPost, unlockPath, adminToken, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("unlock: status = %d, want 204 (body %s)", w.Code, w.Body.String())
+	}
+	row, _ := s.repos.Users.GetByID(ctx, alice.ID)
+	if row.LockedUntil != nil || row.FailedLoginAttempts != 0 {
+		t.Errorf("after unlock: locked_until = %v, failed attempts = %d, want cleared", row.LockedUntil, row.FailedLoginAttempts)
+	}
+
+	if got := login(testPassword).StatusCode; got != http.StatusOK {
+		t.Errorf("login after unlock: status = %d, want 200", got)
+	}
+
+	// An account that is not locked can be unlocked too, which clears a partial count
+	login("wrong-password")
+	if w := s.do(t, http.MethodPost, unlockPath, adminToken, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("unlock with one failed attempt: status = %d, want 204 (body %s)", w.Code, w.Body.String())
+	}
+	if row, _ := s.repos.Users.GetByID(ctx, alice.ID); row.FailedLoginAttempts != 0 {
+		t.Errorf("failed attempts = %d after unlock, want 0", row.FailedLoginAttempts)
+	}
+	login("wrong-password")
+	if got := login(testPassword).StatusCode; got != http.StatusOK {
+		t.Errorf("login after a reset counter and one bad password: status = %d, want 200", got)
+	}
+}
@@ -0,0 +1,164 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	row := s.addTestUser(t, "alice")
+	valid := s.login(t, "alice")
+
+	expired, _, err := s.tokens.IssueWithTTL(row.PublicID, string(row.Role), -time.Minute)
+	if err != nil {
+		t.Fatalf("issue expired token: %v", err)
+	}
+	forged, _, err := logicv1.NewTokenIssuer("another-secret-another-secret-00000", time.Hour).
+		Issue(row.PublicID, string(row.Role))
+	if err != nil {
+		t.Fatalf("issue forged token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing token", "", http.StatusUnauthorized, "unauthorized"},
+		{"not a bearer token", "Basic YWxpY2U6cGFzc3dvcmQ=", http.StatusUnauthorized, "unauthorized"},
+		{"expired token", "Bearer " + expired, http.StatusUnauthorized, "session_expired"},
+		{"forged token", "Bearer " + forged, http.StatusUnauthorized, "invalid_token"},
+		{"valid token", "Bearer " + valid, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers []string
+			if tt.header != "" {
+				headers = []string{"Authorization", tt.header}
+			}
+			w := s.do(t, http.MethodGet, "/auth/v1/private/me", "", nil, headers...)
+
+			if tt.wantCode != "" {
+				assertError(t, w, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := decodeJSON(t, w)["id"]; got != row.PublicID {
+				t.Errorf("id = %v, want %s", got, row.PublicID)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareGuardsAccountRoutes(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/auth/v1/public/sessions"},
+		{http.MethodDelete, "/auth/v1/public/sessions/0b8e6c1e-3f0a-4a57-9b7e-2d1c0f7e4a11"},
+		{http.MethodPost, "/auth/v1/public/change-password"},
+		{http.MethodPost, "/auth/v1/public/change-username"},
+		{http.MethodPatch, "/auth/v1/public/me"},
+		{http.MethodPost, "/auth/v1/public/change-email"},
+		{http.MethodDelete, "/auth/v1/public/account"},
+		{http.MethodPost, "/auth/v1/public/backup-email"},
+		{http.MethodPost, "/auth/v1/public/resend-verification"},
+		{http.MethodPost, "/auth/v1/public/2fa/enroll"},
+		{http.MethodPost, "/auth/v1/public/2fa/confirm"},
+		{http.MethodPost, "/auth/v1/public/2fa/backup-codes"},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			// A body that would fail validation: authentication must be checked first
+			w := s.do(t, route.method, route.path, "", map[string]any{})
+			assertError(t, w, http.StatusUnauthorized, "unauthorized")
+		})
+	}
+}
+
+func TestChangePasswordKeepsCurrentSession(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	s.addTestUser(t, "alice")
+	current := s.login(t, "alice")
+	other := s.login(t, "alice")
+
+	w := s.do(t, http.MethodPost, "/auth/v1/public/change-password", current, map[string]any{
+		"current_password":      testPassword,
+		"new_password":          "a-new-correct-horse",
+		"revoke_other_sessions": true,
+	})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("change password: status = %d, want %d (body %s)", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	if w := s.do(t, http.MethodGet, "/auth/v1/private/me", current, nil); w.Code != http.StatusOK {
+		t.Errorf("current session after password change: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := s.do(t, http.MethodGet, "/auth/v1/private/me", other, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("other session after password change: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthMiddlewareUserRateLimit(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{UserRateLimit: 2, UserRateLimitWindow: time.Minute})
+	s.addTestUser(t, "alice")
+	s.addTestUser(t, "bob")
+	alice := s.login(t, "alice")
+	bob := s.login(t, "bob")
+
+	// Each request comes from another IP, so only a per-user budget can stop alice.
+	me := func(token, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/auth/v1/private/me", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+		w := me(alice, addr)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 (body %s)", i+1, w.Code, w.Body.String())
+		}
+		if got, want := w.Header().Get("X-RateLimit-Remaining"), strconv.Itoa(1-i); got != want {
+			t.Errorf("request %d: X-RateLimit-Remaining = %q, want %q", i+1, got, want)
+		}
+	}
+
+	w := me(alice, "192.0.2.3:1234")
+	assertError(t, w, http.StatusTooManyRequests, "too_many_requests")
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After header missing")
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+		t.Errorf("X-RateLimit-Limit = %q, want 2", got)
+	}
+
+	// Another user from the same IP still has their own budget.
+	if w := me(bob, "192.0.2.3:1234"); w.Code != http.StatusOK {
+		t.Errorf("bob: status = %d, want 200 (body %s)", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthMiddlewareUserRateLimitDisabled(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	s.addTestUser(t, "alice")
+	token := s.login(t, "alice")
+
+	for i := range 20 {
+		if w := s.do(t, http.MethodGet, "/auth/v1/private/me", token, nil); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, w.Code)
+		}
+	}
+}
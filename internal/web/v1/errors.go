@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	"github.com/gin-gonic/gin"
+)
+
+// errorMapping describes how a Logic-layer error is rendered over HTTP.
+type errorMapping struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// errorMappings is the single source of truth for translating Logic-layer
+// sentinel errors into HTTP responses. Add new sentinels here, not in handlers.
+// The first matching entry wins.
+var errorMappings = []errorMapping{
+	{logicv1.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials"},
+	// Don't reveal that user doesn't exist (security best practice)
+	{logicv1.ErrUserNotFound, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials"},
+	{logicv1.ErrPasswordExpired, http.StatusForbidden, "password_expired", "Password expired"},
+	{logicv1.ErrAccountLocked, http.StatusForbidden, "account_locked", "Account locked"},
+	{logicv1.ErrForbidden, http.StatusForbidden, "forbidden", "Forbidden"},
+	{logicv1.ErrUsernameExists, http.StatusConflict, "username_exists", "Username already exists"},
+	{logicv1.ErrInvalidUsername, http.StatusBadRequest, "invalid_username",
+		"Username must be 3-32 letters, digits, dots, dashes or underscores"},
+	{logicv1.ErrUsernameReserved, http.StatusBadRequest, "username_reserved", "Username is reserved"},
+	{logicv1.ErrEmailExists, http.StatusConflict, "email_exists", "Email already exists"},
+	{logicv1.ErrRegistrationClosed, http.StatusForbidden, "registration_closed", "Registration is closed"},
+	{logicv1.ErrInvalidInvite, http.StatusForbidden, "invalid_invite", "Invalid or expired invite"},
+	{logicv1.ErrSessionNotFound, http.StatusUnauthorized, "invalid_token", "Invalid or expired token"},
+	{logicv1.ErrInvalidToken, http.StatusUnauthorized, "invalid_token", "Invalid or expired token"},
+	{logicv1.ErrSessionExpired, http.StatusUnauthorized, "session_expired", "Session expired"},
+	// Same response as an unknown token so a thief learns nothing about the binding
+	{logicv1.ErrSessionBindingMismatch, http.StatusUnauthorized, "invalid_token", "Invalid or expired token"},
+	{logicv1.ErrInvalidRefreshToken, http.StatusUnauthorized, "invalid_refresh_token",
+		"Invalid or expired refresh token"},
+	{logicv1.ErrInvalidResetToken, http.StatusBadRequest, "invalid_reset_token", "Invalid or expired reset token"},
+	{logicv1.ErrInvalidVerificationToken, http.StatusBadRequest, "invalid_verification_token",
+		"Invalid or expired verification link"},
+	{logicv1.ErrTwoFactorUnavailable, http.StatusNotImplemented, "two_factor_unavailable",
+		"Two-factor authentication is not available"},
+	{logicv1.ErrTwoFactorAlreadyEnabled, http.StatusConflict, "two_factor_already_enabled",
+		"Two-factor authentication is already enabled"},
+	{logicv1.ErrTwoFactorNotEnrolled, http.StatusBadRequest, "two_factor_not_enrolled",
+		"Start two-factor enrollment first"},
+	{logicv1.ErrInvalidTOTPCode, http.StatusBadRequest, "invalid_totp_code", "Invalid authentication code"},
+	{logicv1.ErrTooManyRequests, http.StatusTooManyRequests, "too_many_requests", "Too many requests, try again later"},
+	{logicv1.ErrServiceUnavailable, http.StatusServiceUnavailable, "service_unavailable",
+		"Service temporarily unavailable, try again later"},
+	{logicv1.ErrPasswordReused, http.StatusBadRequest, "password_reused", "New password must differ from the current password"},
+	{logicv1.ErrWeakPassword, http.StatusBadRequest, "weak_password", "Password does not meet policy"},
+}
+
+// serviceUnavailableRetryAfter is the Retry-After (seconds) sent with 503s.
+// Saturation is usually brief, so clients are asked to back off only shortly.
+const serviceUnavailableRetryAfter = "1"
+
+// sessionNotFound overrides ErrSessionNotFound where the session is the target
+// resource (e.g., revoking by ID) rather than the caller's credentials.
+var sessionNotFound = errorMapping{
+	logicv1.ErrSessionNotFound, http.StatusNotFound, "session_not_found", "Session not found",
+}
+
+// userNotFound overrides ErrUserNotFound where an admin targets a user by ID, so
+// the usual "don't reveal the user exists" 401 does not apply.
+var userNotFound = errorMapping{
+	logicv1.ErrUserNotFound, http.StatusNotFound, "user_not_found", "User not found",
+}
+
+// invalidLoginTOTP overrides ErrInvalidTOTPCode at login, where it is a failed
+// authentication rather than a bad request.
+var invalidLoginTOTP = errorMapping{
+	logicv1.ErrInvalidTOTPCode, http.StatusUnauthorized, "invalid_totp_code", "Invalid authentication code",
+}
+
+// wrongCurrentPassword overrides ErrInvalidCredentials where the caller is already
+// authenticated: a 401 would make clients drop a session that is still valid.
+var wrongCurrentPassword = errorMapping{
+	logicv1.ErrInvalidCredentials, http.StatusForbidden, "invalid_current_password", "Current password is incorrect",
+}
+
+// respondError writes the HTTP response for err using errorMappings.
+// overrides are checked first for endpoint-specific meanings of a sentinel.
+// Unknown errors become 500 without leaking internal details.
+func (h *Handler) respondError(c *gin.Context, err error, overrides ...errorMapping) {
+	m := lookupErrorMapping(err, overrides)
+
+	var extra gin.H
+	var policyErr *logicv1.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		extra = gin.H{"violations": policyErr.Violations}
+	}
+
+	var retryErr *logicv1.RetryAfterError
+	if errors.As(err, &retryErr) {
+		// Round up so clients never retry a moment too early
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+	}
+	if errors.Is(err, logicv1.ErrServiceUnavailable) {
+		c.Header("Retry-After", serviceUnavailableRetryAfter)
+	}
+
+	h.writeError(c, m.status, m.code, m.message, extra)
+}
+
+// lookupErrorMapping returns the mapping for err, falling back to 500.
+func lookupErrorMapping(err error, overrides []errorMapping) errorMapping {
+	for _, m := range overrides {
+		if errors.Is(err, m.err) {
+			return m
+		}
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m
+		}
+	}
+	return errorMapping{
+		status:  http.StatusInternalServerError,
+		code:    "internal_error",
+		message: "Internal server error",
+	}
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestErrorMappings(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{logicv1.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{logicv1.ErrUserNotFound, http.StatusUnauthorized, "invalid_credentials"},
+		{logicv1.ErrPasswordExpired, http.StatusForbidden, "password_expired"},
+		{logicv1.ErrAccountLocked, http.StatusForbidden, "account_locked"},
+		{logicv1.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{logicv1.ErrUsernameExists, http.StatusConflict, "username_exists"},
+		{logicv1.ErrInvalidUsername, http.StatusBadRequest, "invalid_username"},
+		{logicv1.ErrUsernameReserved, http.StatusBadRequest, "username_reserved"},
+		{logicv1.ErrEmailExists, http.StatusConflict, "email_exists"},
+		{logicv1.ErrRegistrationClosed, http.StatusForbidden, "registration_closed"},
+		{logicv1.ErrInvalidInvite, http.StatusForbidden, "invalid_invite"},
+		{logicv1.ErrSessionNotFound, http.StatusUnauthorized, "invalid_token"},
+		{logicv1.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+		{logicv1.ErrSessionExpired, http.StatusUnauthorized, "session_expired"},
+		{logicv1.ErrSessionBindingMismatch, http.StatusUnauthorized, "invalid_token"},
+		{logicv1.ErrInvalidRefreshToken, http.StatusUnauthorized, "invalid_refresh_token"},
+		{logicv1.ErrInvalidResetToken, http.StatusBadRequest, "invalid_reset_token"},
+		{logicv1.ErrInvalidVerificationToken, http.StatusBadRequest, "invalid_verification_token"},
+		{logicv1.ErrTwoFactorUnavailable, http.StatusNotImplemented, "two_factor_unavailable"},
+		{logicv1.ErrTwoFactorAlreadyEnabled, http.StatusConflict, "two_factor_already_enabled"},
+		{logicv1.ErrTwoFactorNotEnrolled, http.StatusBadRequest, "two_factor_not_enrolled"},
+		{logicv1.ErrInvalidTOTPCode, http.StatusBadRequest, "invalid_totp_code"},
+		{logicv1.ErrTooManyRequests, http.StatusTooManyRequests, "too_many_requests"},
+		{logicv1.ErrServiceUnavailable, http.StatusServiceUnavailable, "service_unavailable"},
+		{logicv1.ErrPasswordReused, http.StatusBadRequest, "password_reused"},
+		{logicv1.ErrWeakPassword, http.StatusBadRequest, "weak_password"},
+	}
+
+	covered := map[error]bool{}
+	for _, tt := range tests {
+		covered[tt.err] = true
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			// Handlers see sentinels wrapped with context by the Logic layer
+			m := lookupErrorMapping(fmt.Errorf("operation: %w", tt.err), nil)
+			if m.status != tt.wantStatus || m.code != tt.wantCode {
+				t.Errorf("mapping = %d %s, want %d %s", m.status, m.code, tt.wantStatus, tt.wantCode)
+			}
+			if m.message == "" {
+				t.Error("mapping has no message")
+			}
+		})
+	}
+
+	for _, m := range errorMappings {
+		if !covered[m.err] {
+			t.Errorf("errorMappings entry for %q has no test case", m.err)
+		}
+	}
+}
+
+func TestErrorMappingOverrides(t *testing.T) {
+	tests := []struct {
+		name       string
+		override   errorMapping
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"session as target resource", sessionNotFound, logicv1.ErrSessionNotFound, http.StatusNotFound, "session_not_found"},
+		{"user targeted by an admin", userNotFound, logicv1.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+		{"second factor at login", invalidLoginTOTP, logicv1.ErrInvalidTOTPCode, http.StatusUnauthorized, "invalid_totp_code"},
+		{"current password of a signed-in user", wrongCurrentPassword, logicv1.ErrInvalidCredentials,
+			http.StatusForbidden, "invalid_current_password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := lookupErrorMapping(fmt.Errorf("operation: %w", tt.err), []errorMapping{tt.override})
+			if m.status != tt.wantStatus || m.code != tt.wantCode {
+				t.Errorf("mapping = %d %s, want %d %s", m.status, m.code, tt.wantStatus, tt.wantCode)
+			}
+
+			// An override only applies to its own sentinel
+			m = lookupErrorMapping(logicv1.ErrTooManyRequests, []errorMapping{tt.override})
+			if m.code != "too_many_requests" {
+				t.Errorf("unrelated error with override: code = %s, want too_many_requests", m.code)
+			}
+		})
+	}
+}
+
+func TestErrorMappingUnknownError(t *testing.T) {
+	m := lookupErrorMapping(errors.New("connection reset by peer"), nil)
+	if m.status != http.StatusInternalServerError || m.code != "internal_error" {
+		t.Errorf("mapping = %d %s, want 500 internal_error", m.status, m.code)
+	}
+	if m.message != "Internal server error" {
+		t.Errorf("message = %q leaks details", m.message)
+	}
+}
+
+func TestServiceUnavailableResponse(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	s.repos.Users.CreateErr = fmt.Errorf("acquire connection within 2s: %w", logicv1.ErrServiceUnavailable)
+
+	w := s.do(t, http.MethodPost, "/auth/v1/public/register", "",
+		map[string]string{"username": "alice", "email": "alice@example.com", "password": testPassword})
+	assertError(t, w, http.StatusServiceUnavailable, "service_unavailable")
+	if got := w.Header().Get("Retry-After"); got != serviceUnavailableRetryAfter {
+		t.Errorf("Retry-After = %q, want %q", got, serviceUnavailableRetryAfter)
+	}
+}
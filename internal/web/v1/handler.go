@@ -1,8 +1,9 @@
 package v1
 
 import (
-	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/duynhne/auth-service/internal/core/domain"
 	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
@@ -17,19 +18,102 @@ import (
 // Dependencies are injected via the constructor — no global state.
 type Handler struct {
 	auth *logicv1.AuthService
+	opts Options
+	// userLimiter holds the per-user budgets spent in authenticate; nil when disabled.
+	userLimiter *middleware.RateLimiter
+	// introspectionLimiter holds the per-API-key introspection budgets; nil when disabled.
+	introspectionLimiter *middleware.RateLimiter
+}
+
+// Options holds HTTP presentation settings for Handler.
+// The zero value keeps the default (bare) response shapes.
+type Options struct {
+	// ResponseEnvelope wraps successes in {"data": ...} and errors in {"error": {...}}.
+	ResponseEnvelope bool
+	// RateLimit is the per-IP budget of RateLimitWindow applied to each credential
+	// endpoint (login, register, password reset). 0 disables it.
+	RateLimit       int
+	RateLimitWindow time.Duration
+	// UserRateLimit is the budget of UserRateLimitWindow each authenticated user
+	// shares across every route behind AuthMiddleware or RequireRole, independent
+	// of their IP. 0 disables it.
+	UserRateLimit       int
+	UserRateLimitWindow time.Duration
+	// IntrospectionAPIKey lets gateways call token introspection with X-API-Key
+	// instead of an admin token. Empty accepts admin tokens only.
+	IntrospectionAPIKey string
+	// IntrospectionRateLimit is the budget of IntrospectionRateLimitWindow for each
+	// API key calling introspection. Key callers skip the per-IP and per-user
+	// limits; admin-token callers spend their user budget instead. 0 disables it.
+	IntrospectionRateLimit       int
+	IntrospectionRateLimitWindow time.Duration
 }
 
 // NewHandler creates a new Handler with the given AuthService.
-func NewHandler(auth *logicv1.AuthService) *Handler {
-	return &Handler{auth: auth}
+func NewHandler(auth *logicv1.AuthService, opts Options) *Handler {
+	return &Handler{
+		auth:        auth,
+		opts:        opts,
+		userLimiter: middleware.NewRateLimiter(opts.UserRateLimit, opts.UserRateLimitWindow),
+		introspectionLimiter: middleware.NewRateLimiter(
+			opts.IntrospectionRateLimit, opts.IntrospectionRateLimitWindow),
+	}
+}
+
+// NotFound responds to requests that match no route.
+func (h *Handler) NotFound(c *gin.Context) {
+	h.writeError(c, http.StatusNotFound, "not_found", "Not found", nil)
+}
+
+// MethodNotAllowed responds to requests whose path exists under another method.
+// Gin sets the Allow header before invoking it.
+func (h *Handler) MethodNotAllowed(c *gin.Context) {
+	h.writeError(c, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed", nil)
 }
 
 // RegisterRoutes mounts auth v1 routes using Variant A edge naming
 // (see homelab/docs/api/api-naming-convention.md).
+// Credential endpoints, including those re-checking the current password, are
+// rate limited per client IP, each with its own budget. Routes acting on the
+// caller's account sit behind AuthMiddleware.
 func (h *Handler) RegisterRoutes(r gin.IRouter) {
-	r.POST("/auth/v1/public/login", h.Login)
-	r.POST("/auth/v1/public/register", h.Register)
-	r.GET("/auth/v1/private/me", h.GetMe)
+	rateLimit := func() gin.HandlerFunc {
+		return middleware.RateLimitMiddlewareWithReject(h.opts.RateLimit, h.opts.RateLimitWindow, func(c *gin.Context) {
+			h.writeError(c, http.StatusTooManyRequests, "too_many_requests", "Too many requests, try again later", nil)
+			c.Abort()
+		})
+	}
+
+	r.POST("/auth/v1/public/login", rateLimit(), h.Login)
+	r.POST("/auth/v1/public/register", rateLimit(), h.Register)
+	r.GET("/auth/v1/private/me", h.AuthMiddleware(), h.GetMe)
+	r.POST("/auth/v1/private/introspect", h.requireIntrospectionCaller(), h.Introspect)
+	r.POST("/auth/v1/public/logout", h.Logout)
+	r.POST("/auth/v1/public/refresh", rateLimit(), h.Refresh)
+	r.POST("/auth/v1/public/forgot-password", rateLimit(), h.ForgotPassword)
+	r.POST("/auth/v1/public/reset-password", rateLimit(), h.ResetPassword)
+	r.POST("/auth/v1/public/change-password", rateLimit(), h.AuthMiddleware(), h.ChangePassword)
+	r.POST("/auth/v1/public/change-username", rateLimit(), h.AuthMiddleware(), h.ChangeUsername)
+	r.PATCH("/auth/v1/public/me", rateLimit(), h.AuthMiddleware(), h.UpdateProfile)
+	r.POST("/auth/v1/public/change-email", rateLimit(), h.AuthMiddleware(), h.ChangeEmail)
+	r.DELETE("/auth/v1/public/account", rateLimit(), h.AuthMiddleware(), h.DeleteAccount)
+	r.GET("/auth/v1/public/verify-email-change", h.VerifyEmailChange)
+	r.POST("/auth/v1/public/backup-email", rateLimit(), h.AuthMiddleware(), h.SetBackupEmail)
+	r.GET("/auth/v1/public/verify-backup-email", h.VerifyBackupEmail)
+	r.GET("/auth/v1/public/verify-email", h.VerifyEmail)
+	r.POST("/auth/v1/public/resend-verification", h.AuthMiddleware(), h.ResendVerification)
+	// 2FA is only for confirmed addresses, so a lost authenticator can still be recovered by email
+	r.POST("/auth/v1/public/2fa/enroll", h.AuthMiddleware(), h.RequireVerifiedEmail(), h.EnrollTOTP)
+	r.POST("/auth/v1/public/2fa/confirm", h.AuthMiddleware(), h.ConfirmTOTP)
+	r.POST("/auth/v1/public/2fa/backup-codes", h.AuthMiddleware(), h.RegenerateBackupCodes)
+	r.GET("/auth/v1/public/sessions", h.AuthMiddleware(), h.ListSessions)
+	r.DELETE("/auth/v1/public/sessions/:id", h.AuthMiddleware(), h.DeleteSession)
+
+	// Admin audience: every route requires the admin role
+	admin := r.Group("/auth/v1/admin", h.RequireRole(domain.RoleAdmin))
+	admin.POST("/invites", h.AdminIssueInvite)
+	admin.GET("/users", h.AdminListUsers)
+	admin.POST("/users/:id/unlock", h.AdminUnlockUser)
 }
 
 // Login handles HTTP request for user login.
@@ -48,36 +132,35 @@ func (h *Handler) Login(c *gin.Context) {
 		span.SetAttributes(attribute.Bool("request.valid", false))
 		span.RecordError(err)
 		logger.Error().Err(err).Msg("Invalid request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
 		return
 	}
 
 	span.SetAttributes(attribute.Bool("request.valid", true))
 
 	// Call business logic layer
-	response, err := h.auth.Login(ctx, req)
+	result, err := h.auth.Login(ctx, req, clientInfo(c))
 	if err != nil {
 		span.RecordError(err)
 		logger.Error().Err(err).Msg("Login failed")
 
-		switch {
-		case errors.Is(err, logicv1.ErrInvalidCredentials):
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		case errors.Is(err, logicv1.ErrUserNotFound):
-			// Don't reveal that user doesn't exist (security best practice)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		case errors.Is(err, logicv1.ErrPasswordExpired):
-			c.JSON(http.StatusForbidden, gin.H{"error": "Password expired"})
-		case errors.Is(err, logicv1.ErrAccountLocked):
-			c.JSON(http.StatusForbidden, gin.H{"error": "Account locked"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		h.respondError(c, err, invalidLoginTOTP)
 		return
 	}
 
-	logger.Info().Str("user_id", response.User.ID).Msg("Login successful")
-	c.JSON(http.StatusOK, response)
+	switch result.Next {
+	case logicv1.StepNone:
+		logger.Info().Str("user_id", result.Session.User.ID).Msg("Login successful")
+		h.respond(c, http.StatusOK, result.Session)
+	case logicv1.StepTOTP:
+		logger.Info().Msg("Login awaiting two-factor code")
+		// The flag lets clients switch to the code prompt without matching on strings
+		h.writeError(c, http.StatusUnauthorized, "two_factor_required", "Two-factor code required",
+			gin.H{"two_factor_required": true})
+	default:
+		logger.Error().Str("next_step", string(result.Next)).Msg("Unhandled login step")
+		h.writeError(c, http.StatusInternalServerError, "internal_error", "Internal server error", nil)
+	}
 }
 
 // Register handles HTTP request for user registration.
@@ -96,14 +179,14 @@ func (h *Handler) Register(c *gin.Context) {
 		span.SetAttributes(attribute.Bool("request.valid", false))
 		span.RecordError(err)
 		logger.Error().Err(err).Msg("Invalid request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
 		return
 	}
 
 	span.SetAttributes(attribute.Bool("request.valid", true))
 
 	// Call business logic layer
-	response, err := h.auth.Register(ctx, req)
+	response, err := h.auth.Register(ctx, req, clientInfo(c))
 	if err != nil {
 		span.RecordError(err)
 		logger.Error().
@@ -111,22 +194,17 @@ func (h *Handler) Register(c *gin.Context) {
 			Str("username", req.Username).
 			Msg("Registration failed")
 
-		switch {
-		case errors.Is(err, logicv1.ErrUserExists):
-			c.JSON(http.StatusConflict, gin.H{"error": "Username or email already exists"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		h.respondError(c, err)
 		return
 	}
 
 	logger.Info().Str("user_id", response.User.ID).Msg("Registration successful")
-	c.JSON(http.StatusCreated, response)
+	h.respond(c, http.StatusCreated, response)
 }
 
 // GetMe handles HTTP request to get current user from session token.
 // GET /auth/v1/private/me
-// Authorization: Bearer <token>
+// Authorization: Bearer <token> (checked by AuthMiddleware)
 func (h *Handler) GetMe(c *gin.Context) {
 	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
 		attribute.String("layer", "web"),
@@ -135,44 +213,796 @@ func (h *Handler) GetMe(c *gin.Context) {
 	))
 	defer span.End()
 
+	user := CurrentUser(c)
+
+	pkgzerolog.FromContext(ctx).Info().Str("user_id", user.ID).Msg("Token validated")
+	h.respond(c, http.StatusOK, user)
+}
+
+// Introspect handles HTTP request from a gateway to check an access token (RFC 7662).
+// POST /auth/v1/private/introspect
+// X-API-Key: <INTROSPECTION_API_KEY>, or Authorization: Bearer <token> (admin role)
+// Body: {"token": "..."} or the form-encoded token parameter.
+// Responds 200 with {"active": false} for any token that is not active.
+func (h *Handler) Introspect(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	var req domain.IntrospectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	response, err := h.auth.Introspect(ctx, req.Token)
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Msg("Token introspection failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Bool("active", response.Active).Str("user_id", response.Subject).Msg("Token introspected")
+	h.respondUnwrapped(c, http.StatusOK, response)
+}
+
+// Logout handles HTTP request to revoke the caller's current session.
+// POST /auth/v1/public/logout
+// Authorization: Bearer <token>
+// Returns 204 even when the session is already gone, so clients can retry safely.
+func (h *Handler) Logout(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	token, ok := h.bearerToken(c, span)
+	if !ok {
+		return
+	}
+
+	if err := h.auth.Logout(ctx, token); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Msg("Logout failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Msg("Logged out")
+	c.Status(http.StatusNoContent)
+}
+
+// ListSessions handles HTTP request to list the caller's active sessions.
+// GET /auth/v1/public/sessions
+// Authorization: Bearer <token> (checked by AuthMiddleware)
+func (h *Handler) ListSessions(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	sessions, err := h.auth.ListSessions(ctx, requester)
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", requester.ID).Msg("Session listing failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// ForgotPassword handles HTTP request to start a password reset.
+// POST /auth/v1/public/forgot-password
+// Always returns 200 for a well-formed request so the response does not reveal
+// whether the email is registered.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	var req domain.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	h.auth.RequestPasswordReset(ctx, req.Email)
+
+	logger.Info().Msg("Password reset requested")
+	h.respond(c, http.StatusOK, gin.H{"message": "If the email is registered, a reset link has been sent"})
+}
+
+// Refresh handles HTTP request to exchange a refresh token for a new access token.
+// POST /auth/v1/public/refresh
+// The presented refresh token is used up; the response carries its replacement.
+func (h *Handler) Refresh(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	var req domain.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	response, err := h.auth.Refresh(ctx, req.RefreshToken, clientInfo(c))
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Msg("Token refresh failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Str("user_id", response.User.ID).Msg("Token refreshed")
+	h.respond(c, http.StatusOK, response)
+}
+
+// ResetPassword handles HTTP request to set a new password with a reset token.
+// POST /auth/v1/public/reset-password
+// On success every existing session of the user is revoked.
+func (h *Handler) ResetPassword(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
 	logger := pkgzerolog.FromContext(ctx)
 
-	// Extract token from Authorization header
+	var req domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	if err := h.auth.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Msg("Password reset failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Msg("Password reset completed")
+	c.Status(http.StatusNoContent)
+}
+
+// ChangePassword handles HTTP request to rotate the caller's password.
+// POST /auth/v1/public/change-password
+// Authorization: Bearer <token> (checked by AuthMiddleware)
+func (h *Handler) ChangePassword(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	var req domain.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	if err := h.auth.ChangePassword(ctx, requester, currentToken(c), req); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Password change failed")
+
+		h.respondError(c, err, wrongCurrentPassword)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("Password changed")
+	c.Status(http.StatusNoContent)
+}
+
+// ChangeUsername handles HTTP request to rename the authenticated user.
+// POST /auth/v1/public/change-username
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Body: {"username": "...", "current_password": "..."}
+func (h *Handler) ChangeUsername(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	var req domain.ChangeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	if err := h.auth.ChangeUsername(ctx, requester, req); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Username change failed")
+
+		h.respondError(c, err, wrongCurrentPassword)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("Username changed")
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteAccount handles HTTP request to delete the authenticated user's account.
+// DELETE /auth/v1/public/account
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Body: {"current_password": "..."}
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	var req domain.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	if err := h.auth.DeleteAccount(ctx, requester, req); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Account deletion failed")
+
+		h.respondError(c, err, wrongCurrentPassword)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("Account deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateProfile handles HTTP request to change the authenticated user's username and/or email.
+// PATCH /auth/v1/public/me
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Body: {"username": "...", "email": "...", "current_password": "..."} (username and email optional)
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	var req domain.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	response, err := h.auth.UpdateProfile(ctx, requester, req)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Profile update failed")
+
+		h.respondError(c, err, wrongCurrentPassword)
+		return
+	}
+
+	logger.Info().
+		Str("user_id", requester.ID).
+		Bool("email_change_pending", response.EmailChangePending).
+		Msg("Profile updated")
+	h.respond(c, http.StatusOK, response)
+}
+
+// ChangeEmail handles HTTP request to start an email change for the authenticated user.
+// POST /auth/v1/public/change-email
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Body: {"new_email": "...", "current_password": "..."}
+// Responds 202: the change applies only once the link sent to new_email is followed.
+func (h *Handler) ChangeEmail(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	var req domain.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	if err := h.auth.RequestEmailChange(ctx, requester, req); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Email change request failed")
+
+		h.respondError(c, err, wrongCurrentPassword)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("Email change requested")
+	h.respond(c, http.StatusAccepted, gin.H{"message": "Confirmation link sent to the new email address"})
+}
+
+// VerifyEmailChange handles HTTP request to confirm a new email address from the emailed link.
+// GET /auth/v1/public/verify-email-change?token=<token>
+func (h *Handler) VerifyEmailChange(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	token := c.Query("token")
+	if token == "" {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		h.writeError(c, http.StatusBadRequest, "invalid_request", "token query parameter is required", nil)
+		return
+	}
+
+	if err := h.auth.VerifyEmailChange(ctx, token); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Msg("Email change verification failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Msg("Email changed")
+	h.respond(c, http.StatusOK, gin.H{"message": "Email changed"})
+}
+
+// SetBackupEmail handles HTTP request to register a recovery email for the authenticated user.
+// POST /auth/v1/public/backup-email
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Body: {"backup_email": "...", "current_password": "..."}
+// Responds 202: the address is usable for recovery only once the link sent to it is followed.
+func (h *Handler) SetBackupEmail(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	var req domain.SetBackupEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	if err := h.auth.RequestBackupEmail(ctx, requester, req); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Backup email request failed")
+
+		h.respondError(c, err, wrongCurrentPassword)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("Backup email requested")
+	h.respond(c, http.StatusAccepted, gin.H{"message": "Confirmation link sent to the backup email address"})
+}
+
+// VerifyBackupEmail handles HTTP request to confirm a backup email from the emailed link.
+// GET /auth/v1/public/verify-backup-email?token=<token>
+func (h *Handler) VerifyBackupEmail(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	token := c.Query("token")
+	if token == "" {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		h.writeError(c, http.StatusBadRequest, "invalid_request", "token query parameter is required", nil)
+		return
+	}
+
+	if err := h.auth.VerifyBackupEmail(ctx, token); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Msg("Backup email verification failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Msg("Backup email verified")
+	h.respond(c, http.StatusOK, gin.H{"message": "Backup email verified"})
+}
+
+// VerifyEmail handles HTTP request to confirm an email address from the emailed link.
+// GET /auth/v1/public/verify-email?token=<token>
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	token := c.Query("token")
+	if token == "" {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		h.writeError(c, http.StatusBadRequest, "invalid_request", "token query parameter is required", nil)
+		return
+	}
+
+	if err := h.auth.VerifyEmail(ctx, token); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Msg("Email verification failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Msg("Email verified")
+	h.respond(c, http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// ResendVerification handles HTTP request to send a new email verification link.
+// POST /auth/v1/public/resend-verification
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Responds 200 even when the email is already verified.
+func (h *Handler) ResendVerification(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	if err := h.auth.ResendEmailVerification(ctx, requester); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Verification resend failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, gin.H{"message": "If your email is not yet verified, a new link has been sent"})
+}
+
+// EnrollTOTP handles HTTP request to start TOTP two-factor enrollment.
+// POST /auth/v1/public/2fa/enroll
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	enrollment, err := h.auth.EnrollTOTP(ctx, requester)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("TOTP enrollment failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("TOTP enrollment started")
+	h.respond(c, http.StatusOK, enrollment)
+}
+
+// ConfirmTOTP handles HTTP request to activate a pending TOTP enrollment.
+// POST /auth/v1/public/2fa/confirm
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Body: {"code": "123456"}
+func (h *Handler) ConfirmTOTP(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	var req domain.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	backupCodes, err := h.auth.ConfirmTOTP(ctx, requester, req.Code)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("TOTP confirmation failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("Two-factor authentication enabled")
+	// backup_codes is empty if they could not be stored; the user can regenerate them
+	h.respond(c, http.StatusOK, gin.H{"backup_codes": backupCodes})
+}
+
+// RegenerateBackupCodes handles HTTP request to replace the 2FA backup codes.
+// POST /auth/v1/public/2fa/backup-codes
+// Headers: Authorization: Bearer <token> (checked by AuthMiddleware)
+// Every previous backup code stops working.
+func (h *Handler) RegenerateBackupCodes(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	backupCodes, err := h.auth.RegenerateBackupCodes(ctx, requester)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Msg("Backup code regeneration failed")
+
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Msg("Backup codes regenerated")
+	h.respond(c, http.StatusOK, gin.H{"backup_codes": backupCodes})
+}
+
+// DeleteSession handles HTTP request to revoke one of the caller's sessions, or
+// any user's session when the caller is an admin.
+// DELETE /auth/v1/public/sessions/:id
+// Authorization: Bearer <token> (checked by AuthMiddleware)
+func (h *Handler) DeleteSession(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	requester := CurrentUser(c)
+
+	sessionID := c.Param("id")
+
+	if err := h.auth.DeleteSession(ctx, requester, sessionID); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", requester.ID).Str("session_id", sessionID).Msg("Session revocation failed")
+
+		h.respondError(c, err, sessionNotFound)
+		return
+	}
+
+	logger.Info().Str("user_id", requester.ID).Str("session_id", sessionID).Msg("Session revoked")
+	c.Status(http.StatusNoContent)
+}
+
+// AdminIssueInvite handles HTTP request from an admin to issue a single-use registration invite.
+// POST /auth/v1/admin/invites
+// Authorization: Bearer <token> (admin role, checked by RequireRole)
+func (h *Handler) AdminIssueInvite(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	admin := CurrentUser(c)
+
+	var req domain.InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		h.writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	response, err := h.auth.IssueInvite(ctx, admin, req.Email)
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", admin.ID).Msg("Invite issuance failed")
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Str("user_id", admin.ID).Msg("Admin invite issued")
+	h.respond(c, http.StatusCreated, response)
+}
+
+// AdminUnlockUser handles HTTP request from an admin to lift a user's lockout
+// after repeated failed logins and reset their failed-login counter.
+// POST /auth/v1/admin/users/:id/unlock
+// Authorization: Bearer <token> (admin role, checked by RequireRole)
+// Unlocking an account that is not locked is not an error (204 either way).
+func (h *Handler) AdminUnlockUser(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	admin := CurrentUser(c)
+	userID := c.Param("id")
+
+	if err := h.auth.UnlockUser(ctx, admin, userID); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("user_id", admin.ID).Str("target_user_id", userID).Msg("Account unlock failed")
+
+		h.respondError(c, err, userNotFound)
+		return
+	}
+
+	logger.Info().Str("user_id", admin.ID).Str("target_user_id", userID).Msg("Account unlocked")
+	c.Status(http.StatusNoContent)
+}
+
+// AdminListUsers handles HTTP request from an admin to list users page by page.
+// GET /auth/v1/admin/users?limit=<n>&offset=<n>
+// Authorization: Bearer <token> (admin role, checked by RequireRole)
+// limit defaults to 20 and is capped at 100; the response carries the total count.
+func (h *Handler) AdminListUsers(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+	admin := CurrentUser(c)
+
+	limit, limitOK := queryInt(c, "limit")
+	offset, offsetOK := queryInt(c, "offset")
+	if !limitOK || !offsetOK {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		h.writeError(c, http.StatusBadRequest, "invalid_request", "limit and offset must be non-negative integers", nil)
+		return
+	}
+
+	page, err := h.auth.ListUsers(ctx, limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("user_id", admin.ID).Msg("User listing failed")
+		h.respondError(c, err)
+		return
+	}
+
+	logger.Info().Str("user_id", admin.ID).Int("count", len(page.Users)).Msg("Users listed")
+	h.respond(c, http.StatusOK, page)
+}
+
+// queryInt parses an optional non-negative integer query parameter.
+// A missing parameter yields 0; a malformed or negative one returns false.
+func queryInt(c *gin.Context, name string) (int, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+// On failure it writes a 401 response and returns false.
+func (h *Handler) bearerToken(c *gin.Context, span trace.Span) (string, bool) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
 		span.SetAttributes(attribute.Bool("auth.present", false))
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-		return
+		h.writeError(c, http.StatusUnauthorized, "unauthorized", "Authorization header required", nil)
+		return "", false
 	}
 
 	// Expect "Bearer <token>"
 	const bearerPrefix = "Bearer "
 	if len(authHeader) <= len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
 		span.SetAttributes(attribute.Bool("auth.valid_format", false))
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
-		return
+		h.writeError(c, http.StatusUnauthorized, "unauthorized", "Invalid authorization format", nil)
+		return "", false
 	}
-	token := authHeader[len(bearerPrefix):]
 
 	span.SetAttributes(attribute.Bool("auth.present", true))
+	return authHeader[len(bearerPrefix):], true
+}
 
-	// Lookup user by token
-	user, err := h.auth.GetUserByToken(ctx, token)
-	if err != nil {
-		span.RecordError(err)
-		logger.Warn().Err(err).Msg("Token lookup failed")
+// DeviceIDHeader carries the client's device identifier (set by the frontend from its device cookie).
+const DeviceIDHeader = "X-Device-ID"
 
-		switch {
-		case errors.Is(err, logicv1.ErrSessionNotFound):
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-		case errors.Is(err, logicv1.ErrSessionExpired):
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
-		return
+// clientInfo captures the request attributes used for session binding.
+func clientInfo(c *gin.Context) domain.ClientInfo {
+	return domain.ClientInfo{
+		DeviceID: c.GetHeader(DeviceIDHeader),
+		IP:       c.ClientIP(),
 	}
-
-	logger.Info().Str("user_id", user.ID).Msg("Token validated")
-	c.JSON(http.StatusOK, user)
 }
@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testTokenSecret signs access tokens in tests.
+const testTokenSecret = "test-secret-test-secret-test-secret-00"
+
+// testPassword is the password of every user created by addTestUser.
+const testPassword = "correct-horse-battery"
+
+// testServer is a router serving the v1 routes over in-memory repositories.
+type testServer struct {
+	router *gin.Engine
+	auth   *logicv1.AuthService
+	repos  *memory.Repos
+	tokens *logicv1.TokenIssuer
+}
+
+// newTestServer mounts the v1 routes the way main does, backed by fresh
+// in-memory repositories.
+func newTestServer(t *testing.T, logicOpts logicv1.Options, opts Options) *testServer {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	if logicOpts.BcryptCost == 0 {
+		logicOpts.BcryptCost = bcrypt.MinCost
+	}
+
+	repos := memory.New()
+	tokens := logicv1.NewTokenIssuer(testTokenSecret, time.Hour)
+	auth := logicv1.NewAuthService(logicv1.Repositories{
+		Users:         repos.Users,
+		Sessions:      repos.Sessions,
+		Invites:       repos.Invites,
+		Resets:        repos.Resets,
+		Verifications: repos.Verifications,
+		Tokens:        repos.Tokens,
+		EmailChanges:  repos.EmailChanges,
+		TOTP:          repos.TOTP,
+		BackupCodes:   repos.BackupCodes,
+		RefreshTokens: repos.RefreshTokens,
+	}, tokens, repos.Notifier, logicOpts)
+
+	h := NewHandler(auth, opts)
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(h.NotFound)
+	r.NoMethod(h.MethodNotAllowed)
+	h.RegisterRoutes(r)
+
+	return &testServer{router: r, auth: auth, repos: repos, tokens: tokens}
+}
+
+// addTestUser stores a user with testPassword and returns its row.
+func (s *testServer) addTestUser(t *testing.T, username string) *domain.UserRow {
+	t.Helper()
+	return s.repos.Users.AddUser(t, username, username+"@example.com", testPassword, bcrypt.MinCost)
+}
+
+// login logs username in with testPassword and returns the access token.
+func (s *testServer) login(t *testing.T, username string) string {
+	t.Helper()
+	return s.loginResponse(t, username).Token
+}
+
+// loginResponse logs username in with testPassword and returns the new session.
+func (s *testServer) loginResponse(t *testing.T, username string) *domain.AuthResponse {
+	t.Helper()
+
+	result, err := s.auth.Login(context.Background(),
+		domain.LoginRequest{Username: username, Password: testPassword}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("login %s: %v", username, err)
+	}
+	if result.Session == nil {
+		t.Fatalf("login %s: no session, next step %q", username, result.Next)
+	}
+	return result.Session
+}
+
+// do serves a request with an optional bearer token and JSON body (nil for none).
+func (s *testServer) do(t *testing.T, method, path, token string, body any, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeJSON decodes a JSON response body into a map.
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+// assertError checks status and the bare-shape error code of w.
+func assertError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, status, w.Body.String())
+	}
+	if got := decodeJSON(t, w)["code"]; got != code {
+		t.Errorf("code = %v, want %q (body %s)", got, code, w.Body.String())
+	}
+}
+
+func TestNotFoundAndMethodNotAllowed(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+
+	assertError(t, s.do(t, http.MethodGet, "/auth/v1/public/nope", "", nil), http.StatusNotFound, "not_found")
+
+	w := s.do(t, http.MethodGet, "/auth/v1/public/login", "", nil)
+	assertError(t, w, http.StatusMethodNotAllowed, "method_not_allowed")
+	if allow := w.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Allow = %q, want %q", allow, http.MethodPost)
+	}
+}
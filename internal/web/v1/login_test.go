@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestLoginResults(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{TOTPEncryptionKey: bytes.Repeat([]byte{7}, 32)}, Options{})
+	s.addTestUser(t, "alice")
+	bob := s.addTestUser(t, "bob")
+
+	// Confirm bob's enrollment directly: the code check has tests of its own
+	ctx := context.Background()
+	if _, err := s.auth.EnrollTOTP(ctx, &domain.User{ID: bob.PublicID, InternalID: bob.ID, Username: bob.Username}); err != nil {
+		t.Fatalf("enroll: %v", err)
+	}
+	if err := s.repos.TOTP.Confirm(ctx, bob.ID, 0); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		body       map[string]string
+		wantStatus int
+		wantCode   string
+	}{
+		{"session", map[string]string{"username": "alice", "password": testPassword}, http.StatusOK, ""},
+		{"two-factor code required", map[string]string{"username": "bob", "password": testPassword},
+			http.StatusUnauthorized, "two_factor_required"},
+		{"wrong second factor", map[string]string{"username": "bob", "password": testPassword, "backup_code": "not-a-code"},
+			http.StatusUnauthorized, "invalid_totp_code"},
+		{"wrong password", map[string]string{"username": "bob", "password": "wrong-password"},
+			http.StatusUnauthorized, "invalid_credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.do(t, http.MethodPost, "/auth/v1/public/login", "", tt.body)
+
+			if tt.wantCode != "" {
+				assertError(t, w, tt.wantStatus, tt.wantCode)
+				required, _ := decodeJSON(t, w)["two_factor_required"].(bool)
+				if want := tt.wantCode == "two_factor_required"; required != want {
+					t.Errorf("two_factor_required = %v, want %v", required, want)
+				}
+				return
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if token, _ := decodeJSON(t, w)["token"].(string); token == "" {
+				t.Errorf("no token in %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoginIssuesFreshSessionIgnoringPresentedToken(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	alice := s.addTestUser(t, "alice")
+	mallory := s.addTestUser(t, "mallory")
+	planted := s.loginResponse(t, "mallory")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"another user's live session", planted.Token},
+		{"an identifier the client made up", "attacker-chosen-session-id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.do(t, http.MethodPost, "/auth/v1/public/login", tt.token,
+				map[string]string{"username": "alice", "password": testPassword})
+			if w.Code != http.StatusOK {
+				t.Fatalf("login: status = %d, want 200 (body %s)", w.Code, w.Body.String())
+			}
+			body := decodeJSON(t, w)
+			if body["token"] == tt.token || body["token"] == "" {
+				t.Errorf("login returned token %v, want a fresh one", body["token"])
+			}
+			if body["session_id"] == planted.SessionID {
+				t.Errorf("login reused the presented session %s", planted.SessionID)
+			}
+
+			fresh, _ := body["token"].(string)
+			if got := decodeJSON(t, s.do(t, http.MethodGet, "/auth/v1/private/me", fresh, nil))["id"]; got != alice.PublicID {
+				t.Errorf("fresh token: id = %v, want %s", got, alice.PublicID)
+			}
+		})
+	}
+
+	// The planted token was not upgraded to alice's account.
+	w := s.do(t, http.MethodGet, "/auth/v1/private/me", planted.Token, nil)
+	if got := decodeJSON(t, w)["id"]; got != mallory.PublicID {
+		t.Errorf("planted token after alice's login: id = %v, want %s", got, mallory.PublicID)
+	}
+	assertError(t, s.do(t, http.MethodGet, "/auth/v1/private/me", "attacker-chosen-session-id", nil),
+		http.StatusUnauthorized, "invalid_token")
+}
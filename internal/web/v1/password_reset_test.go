@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestForgotPasswordSameResponseForUnknownEmail(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{PasswordResetBackupEmail: true}, Options{})
+	s.addTestUser(t, "alice")
+
+	known := s.do(t, http.MethodPost, "/auth/v1/public/forgot-password", "",
+		map[string]string{"email": "alice@example.com"})
+	unknown := s.do(t, http.MethodPost, "/auth/v1/public/forgot-password", "",
+		map[string]string{"email": "nobody@example.com"})
+
+	if known.Code != http.StatusOK || unknown.Code != http.StatusOK {
+		t.Fatalf("status = %d (known), %d (unknown), want 200 for both", known.Code, unknown.Code)
+	}
+	if known.Body.String() != unknown.Body.String() {
+		t.Errorf("bodies differ:\nknown:   %s\nunknown: %s", known.Body, unknown.Body)
+	}
+	s.repos.Notifier.WaitFor(t, "password_reset", "alice@example.com")
+}
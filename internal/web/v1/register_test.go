@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestRegisterRejectsOverlongPassword(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+
+	w := s.do(t, http.MethodPost, "/auth/v1/public/register", "", map[string]string{
+		"username": "alice",
+		"email":    "alice@example.com",
+		"password": strings.Repeat("a", 73),
+	})
+	assertError(t, w, http.StatusBadRequest, "weak_password")
+
+	violations, _ := decodeJSON(t, w)["violations"].([]any)
+	if len(violations) != 1 || violations[0].(map[string]any)["rule"] != "max_length" {
+		t.Errorf("violations = %v, want one max_length violation", violations)
+	}
+}
+
+func TestRegisterUsernameCaseCollision(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	s.addTestUser(t, "alice")
+
+	w := s.do(t, http.MethodPost, "/auth/v1/public/register", "", map[string]string{
+		"username": "Alice",
+		"email":    "someone.else@example.com",
+		"password": testPassword,
+	})
+	assertError(t, w, http.StatusConflict, "username_exists")
+}
@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequireRole returns middleware that authenticates the bearer token like
+// AuthMiddleware and lets the request through only when the user holds role.
+// Missing or invalid tokens get 401 and other roles get 403. Handlers behind it
+// read the user with CurrentUser.
+func (h *Handler) RequireRole(role domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, span := middleware.StartSpan(c.Request.Context(), "http.require_role", trace.WithAttributes(
+			attribute.String("layer", "web"),
+			attribute.String("role.required", string(role)),
+		))
+		defer span.End()
+
+		user, ok := h.authenticate(ctx, c, span)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		if err := h.auth.AuthorizeRole(ctx, user, role); err != nil {
+			span.RecordError(err)
+
+			h.respondError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// APIKeyHeader carries the gateway API key accepted by token introspection.
+const APIKeyHeader = "X-API-Key"
+
+// requireIntrospectionCaller lets a request through when it carries the
+// introspection API key in X-API-Key, or otherwise an admin's bearer token (see
+// RequireRole). A wrong key is rejected outright rather than falling back.
+// Key callers spend the API key's own budget (Options.IntrospectionRateLimit).
+func (h *Handler) requireIntrospectionCaller() gin.HandlerFunc {
+	requireAdmin := h.RequireRole(domain.RoleAdmin)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			requireAdmin(c)
+			return
+		}
+
+		if h.opts.IntrospectionAPIKey == "" ||
+			subtle.ConstantTimeCompare([]byte(key), []byte(h.opts.IntrospectionAPIKey)) != 1 {
+			h.writeError(c, http.StatusUnauthorized, "invalid_api_key", "Invalid API key", nil)
+			c.Abort()
+			return
+		}
+		if !h.introspectionLimiter.Allow(c, key) {
+			h.writeError(c, http.StatusTooManyRequests, "too_many_requests", "Too many requests, try again later", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+// testAPIKey is the introspection API key of tests.
+var testAPIKey = strings.Repeat("k", 32)
+
+func TestIntrospectionRateLimitPerAPIKey(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{
+		IntrospectionAPIKey:          testAPIKey,
+		IntrospectionRateLimit:       2,
+		IntrospectionRateLimitWindow: time.Minute,
+		UserRateLimit:                1,
+		UserRateLimitWindow:          time.Minute,
+	})
+	s.addTestUser(t, "alice")
+	token := s.login(t, "alice")
+	body := map[string]string{"token": token}
+
+	for i := range 2 {
+		w := s.do(t, http.MethodPost, "/auth/v1/private/introspect", "", body, APIKeyHeader, testAPIKey)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 (body %s)", i+1, w.Code, w.Body.String())
+		}
+		if active := decodeJSON(t, w)["active"]; active != true {
+			t.Fatalf("request %d: active = %v, want true", i+1, active)
+		}
+	}
+
+	w := s.do(t, http.MethodPost, "/auth/v1/private/introspect", "", body, APIKeyHeader, testAPIKey)
+	assertError(t, w, http.StatusTooManyRequests, "too_many_requests")
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After header missing")
+	}
+
+	// Introspecting alice's token twice did not spend alice's own budget of 1,
+	// and the exhausted key budget does not throttle her.
+	if w := s.do(t, http.MethodGet, "/auth/v1/private/me", token, nil); w.Code != http.StatusOK {
+		t.Errorf("alice /me: status = %d, want 200 (body %s)", w.Code, w.Body.String())
+	}
+	assertError(t, s.do(t, http.MethodGet, "/auth/v1/private/me", token, nil),
+		http.StatusTooManyRequests, "too_many_requests")
+}
+
+func TestIntrospectionRejectsWrongAPIKey(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{
+		IntrospectionAPIKey:          testAPIKey,
+		IntrospectionRateLimit:       1,
+		IntrospectionRateLimitWindow: time.Minute,
+	})
+	body := map[string]string{"token": "x"}
+
+	for range 3 {
+		w := s.do(t, http.MethodPost, "/auth/v1/private/introspect", "", body, APIKeyHeader, strings.Repeat("x", 32))
+		assertError(t, w, http.StatusUnauthorized, "invalid_api_key")
+	}
+	// Wrong keys never spent the real key's budget.
+	if w := s.do(t, http.MethodPost, "/auth/v1/private/introspect", "", body, APIKeyHeader, testAPIKey); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 (body %s)", w.Code, w.Body.String())
+	}
+}
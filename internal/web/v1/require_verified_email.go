@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail returns middleware that lets the request through only when
+// the authenticated user has confirmed their email address; others get 403 with
+// code email_not_verified. It reads the user stored by AuthMiddleware or
+// RequireRole, so it must be mounted after one of them.
+//
+// Usage:
+//
+//	r.POST("/auth/v1/public/2fa/enroll", h.AuthMiddleware(), h.RequireVerifiedEmail(), h.EnrollTOTP)
+func (h *Handler) RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil {
+			h.writeError(c, http.StatusUnauthorized, "unauthorized", "Authorization header required", nil)
+			c.Abort()
+			return
+		}
+
+		if !user.EmailVerified {
+			h.writeError(c, http.StatusForbidden, "email_not_verified", "Verify your email address first", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	h := NewHandler(s.auth, Options{})
+	s.router.GET("/test/verified-only", h.AuthMiddleware(), h.RequireVerifiedEmail(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	verified := s.addTestUser(t, "alice")
+	if err := s.repos.Users.MarkEmailVerified(context.Background(), verified.ID); err != nil {
+		t.Fatal(err)
+	}
+	s.addTestUser(t, "bob")
+
+	if w := s.do(t, http.MethodGet, "/test/verified-only", s.login(t, "alice"), nil); w.Code != http.StatusNoContent {
+		t.Errorf("verified user: status = %d, want %d (body %s)", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	assertError(t, s.do(t, http.MethodGet, "/test/verified-only", s.login(t, "bob"), nil),
+		http.StatusForbidden, "email_not_verified")
+}
+
+func TestRequireVerifiedEmailWithoutAuthentication(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	h := NewHandler(s.auth, Options{})
+	s.router.GET("/test/unauthenticated", h.RequireVerifiedEmail(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	assertError(t, s.do(t, http.MethodGet, "/test/unauthenticated", "", nil), http.StatusUnauthorized, "unauthorized")
+}
+
+func TestEnrollTOTPRequiresVerifiedEmail(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{TOTPEncryptionKey: make([]byte, 32)}, Options{})
+	s.addTestUser(t, "bob")
+
+	assertError(t, s.do(t, http.MethodPost, "/auth/v1/public/2fa/enroll", s.login(t, "bob"), nil),
+		http.StatusForbidden, "email_not_verified")
+}
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Response shapes. Handlers never call c.JSON directly; they go through respond
+// and writeError so RESPONSE_ENVELOPE applies uniformly to every endpoint. The only
+// exception is respondUnwrapped, for bodies defined by an external spec.
+//
+//	bare (default):  success → <body>            error → {"error": "<message>", "code": "<code>", ...}
+//	envelope:        success → {"data": <body>}  error → {"error": {"message": "<message>", "code": "<code>", ...}}
+//
+// Errors honor a minimal Accept negotiation: clients that prefer text/plain (e.g.,
+// monitoring probes) get "<message> (<code>)" as plain text instead. JSON stays
+// the default for missing, wildcard and browser Accept headers.
+
+// respond writes a successful JSON response.
+func (h *Handler) respond(c *gin.Context, status int, body any) {
+	if h.opts.ResponseEnvelope {
+		c.JSON(status, gin.H{"data": body})
+		return
+	}
+	c.JSON(status, body)
+}
+
+// respondUnwrapped writes a successful JSON response whose shape is fixed by an
+// external spec (e.g., RFC 7662 introspection), so RESPONSE_ENVELOPE never applies.
+func (h *Handler) respondUnwrapped(c *gin.Context, status int, body any) {
+	c.JSON(status, body)
+}
+
+// writeError writes an error JSON response. extra carries additional
+// machine-readable fields (e.g., password policy violations) and may be nil;
+// it is dropped from plain-text responses.
+func (h *Handler) writeError(c *gin.Context, status int, code, message string, extra gin.H) {
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain) == gin.MIMEPlain {
+		c.String(status, "%s (%s)\n", message, code)
+		return
+	}
+
+	body := gin.H{"code": code}
+	for k, v := range extra {
+		body[k] = v
+	}
+
+	if h.opts.ResponseEnvelope {
+		body["message"] = message
+		c.JSON(status, gin.H{"error": body})
+		return
+	}
+	body["error"] = message
+	c.JSON(status, body)
+}
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestResponseEnvelope(t *testing.T) {
+	for _, envelope := range []bool{false, true} {
+		name := "bare"
+		if envelope {
+			name = "envelope"
+		}
+		t.Run(name, func(t *testing.T) {
+			s := newTestServer(t, logicv1.Options{PasswordPolicy: logicv1.PasswordPolicy{MinLength: 12}},
+				Options{ResponseEnvelope: envelope, IntrospectionAPIKey: testAPIKey})
+			row := s.addTestUser(t, "alice")
+			token := s.login(t, "alice")
+
+			t.Run("success", func(t *testing.T) {
+				w := s.do(t, http.MethodGet, "/auth/v1/private/me", token, nil)
+				if w.Code != http.StatusOK {
+					t.Fatalf("status = %d, want 200 (body %s)", w.Code, w.Body.String())
+				}
+				body := decodeJSON(t, w)
+				if envelope {
+					data, ok := body["data"].(map[string]any)
+					if !ok {
+						t.Fatalf("no data object in %s", w.Body.String())
+					}
+					body = data
+				}
+				if body["id"] != row.PublicID {
+					t.Errorf("id = %v, want %s (body %s)", body["id"], row.PublicID, w.Body.String())
+				}
+			})
+
+			t.Run("error", func(t *testing.T) {
+				w := s.do(t, http.MethodPost, "/auth/v1/public/register", "", map[string]string{
+					"username": "bob", "email": "bob@example.com", "password": "too-short",
+				})
+				if w.Code != http.StatusBadRequest {
+					t.Fatalf("status = %d, want 400 (body %s)", w.Code, w.Body.String())
+				}
+				body := decodeJSON(t, w)
+				message := body["error"]
+				if envelope {
+					errBody, ok := body["error"].(map[string]any)
+					if !ok {
+						t.Fatalf("no error object in %s", w.Body.String())
+					}
+					body, message = errBody, errBody["message"]
+				}
+				if body["code"] != "weak_password" {
+					t.Errorf("code = %v, want weak_password (body %s)", body["code"], w.Body.String())
+				}
+				if message != "Password does not meet policy" {
+					t.Errorf("message = %v (body %s)", message, w.Body.String())
+				}
+				if _, ok := body["violations"]; !ok {
+					t.Errorf("violations missing next to the code (body %s)", w.Body.String())
+				}
+			})
+
+			t.Run("unwrapped introspection", func(t *testing.T) {
+				w := s.do(t, http.MethodPost, "/auth/v1/private/introspect", "",
+					map[string]string{"token": token}, APIKeyHeader, testAPIKey)
+				if w.Code != http.StatusOK {
+					t.Fatalf("status = %d, want 200 (body %s)", w.Code, w.Body.String())
+				}
+				body := decodeJSON(t, w)
+				if _, wrapped := body["data"]; wrapped {
+					t.Errorf("introspection response wrapped: %s", w.Body.String())
+				}
+				if body["active"] != true {
+					t.Errorf("active = %v, want true (body %s)", body["active"], w.Body.String())
+				}
+			})
+		})
+	}
+}
+
+func TestErrorContentNegotiation(t *testing.T) {
+	tests := []struct {
+		name      string
+		accept    string
+		wantPlain bool
+	}{
+		{"no Accept", "", false},
+		{"wildcard", "*/*", false},
+		{"JSON", "application/json", false},
+		{"browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", false},
+		{"plain text", "text/plain", true},
+		{"plain text preferred", "text/plain, application/json;q=0.5", true},
+	}
+
+	for _, envelope := range []bool{false, true} {
+		s := newTestServer(t, logicv1.Options{}, Options{ResponseEnvelope: envelope})
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/envelope=%v", tt.name, envelope), func(t *testing.T) {
+				var headers []string
+				if tt.accept != "" {
+					headers = []string{"Accept", tt.accept}
+				}
+				w := s.do(t, http.MethodGet, "/auth/v1/private/me", "", nil, headers...)
+				if w.Code != http.StatusUnauthorized {
+					t.Fatalf("status = %d, want 401 (body %s)", w.Code, w.Body.String())
+				}
+
+				contentType := w.Header().Get("Content-Type")
+				if !tt.wantPlain {
+					if !strings.HasPrefix(contentType, "application/json") {
+						t.Errorf("Content-Type = %q, want JSON", contentType)
+					}
+					decodeJSON(t, w)
+					return
+				}
+				if !strings.HasPrefix(contentType, "text/plain") {
+					t.Errorf("Content-Type = %q, want text/plain", contentType)
+				}
+				if body := w.Body.String(); !strings.HasSuffix(body, " (unauthorized)\n") || strings.ContainsAny(body, "{}") {
+					t.Errorf("body = %q, want \"<message> (unauthorized)\"", body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+)
+
+func TestMethodNotAllowedAllowHeader(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+
+	tests := []struct {
+		method, path string
+		wantAllow    string
+	}{
+		{http.MethodGet, "/auth/v1/public/login", http.MethodPost},
+		{http.MethodPost, "/auth/v1/private/me", http.MethodGet},
+		{http.MethodGet, "/auth/v1/public/me", http.MethodPatch},
+		{http.MethodPost, "/auth/v1/public/account", http.MethodDelete},
+		{http.MethodPost, "/auth/v1/public/sessions/0b8e6c1e-3f0a-4a57-9b7e-2d1c0f7e4a11", http.MethodDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := s.do(t, tt.method, tt.path, "", nil)
+			assertError(t, w, http.StatusMethodNotAllowed, "method_not_allowed")
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", allow, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestUnknownRoutes(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{ResponseEnvelope: true})
+
+	w := s.do(t, http.MethodGet, "/auth/v1/public/nope", "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404 (body %s)", w.Code, w.Body.String())
+	}
+	errBody, _ := decodeJSON(t, w)["error"].(map[string]any)
+	if errBody["code"] != "not_found" {
+		t.Errorf("404 in envelope mode = %s, want the not_found error object", w.Body.String())
+	}
+
+	// A trailing slash redirects to the route instead of a 404
+	w = s.do(t, http.MethodGet, "/auth/v1/private/me/", "", nil)
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("trailing slash: status = %d, want %d", w.Code, http.StatusMovedPermanently)
+	}
+	if loc := w.Header().Get("Location"); loc != "/auth/v1/private/me" {
+		t.Errorf("trailing slash: Location = %q, want /auth/v1/private/me", loc)
+	}
+}
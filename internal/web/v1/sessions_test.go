@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	"github.com/google/uuid"
+)
+
+func TestDeleteSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		requester  string // user revoking bob's second session
+		sessionID  string // overrides bob's session ID when set
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "owner deletes own session", requester: "bob", wantStatus: http.StatusNoContent},
+		{name: "other user is rejected", requester: "mallory", wantStatus: http.StatusForbidden, wantCode: "forbidden"},
+		{name: "admin overrides ownership", requester: "admin", wantStatus: http.StatusNoContent},
+		{name: "unknown session", requester: "bob", sessionID: uuid.NewString(),
+			wantStatus: http.StatusNotFound, wantCode: "session_not_found"},
+		{name: "malformed session ID", requester: "bob", sessionID: "42",
+			wantStatus: http.StatusNotFound, wantCode: "session_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, logicv1.Options{}, Options{})
+			s.addTestUser(t, "bob")
+			s.addTestUser(t, "mallory")
+			admin := s.addTestUser(t, "admin")
+			if err := s.repos.Users.SetRole(context.Background(), admin.ID, domain.RoleAdmin); err != nil {
+				t.Fatalf("set role: %v", err)
+			}
+
+			bobToken := s.login(t, "bob")
+			target := s.loginResponse(t, "bob")
+			token := bobToken
+			if tt.requester != "bob" {
+				token = s.login(t, tt.requester)
+			}
+
+			sessionID := target.SessionID
+			if tt.sessionID != "" {
+				sessionID = tt.sessionID
+			}
+
+			w := s.do(t, http.MethodDelete, "/auth/v1/public/sessions/"+sessionID, token, nil)
+			if tt.wantCode != "" {
+				assertError(t, w, tt.wantStatus, tt.wantCode)
+			} else if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			// The target session is gone exactly when the revocation succeeded.
+			status := s.do(t, http.MethodGet, "/auth/v1/private/me", target.Token, nil).Code
+			revoked := tt.wantStatus == http.StatusNoContent
+			if revoked != (status == http.StatusUnauthorized) {
+				t.Errorf("target session: /me status = %d after revocation status %d", status, w.Code)
+			}
+			// Bob's other session is never touched.
+			if code := s.do(t, http.MethodGet, "/auth/v1/private/me", bobToken, nil).Code; code != http.StatusOK {
+				t.Errorf("other session: /me status = %d, want 200", code)
+			}
+		})
+	}
+}
+
+func TestAuthResponseSessionIDIsListed(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	s.addTestUser(t, "alice")
+
+	responses := map[string]map[string]any{
+		"register": decodeJSON(t, s.do(t, http.MethodPost, "/auth/v1/public/register", "",
+			map[string]string{"username": "bob", "email": "bob@example.com", "password": testPassword})),
+		"login": decodeJSON(t, s.do(t, http.MethodPost, "/auth/v1/public/login", "",
+			map[string]string{"username": "alice", "password": testPassword})),
+	}
+	for name, body := range responses {
+		t.Run(name, func(t *testing.T) {
+			sessionID, _ := body["session_id"].(string)
+			if uuid.Validate(sessionID) != nil {
+				t.Fatalf("session_id = %#v, want a UUID", body["session_id"])
+			}
+			token, _ := body["token"].(string)
+			if sessionID == token {
+				t.Error("session_id is the token")
+			}
+
+			var listed []string
+			sessions, _ := decodeJSON(t, s.do(t, http.MethodGet, "/auth/v1/public/sessions", token, nil))["sessions"].([]any)
+			for _, session := range sessions {
+				session, _ := session.(map[string]any)
+				listed = append(listed, session["id"].(string))
+			}
+			if len(listed) != 1 || listed[0] != sessionID {
+				t.Errorf("listed sessions = %v, want only %s", listed, sessionID)
+			}
+		})
+	}
+}
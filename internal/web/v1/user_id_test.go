@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	"github.com/google/uuid"
+)
+
+func TestUserIDSerialization(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	admin := s.addTestUser(t, "admin")
+	if err := s.repos.Users.SetRole(context.Background(), admin.ID, domain.RoleAdmin); err != nil {
+		t.Fatalf("set role: %v", err)
+	}
+	adminToken := s.login(t, "admin")
+
+	w := s.do(t, http.MethodPost, "/auth/v1/public/register", "",
+		map[string]string{"username": "carol", "email": "carol@example.com", "password": testPassword})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: status = %d, want 201 (body %s)", w.Code, w.Body.String())
+	}
+	registered, _ := decodeJSON(t, w)["user"].(map[string]any)
+	want, ok := registered["id"].(string)
+	if !ok || want == "" {
+		t.Fatalf("register: user.id = %#v, want a non-empty JSON string", registered["id"])
+	}
+
+	w = s.do(t, http.MethodPost, "/auth/v1/public/login", "",
+		map[string]string{"username": "carol", "password": testPassword})
+	login := decodeJSON(t, w)
+	token, _ := login["token"].(string)
+	loginUser, _ := login["user"].(map[string]any)
+
+	var listed any
+	users, _ := decodeJSON(t, s.do(t, http.MethodGet, "/auth/v1/admin/users", adminToken, nil))["users"].([]any)
+	for _, u := range users {
+		if u, _ := u.(map[string]any); u["username"] == "carol" {
+			listed = u["id"]
+		}
+	}
+
+	endpoints := map[string]any{
+		"login":      loginUser["id"],
+		"me":         decodeJSON(t, s.do(t, http.MethodGet, "/auth/v1/private/me", token, nil))["id"],
+		"introspect": decodeJSON(t, s.do(t, http.MethodPost, "/auth/v1/private/introspect", adminToken, map[string]string{"token": token}))["sub"],
+		"admin list": listed,
+	}
+	for name, got := range endpoints {
+		if got != want {
+			t.Errorf("%s: id = %#v, want the string %q from register", name, got, want)
+		}
+	}
+}
+
+func TestResponsesOmitSerialID(t *testing.T) {
+	s := newTestServer(t, logicv1.Options{}, Options{})
+	admin := s.addTestUser(t, "admin")
+	alice := s.addTestUser(t, "alice")
+	if err := s.repos.Users.SetRole(context.Background(), admin.ID, domain.RoleAdmin); err != nil {
+		t.Fatalf("set role: %v", err)
+	}
+	adminToken := s.login(t, "admin")
+	aliceToken := s.login(t, "alice")
+
+	responses := map[string]map[string]any{
+		"register": decodeJSON(t, s.do(t, http.MethodPost, "/auth/v1/public/register", "",
+			map[string]string{"username": "carol", "email": "carol@example.com", "password": testPassword})),
+		"login": decodeJSON(t, s.do(t, http.MethodPost, "/auth/v1/public/login", "",
+			map[string]string{"username": "alice", "password": testPassword})),
+		"me":         decodeJSON(t, s.do(t, http.MethodGet, "/auth/v1/private/me", aliceToken, nil)),
+		"introspect": decodeJSON(t, s.do(t, http.MethodPost, "/auth/v1/private/introspect", adminToken, map[string]string{"token": aliceToken})),
+		"admin list": decodeJSON(t, s.do(t, http.MethodGet, "/auth/v1/admin/users", adminToken, nil)),
+	}
+	for name, body := range responses {
+		assertPublicIDs(t, name, body)
+	}
+
+	// Admin paths take the public ID; the serial ID matches no user
+	if w := s.do(t, http.MethodPost, "/auth/v1/admin/users/"+alice.PublicID+"/unlock", adminToken, nil); w.Code != http.StatusNoContent {
+		t.Errorf("unlock by public ID: status = %d, want 204 (body %s)", w.Code, w.Body.String())
+	}
+	assertError(t, s.do(t, http.MethodPost, "/auth/v1/admin/users/"+strconv.Itoa(alice.ID)+"/unlock", adminToken, nil),
+		http.StatusNotFound, "user_not_found")
+}
+
+// assertPublicIDs fails unless every user ID field in v, at any depth, is a UUID string.
+func assertPublicIDs(t *testing.T, path string, v any) {
+	t.Helper()
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch key {
+			case "internal_id":
+				t.Errorf("%s.%s: serial ID serialized", path, key)
+			case "id", "sub", "user_id":
+				if id, ok := value.(string); !ok || uuid.Validate(id) != nil {
+					t.Errorf("%s.%s = %#v, want a UUID string", path, key, value)
+				}
+			default:
+				assertPublicIDs(t, path+"."+key, value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			assertPublicIDs(t, path+"["+strconv.Itoa(i)+"]", value)
+		}
+	}
+}
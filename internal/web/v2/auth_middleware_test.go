@@ -0,0 +1,105 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	"github.com/duynhne/auth-service/internal/core/repository/memory"
+	logicv1 "github.com/duynhne/auth-service/internal/logic/v1"
+	logicv2 "github.com/duynhne/auth-service/internal/logic/v2"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testTokenSecret = "test-secret-test-secret-test-secret-00"
+	testPassword    = "correct-horse-battery"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repos := memory.New()
+	tokens := logicv1.NewTokenIssuer(testTokenSecret, time.Hour)
+	auth := logicv1.NewAuthService(logicv1.Repositories{
+		Users:         repos.Users,
+		Sessions:      repos.Sessions,
+		Invites:       repos.Invites,
+		Resets:        repos.Resets,
+		Verifications: repos.Verifications,
+		Tokens:        repos.Tokens,
+		EmailChanges:  repos.EmailChanges,
+		TOTP:          repos.TOTP,
+		BackupCodes:   repos.BackupCodes,
+		RefreshTokens: repos.RefreshTokens,
+	}, tokens, repos.Notifier, logicv1.Options{BcryptCost: bcrypt.MinCost})
+	r := gin.New()
+	NewHandler(logicv2.NewAuthService(auth, tokens), Options{}).RegisterRoutes(r)
+
+	ctx := context.Background()
+	alice := repos.Users.AddUser(t, "alice", "alice@example.com", testPassword, bcrypt.MinCost)
+	result, err := auth.Login(ctx, domain.LoginRequest{Username: "alice", Password: testPassword}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	// A correctly signed token whose exp has passed, for a session that still exists
+	expired, claims, err := tokens.IssueWithTTL(alice.PublicID, string(alice.Role), -time.Minute)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if _, err := repos.Sessions.Create(ctx, domain.NewSession{
+		UserID: alice.ID, Token: claims.ID, ExpiresAt: time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing token", "", http.StatusUnauthorized, "unauthorized"},
+		{"not a bearer token", "Basic YWxpY2U6c2VjcmV0", http.StatusUnauthorized, "unauthorized"},
+		{"expired token", "Bearer " + expired, http.StatusUnauthorized, "session_expired"},
+		{"valid token", "Bearer " + result.Session.Token, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/auth/v2/private/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var body struct {
+				Data  *domain.User `json:"data"`
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body %s: %v", w.Body.String(), err)
+			}
+			if tt.wantCode != "" {
+				if body.Error.Code != tt.wantCode {
+					t.Errorf("code = %q, want %q", body.Error.Code, tt.wantCode)
+				}
+				return
+			}
+			if body.Data == nil || body.Data.ID != alice.PublicID {
+				t.Errorf("data = %+v, want alice (%s)", body.Data, alice.PublicID)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package v2
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	logicv2 "github.com/duynhne/auth-service/internal/logic/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// errorMapping describes how a Logic-layer error is rendered over HTTP.
+type errorMapping struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// errorMappings translates the sentinels v2 endpoints can return. Codes match
+// v1 so clients can share their error handling. The first matching entry wins.
+var errorMappings = []errorMapping{
+	{logicv2.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials"},
+	// Don't reveal that user doesn't exist (security best practice)
+	{logicv2.ErrUserNotFound, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials"},
+	{logicv2.ErrPasswordExpired, http.StatusForbidden, "password_expired", "Password expired"},
+	{logicv2.ErrAccountLocked, http.StatusForbidden, "account_locked", "Account locked"},
+	{logicv2.ErrSessionNotFound, http.StatusUnauthorized, "invalid_token", "Invalid or expired token"},
+	{logicv2.ErrInvalidToken, http.StatusUnauthorized, "invalid_token", "Invalid or expired token"},
+	{logicv2.ErrSessionExpired, http.StatusUnauthorized, "session_expired", "Session expired"},
+	{logicv2.ErrSessionBindingMismatch, http.StatusUnauthorized, "invalid_token", "Invalid or expired token"},
+	{logicv2.ErrInvalidTOTPCode, http.StatusUnauthorized, "invalid_totp_code", "Invalid authentication code"},
+	{logicv2.ErrTwoFactorUnavailable, http.StatusNotImplemented, "two_factor_unavailable",
+		"Two-factor authentication is not available"},
+	{logicv2.ErrTooManyRequests, http.StatusTooManyRequests, "too_many_requests", "Too many requests, try again later"},
+	{logicv2.ErrServiceUnavailable, http.StatusServiceUnavailable, "service_unavailable",
+		"Service temporarily unavailable, try again later"},
+}
+
+// serviceUnavailableRetryAfter is the Retry-After (seconds) sent with 503s.
+const serviceUnavailableRetryAfter = "1"
+
+// respondError maps err to its structured error response, falling back to 500.
+func respondError(c *gin.Context, err error) {
+	m := errorMapping{nil, http.StatusInternalServerError, "internal_error", "Internal server error"}
+	for _, candidate := range errorMappings {
+		if errors.Is(err, candidate.err) {
+			m = candidate
+			break
+		}
+	}
+
+	var retryErr *logicv2.RetryAfterError
+	if errors.As(err, &retryErr) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+	}
+	if errors.Is(err, logicv2.ErrServiceUnavailable) {
+		c.Header("Retry-After", serviceUnavailableRetryAfter)
+	}
+
+	writeError(c, m.status, m.code, m.message, nil)
+}
+
+// respond writes a successful response in the v2 envelope.
+func respond(c *gin.Context, status int, body any) {
+	c.JSON(status, gin.H{"data": body})
+}
+
+// writeError writes a structured error. extra carries additional
+// machine-readable fields and may be nil.
+func writeError(c *gin.Context, status int, code, message string, extra gin.H) {
+	body := gin.H{"code": code, "message": message, "status": status}
+	for k, v := range extra {
+		body[k] = v
+	}
+	c.JSON(status, gin.H{"error": body})
+}
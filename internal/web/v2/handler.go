@@ -0,0 +1,138 @@
+// Package v2 provides the HTTP handlers for auth API version 2.
+//
+// Response shapes: v2 always uses the envelope, independent of RESPONSE_ENVELOPE:
+//
+//	success → {"data": <body>}
+//	error   → {"error": {"code": "<code>", "message": "<message>", "status": <http status>, ...}}
+//
+// Routes are mounted under /auth/v2/{audience}/… next to the v1 routes.
+package v2
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/duynhne/auth-service/internal/core/domain"
+	logicv2 "github.com/duynhne/auth-service/internal/logic/v2"
+	"github.com/duynhne/auth-service/middleware"
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeviceIDHeader carries the client's device identifier, as in v1.
+const DeviceIDHeader = "X-Device-ID"
+
+// Handler groups HTTP handlers for the auth API v2.
+type Handler struct {
+	auth *logicv2.AuthService
+	opts Options
+}
+
+// Options holds HTTP settings for Handler.
+type Options struct {
+	// RateLimit is the per-IP budget of RateLimitWindow for v2 login. It is separate
+	// from the v1 budget; the per-username login backoff is shared. 0 disables it.
+	RateLimit       int
+	RateLimitWindow time.Duration
+}
+
+// NewHandler creates a new Handler with the given AuthService.
+func NewHandler(auth *logicv2.AuthService, opts Options) *Handler {
+	return &Handler{auth: auth, opts: opts}
+}
+
+// RegisterRoutes mounts auth v2 routes.
+func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	rateLimit := middleware.RateLimitMiddlewareWithReject(h.opts.RateLimit, h.opts.RateLimitWindow, func(c *gin.Context) {
+		writeError(c, http.StatusTooManyRequests, "too_many_requests", "Too many requests, try again later", nil)
+		c.Abort()
+	})
+
+	r.POST("/auth/v2/public/login", rateLimit, h.Login)
+	r.GET("/auth/v2/private/me", h.AuthMiddleware(), h.GetMe)
+}
+
+// Login handles HTTP request for user login.
+// POST /auth/v2/public/login
+// Body: same as v1 login. Responds with the token's type, issue and expiry times.
+func (h *Handler) Login(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	logger := pkgzerolog.FromContext(ctx)
+
+	var req domain.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		writeError(c, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	result, err := h.auth.Login(ctx, req, clientInfo(c))
+	if err != nil {
+		span.RecordError(err)
+		logger.Error().Err(err).Str("username", req.Username).Msg("Login failed")
+
+		respondError(c, err)
+		return
+	}
+
+	switch result.Next {
+	case logicv2.StepNone:
+		logger.Info().Str("user_id", result.Session.User.ID).Msg("User logged in")
+		respond(c, http.StatusOK, result.Session)
+	case logicv2.StepTOTP:
+		writeError(c, http.StatusUnauthorized, "two_factor_required", "Two-factor code required",
+			gin.H{"two_factor_required": true})
+	default:
+		logger.Error().Str("next_step", string(result.Next)).Msg("Unhandled login step")
+		writeError(c, http.StatusInternalServerError, "internal_error", "Internal server error", nil)
+	}
+}
+
+// GetMe handles HTTP request to get the current user from the bearer token.
+// GET /auth/v2/private/me
+// Authorization: Bearer <token> (checked by AuthMiddleware)
+func (h *Handler) GetMe(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	user := CurrentUser(c)
+
+	pkgzerolog.FromContext(ctx).Info().Str("user_id", user.ID).Msg("Token validated")
+	respond(c, http.StatusOK, user)
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+// On failure it writes a 401 response and returns false.
+func bearerToken(c *gin.Context, span trace.Span) (string, bool) {
+	const bearerPrefix = "Bearer "
+	authHeader := c.GetHeader("Authorization")
+	if len(authHeader) <= len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
+		span.SetAttributes(attribute.Bool("auth.present", authHeader != ""))
+		writeError(c, http.StatusUnauthorized, "unauthorized", "Bearer token required", nil)
+		return "", false
+	}
+
+	span.SetAttributes(attribute.Bool("auth.present", true))
+	return authHeader[len(bearerPrefix):], true
+}
+
+// clientInfo captures the request attributes used for session binding.
+func clientInfo(c *gin.Context) domain.ClientInfo {
+	return domain.ClientInfo{
+		DeviceID: c.GetHeader(DeviceIDHeader),
+		IP:       c.ClientIP(),
+	}
+}
@@ -0,0 +1,53 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoginRateLimitUsesV2ErrorShape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewHandler(nil, Options{RateLimit: 1, RateLimitWindow: time.Minute}).RegisterRoutes(r)
+
+	login := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/auth/v2/public/login", strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The first request spends the budget and fails validation before reaching the service
+	if w := login(); w.Code != http.StatusBadRequest {
+		t.Fatalf("first login: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w := login()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second login: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+			Status  int    `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %s: %v", w.Body.String(), err)
+	}
+	if body.Error.Code != "too_many_requests" || body.Error.Status != http.StatusTooManyRequests || body.Error.Message == "" {
+		t.Errorf("429 body = %s, want the v2 error envelope", w.Body.String())
+	}
+}
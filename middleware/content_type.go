@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONContentType is the Content-Type of every JSON response.
+const JSONContentType = "application/json; charset=utf-8"
+
+// contentTypeWriter sets the default Content-Type just before the headers are
+// sent, so handlers that write a body without one still honor the contract.
+type contentTypeWriter struct {
+	gin.ResponseWriter
+}
+
+func (w *contentTypeWriter) ensureContentType() {
+	if w.Written() {
+		return
+	}
+	switch w.Status() {
+	case http.StatusNoContent, http.StatusNotModified:
+		return
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", JSONContentType)
+	}
+}
+
+func (w *contentTypeWriter) WriteHeaderNow() {
+	w.ensureContentType()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *contentTypeWriter) Write(b []byte) (int, error) {
+	w.ensureContentType()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *contentTypeWriter) WriteString(s string) (int, error) {
+	w.ensureContentType()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// JSONContentTypeMiddleware defaults the response Content-Type to
+// application/json; charset=utf-8 for every response that has a body, so strict
+// clients can rely on it even for endpoints that write without c.JSON.
+//
+// An explicit Content-Type always wins (e.g., text/plain errors negotiated via
+// Accept, or /metrics), and 204/304 responses are left without one.
+func JSONContentTypeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &contentTypeWriter{ResponseWriter: c.Writer}
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestJSONContentTypeMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(JSONContentTypeMiddleware())
+	r.GET("/json", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
+	r.GET("/error", func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": "invalid_token"})
+	})
+	r.GET("/raw", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+		_, _ = c.Writer.Write([]byte(`{"token":"abc"}`))
+	})
+	r.GET("/text", func(c *gin.Context) { c.String(http.StatusBadRequest, "invalid_request") })
+	r.DELETE("/empty", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	// Digest buffers the body and writes it later, after the handler returns
+	digested := r.Group("/digest", ContentDigestMiddleware())
+	digested.GET("/raw", func(c *gin.Context) { _, _ = c.Writer.WriteString(`{"ok":true}`) })
+	digested.GET("/error", func(c *gin.Context) { c.JSON(http.StatusTooManyRequests, gin.H{"code": "too_many_requests"}) })
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+		want         string
+	}{
+		{http.MethodGet, "/json", http.StatusOK, JSONContentType},
+		{http.MethodGet, "/error", http.StatusUnauthorized, JSONContentType},
+		{http.MethodGet, "/raw", http.StatusCreated, JSONContentType},
+		{http.MethodGet, "/digest/raw", http.StatusOK, JSONContentType},
+		{http.MethodGet, "/digest/error", http.StatusTooManyRequests, JSONContentType},
+		{http.MethodGet, "/text", http.StatusBadRequest, "text/plain; charset=utf-8"},
+		{http.MethodDelete, "/empty", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.want {
+				t.Errorf("Content-Type = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
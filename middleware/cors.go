@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/duynhne/auth-service/config"
+	"github.com/gin-gonic/gin"
+)
+
+// CORSMiddleware lets the allowlisted browser origins call the API cross-origin.
+//
+//   - Requests without an Origin header (servers, curl) pass through untouched.
+//   - Requests from an origin not in CORS_ALLOWED_ORIGINS are rejected with 403;
+//     the request Origin is never echoed unless it is on the list.
+//   - Preflights (OPTIONS with Access-Control-Request-Method) are answered here
+//     with 204 and never reach the routes.
+//
+// With CORS_ALLOW_CREDENTIALS=true the exact origin is returned together with
+// Access-Control-Allow-Credentials, as browsers require for cookies.
+//
+// Usage:
+//
+//	r.Use(middleware.CORSMiddleware(cfg.CORS))
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	origins := make(map[string]struct{}, len(cfg.AllowedOrigins))
+	anyOrigin := false
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+			continue
+		}
+		origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		// The response depends on Origin even when it is rejected
+		c.Writer.Header().Add("Vary", "Origin")
+
+		_, listed := origins[strings.ToLower(origin)]
+		if !listed && !anyOrigin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Origin not allowed",
+				"code":  "origin_not_allowed",
+			})
+			return
+		}
+
+		if listed {
+			c.Header("Access-Control-Allow-Origin", origin)
+		} else {
+			c.Header("Access-Control-Allow-Origin", "*")
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+		c.Next()
+	}
+}
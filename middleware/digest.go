@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentDigestHeader carries the SHA-256 digest of the response body (RFC 9530).
+const ContentDigestHeader = "Content-Digest"
+
+// digestWriter buffers the response body so its digest can be sent as a header
+// before the body itself is written.
+type digestWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *digestWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *digestWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// ContentDigestMiddleware emits a Content-Digest header with the SHA-256 of the
+// response body so clients can verify response integrity.
+// Format: Content-Digest: sha-256=:<base64>:
+//
+// The whole body is buffered in memory before being sent, so this is opt-in
+// (RESPONSE_DIGEST_ENABLED=true) and should only wrap small JSON responses.
+func ContentDigestMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dw := &digestWriter{ResponseWriter: c.Writer}
+		c.Writer = dw
+
+		c.Next()
+
+		c.Writer = dw.ResponseWriter
+
+		sum := sha256.Sum256(dw.body.Bytes())
+		c.Header(ContentDigestHeader, "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+
+		if dw.body.Len() == 0 {
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		_, _ = c.Writer.Write(dw.body.Bytes())
+	}
+}
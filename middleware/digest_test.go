@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestContentDigestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ContentDigestMiddleware())
+	r.GET("/json", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{"token": "abc"}) })
+	r.GET("/text", func(c *gin.Context) { c.String(http.StatusBadRequest, "bad request (%s)\n", "invalid_request") })
+	r.DELETE("/empty", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+	}{
+		{http.MethodGet, "/json", http.StatusCreated},
+		{http.MethodGet, "/text", http.StatusBadRequest},
+		{http.MethodDelete, "/empty", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			sum := sha256.Sum256(w.Body.Bytes())
+			want := "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+			if got := w.Header().Get(ContentDigestHeader); got != want {
+				t.Errorf("%s = %q, want %q for body %q", ContentDigestHeader, got, want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// healthCheckDuration is the latency of the latest check of each dependency,
+	// so a slowing dependency shows on dashboards before its checks start failing.
+	healthCheckDuration = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "health_check_duration_seconds",
+			Help: "Duration of the latest health check of each dependency in seconds",
+		},
+		[]string{"dependency"},
+	)
+
+	healthCheckUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "health_check_up",
+			Help: "Whether the latest health check of each dependency passed (1) or failed (0)",
+		},
+		[]string{"dependency"},
+	)
+)
+
+// HealthCheckFunc probes one dependency and returns nil when it is healthy.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthResult is the outcome of one dependency check.
+type HealthResult struct {
+	Err      error
+	Duration time.Duration
+}
+
+// HealthChecker is the registry of dependency checks behind the readiness probe.
+// Every Run updates health_check_duration_seconds and health_check_up for each
+// dependency, so scraping /metrics shows the latency of the latest probe.
+type HealthChecker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]HealthCheckFunc
+}
+
+// NewHealthChecker returns an empty registry whose checks are each bounded by
+// timeout (<= 0 leaves them bounded only by the caller's context).
+func NewHealthChecker(timeout time.Duration) *HealthChecker {
+	return &HealthChecker{timeout: timeout, checks: make(map[string]HealthCheckFunc)}
+}
+
+// Register adds the check for dependency, replacing any previous one.
+func (h *HealthChecker) Register(dependency string, check HealthCheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[dependency] = check
+}
+
+// Run runs every registered check concurrently, records its latency and
+// outcome in the gauges, and returns the results by dependency.
+func (h *HealthChecker) Run(ctx context.Context) map[string]HealthResult {
+	h.mu.RLock()
+	checks := make(map[string]HealthCheckFunc, len(h.checks))
+	for dependency, check := range h.checks {
+		checks[dependency] = check
+	}
+	h.mu.RUnlock()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]HealthResult, len(checks))
+	)
+	for dependency, check := range checks {
+		wg.Go(func() {
+			result := h.runCheck(ctx, dependency, check)
+			mu.Lock()
+			results[dependency] = result
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runCheck runs one check within the timeout and updates its gauges.
+func (h *HealthChecker) runCheck(ctx context.Context, dependency string, check HealthCheckFunc) HealthResult {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check(ctx)
+	duration := time.Since(start)
+
+	up := 1.0
+	if err != nil {
+		up = 0
+	}
+	healthCheckDuration.WithLabelValues(dependency).Set(duration.Seconds())
+	healthCheckUp.WithLabelValues(dependency).Set(up)
+
+	return HealthResult{Err: err, Duration: duration}
+}
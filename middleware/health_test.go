@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHealthCheckerUpdatesGauges(t *testing.T) {
+	h := NewHealthChecker(time.Second)
+	errDown := errors.New("connection refused")
+	down := false
+	h.Register("test_db", func(context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		if down {
+			return errDown
+		}
+		return nil
+	})
+	h.Register("test_cache", func(context.Context) error { return nil })
+
+	results := h.Run(context.Background())
+	if len(results) != 2 || results["test_db"].Err != nil || results["test_cache"].Err != nil {
+		t.Fatalf("results = %v, want both dependencies healthy", results)
+	}
+	if got := testutil.ToFloat64(healthCheckDuration.WithLabelValues("test_db")); got < 0.02 {
+		t.Errorf("test_db duration gauge = %v, want >= 0.02", got)
+	}
+	if got := testutil.ToFloat64(healthCheckUp.WithLabelValues("test_db")); got != 1 {
+		t.Errorf("test_db up gauge = %v, want 1", got)
+	}
+
+	// The next cycle overwrites the gauges with its own outcome
+	down = true
+	results = h.Run(context.Background())
+	if !errors.Is(results["test_db"].Err, errDown) {
+		t.Fatalf("test_db error = %v, want %v", results["test_db"].Err, errDown)
+	}
+	if got := testutil.ToFloat64(healthCheckUp.WithLabelValues("test_db")); got != 0 {
+		t.Errorf("test_db up gauge = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(healthCheckUp.WithLabelValues("test_cache")); got != 1 {
+		t.Errorf("test_cache up gauge = %v, want 1", got)
+	}
+}
+
+func TestHealthCheckerTimeout(t *testing.T) {
+	h := NewHealthChecker(10 * time.Millisecond)
+	h.Register("test_slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	result := h.Run(context.Background())["test_slow"]
+	if !errors.Is(result.Err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want %v", result.Err, context.DeadlineExceeded)
+	}
+	if got := testutil.ToFloat64(healthCheckDuration.WithLabelValues("test_slow")); got < 0.01 {
+		t.Errorf("duration gauge = %v, want >= 0.01", got)
+	}
+}
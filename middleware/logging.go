@@ -70,6 +70,7 @@ func LoggingMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
+		query := RedactQuery(c.Request.URL.RawQuery)
 		method := c.Request.Method
 
 		// Get or generate trace-id
@@ -103,10 +104,19 @@ func LoggingMiddleware() gin.HandlerFunc {
 			event = logger.Info()
 		}
 
+		// Headers are only logged at debug level, always redacted (never credentials)
+		if debug := logger.Debug(); debug.Enabled() {
+			debug.
+				Interface("headers", RedactHeaders(c.Request.Header)).
+				Str("path", path).
+				Msg("HTTP request headers")
+		}
+
 		// Log request/response
 		event.
 			Str("method", method).
 			Str("path", path).
+			Str("query", query).
 			Int("status", statusCode).
 			Dur("duration", duration).
 			Str("client_ip", c.ClientIP()).
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/duynhne/auth-service/config"
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsAuthMiddleware protects the metrics endpoint so only the scraper can read it.
+// Mode comes from METRICS_AUTH:
+//   - off:   no protection (local development)
+//   - token: requires "Authorization: Bearer <METRICS_AUTH_TOKEN>"
+//   - basic: requires HTTP basic auth with METRICS_AUTH_USERNAME/METRICS_AUTH_PASSWORD
+//
+// Usage:
+//
+//	r.GET("/metrics", middleware.MetricsAuthMiddleware(cfg.Metrics), gin.WrapH(promhttp.Handler()))
+func MetricsAuthMiddleware(cfg config.MetricsConfig) gin.HandlerFunc {
+	switch strings.ToLower(cfg.Auth) {
+	case "token":
+		return func(c *gin.Context) {
+			const bearerPrefix = "Bearer "
+			header := c.GetHeader("Authorization")
+			if !strings.HasPrefix(header, bearerPrefix) || !secureEqual(header[len(bearerPrefix):], cfg.AuthToken) {
+				c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				return
+			}
+			c.Next()
+		}
+	case "basic":
+		return func(c *gin.Context) {
+			user, pass, ok := c.Request.BasicAuth()
+			// Evaluate both comparisons to avoid leaking which one failed via timing
+			userOK := secureEqual(user, cfg.AuthUsername)
+			passOK := secureEqual(pass, cfg.AuthPassword)
+			if !ok || !userOK || !passOK {
+				c.Header("WWW-Authenticate", `Basic realm="metrics"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				return
+			}
+			c.Next()
+		}
+	default:
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+}
+
+// secureEqual compares secrets in constant time.
+func secureEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
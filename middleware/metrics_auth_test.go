@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duynhne/auth-service/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.MetricsConfig{AuthToken: "scrape-token", AuthUsername: "prometheus", AuthPassword: "scrape-password"}
+
+	tests := []struct {
+		name          string
+		mode          string
+		setAuth       func(*http.Request)
+		wantStatus    int
+		wantChallenge string
+	}{
+		{"off allows anonymous", "off", func(*http.Request) {}, http.StatusOK, ""},
+		{"token missing", "token", func(*http.Request) {}, http.StatusUnauthorized, `Bearer realm="metrics"`},
+		{"token wrong", "token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") },
+			http.StatusUnauthorized, `Bearer realm="metrics"`},
+		{"token as basic auth", "token", func(r *http.Request) { r.SetBasicAuth("prometheus", "scrape-token") },
+			http.StatusUnauthorized, `Bearer realm="metrics"`},
+		{"token valid", "token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer scrape-token") },
+			http.StatusOK, ""},
+		{"basic missing", "basic", func(*http.Request) {}, http.StatusUnauthorized, `Basic realm="metrics"`},
+		{"basic wrong password", "basic", func(r *http.Request) { r.SetBasicAuth("prometheus", "nope") },
+			http.StatusUnauthorized, `Basic realm="metrics"`},
+		{"basic wrong username", "basic", func(r *http.Request) { r.SetBasicAuth("grafana", "scrape-password") },
+			http.StatusUnauthorized, `Basic realm="metrics"`},
+		{"basic valid", "basic", func(r *http.Request) { r.SetBasicAuth("prometheus", "scrape-password") },
+			http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := cfg
+			cfg.Auth = tt.mode
+			r := gin.New()
+			r.GET("/metrics", MetricsAuthMiddleware(cfg), func(c *gin.Context) { c.String(http.StatusOK, "metrics") })
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			tt.setAuth(req)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != "metrics" {
+				t.Errorf("body = %q, want the metrics", w.Body.String())
+			}
+		})
+	}
+}
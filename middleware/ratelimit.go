@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware limits each client IP to limit requests per window using
+// a token bucket: a client may burst up to limit requests, then regains one
+// request every window/limit. Rejected requests get 429 with Retry-After.
+// Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset (seconds until the bucket is full again).
+//
+// Buckets live in memory, so limits are per replica. Each call creates its own
+// store: mount one instance per route to give every endpoint its own budget.
+// A limit or window <= 0 disables limiting. The client IP is c.ClientIP(), so
+// the engine must only trust real proxies (SetTrustedProxies); otherwise a client
+// gets a fresh bucket for every X-Forwarded-For value it sends.
+//
+// Usage:
+//
+//	r.POST("/auth/v1/public/login", middleware.RateLimitMiddleware(10, time.Minute), h.Login)
+func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+	return RateLimitMiddlewareWithReject(limit, window, func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": "Too many requests, try again later",
+			"code":  "too_many_requests",
+		})
+	})
+}
+
+// RateLimitMiddlewareWithReject is RateLimitMiddleware with a custom 429 body, for
+// APIs whose error shape differs from the flat v1 one. reject runs after the
+// rate limit headers and Retry-After are set; it must write a 429 and abort.
+func RateLimitMiddlewareWithReject(limit int, window time.Duration, reject gin.HandlerFunc) gin.HandlerFunc {
+	limiter := NewRateLimiter(limit, window)
+	if limiter == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		if !limiter.Allow(c, c.ClientIP()) {
+			reject(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimiter is the token bucket limiter behind RateLimitMiddleware, keyed by
+// any string rather than the client IP. It is for limits applied once the
+// caller is known, e.g. per authenticated user or per API key, inside other
+// middleware. Like RateLimitMiddleware, buckets live in memory per replica.
+type RateLimiter struct {
+	store       *rateLimitStore
+	limitHeader string
+}
+
+// NewRateLimiter returns a limiter allowing limit requests per window for each
+// key, or nil when limit or window is <= 0. A nil *RateLimiter allows everything.
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	return &RateLimiter{store: newRateLimitStore(limit, window), limitHeader: strconv.Itoa(limit)}
+}
+
+// Allow spends one request from key's budget and reports whether the request
+// may proceed. It sets the X-RateLimit-* headers, and Retry-After when it
+// returns false; writing the 429 is left to the caller.
+func (l *RateLimiter) Allow(c *gin.Context, key string) bool {
+	if l == nil {
+		return true
+	}
+
+	remaining, retryAfter, reset := l.store.take(key, time.Now())
+
+	c.Header("X-RateLimit-Limit", l.limitHeader)
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+		return false
+	}
+	return true
+}
+
+// rateLimitBucket is the token bucket of one client.
+type rateLimitBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimitStore holds the buckets of every client seen within the last window.
+type rateLimitStore struct {
+	limit  float64
+	window time.Duration
+	rate   float64 // tokens regained per second
+
+	mu        sync.Mutex
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+func newRateLimitStore(limit int, window time.Duration) *rateLimitStore {
+	return &rateLimitStore{
+		limit:   float64(limit),
+		window:  window,
+		rate:    float64(limit) / window.Seconds(),
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+// take spends one token from key's bucket. It returns the tokens left, how long
+// to wait when no token was available (0 when allowed), and the time until the
+// bucket is full again.
+func (s *rateLimitStore) take(key string, now time.Time) (remaining int, retryAfter, reset time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &rateLimitBucket{tokens: s.limit, last: now}
+		s.buckets[key] = b
+	}
+
+	// Refill for the time elapsed since the last request, capped at the burst size
+	b.tokens = math.Min(s.limit, b.tokens+now.Sub(b.last).Seconds()*s.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		retryAfter = s.secondsFor(1 - b.tokens)
+	} else {
+		b.tokens--
+	}
+
+	return int(b.tokens), retryAfter, s.secondsFor(s.limit - b.tokens)
+}
+
+// sweepLocked drops buckets idle for a full window: they would be full again,
+// which is the same as having no bucket. Runs at most once per window so the
+// store stays bounded without a background goroutine. s.mu must be held.
+func (s *rateLimitStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < s.window {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= s.window {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// secondsFor returns how long it takes to regain tokens.
+func (s *rateLimitStore) secondsFor(tokens float64) time.Duration {
+	return time.Duration(tokens / s.rate * float64(time.Second))
+}
+
+// ceilSeconds rounds d up to whole seconds for HTTP headers.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
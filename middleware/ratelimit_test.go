@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiterAllowPerKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewRateLimiter(1, time.Minute)
+
+	allow := func(key string) (bool, http.Header) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		return limiter.Allow(c, key), w.Header()
+	}
+
+	if ok, _ := allow("alice"); !ok {
+		t.Fatal("first request for alice rejected")
+	}
+	ok, header := allow("alice")
+	if ok {
+		t.Fatal("second request for alice allowed")
+	}
+	if header.Get("Retry-After") == "" || header.Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("headers = %v, want Retry-After and X-RateLimit-Remaining: 0", header)
+	}
+	if ok, _ := allow("bob"); !ok {
+		t.Error("bob rejected after alice spent the budget")
+	}
+}
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	for _, tt := range []struct {
+		limit  int
+		window time.Duration
+	}{{0, time.Minute}, {10, 0}, {-1, time.Minute}} {
+		limiter := NewRateLimiter(tt.limit, tt.window)
+		if limiter != nil {
+			t.Errorf("NewRateLimiter(%d, %s) = %v, want nil", tt.limit, tt.window, limiter)
+		}
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if !limiter.Allow(c, "alice") {
+			t.Errorf("nil limiter rejected a request")
+		}
+	}
+}
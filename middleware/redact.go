@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in anything that gets logged.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys lists field, header and query names whose values must never be logged.
+// Matching is case-insensitive; names containing "password", "token" or "secret"
+// are always treated as sensitive.
+var sensitiveKeys = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"cookie":              {},
+	"set-cookie":          {},
+	"x-api-key":           {},
+	"api_key":             {},
+	"code":                {},
+	"reset_code":          {},
+	"totp_code":           {},
+	"backup_code":         {},
+}
+
+// AddSensitiveKeys registers additional field/header names to redact
+// (from LOG_REDACT_FIELDS). Must be called during startup, before serving requests.
+func AddSensitiveKeys(keys ...string) {
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "" {
+			sensitiveKeys[key] = struct{}{}
+		}
+	}
+}
+
+// IsSensitiveKey reports whether values stored under key must be redacted.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := sensitiveKeys[key]; ok {
+		return true
+	}
+	return strings.Contains(key, "password") ||
+		strings.Contains(key, "token") ||
+		strings.Contains(key, "secret")
+}
+
+// RedactMap returns a copy of m with sensitive values replaced, recursing into
+// nested maps and slices. Use it before logging any request/response payload.
+func RedactMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for key, value := range m {
+		if IsSensitiveKey(key) {
+			out[key] = RedactedValue
+			continue
+		}
+		out[key] = redactValue(value)
+	}
+	return out
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return RedactMap(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// RedactHeaders flattens headers for logging with sensitive values replaced.
+func RedactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key, values := range h {
+		if IsSensitiveKey(key) {
+			out[key] = RedactedValue
+			continue
+		}
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// RedactQuery returns the raw query string with sensitive parameter values replaced.
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// Unparseable query may still carry secrets - never log it verbatim
+		return RedactedValue
+	}
+	for key := range values {
+		if IsSensitiveKey(key) {
+			values[key] = []string{RedactedValue}
+		}
+	}
+	return values.Encode()
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	testPassword = "hunter2-correct-horse"
+	testBearer   = "Bearer eyJhbGciOiJIUzI1NiJ9.secret-payload.signature"
+)
+
+// captureLogs sends the global logger to a buffer at debug level for the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previousLogger, previousLevel := log.Logger, zerolog.GlobalLevel()
+	log.Logger = zerolog.New(&buf).Level(zerolog.DebugLevel)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() {
+		log.Logger = previousLogger
+		zerolog.SetGlobalLevel(previousLevel)
+	})
+	return &buf
+}
+
+func TestLoggingMiddlewareRedactsCredentials(t *testing.T) {
+	logs := captureLogs(t)
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(LoggingMiddleware())
+	r.POST("/auth/v1/public/login", func(c *gin.Context) {
+		c.Status(http.StatusUnauthorized)
+	})
+
+	body := `{"username":"alice","password":"` + testPassword + `"}`
+	req := httptest.NewRequest(http.MethodPost,
+		"/auth/v1/public/login?password="+testPassword+"&next=%2Fhome", strings.NewReader(body))
+	req.Header.Set("Authorization", testBearer)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := logs.String()
+	if !strings.Contains(out, "HTTP request headers") || !strings.Contains(out, `"path":"/auth/v1/public/login"`) {
+		t.Fatalf("request was not logged: %s", out)
+	}
+	for _, secret := range []string{testPassword, "secret-payload"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log output contains %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, RedactedValue) {
+		t.Errorf("log output has no %s marker: %s", RedactedValue, out)
+	}
+	// Non-sensitive values stay readable
+	if !strings.Contains(out, "next=%2Fhome") {
+		t.Errorf("log output lost the non-sensitive query parameter: %s", out)
+	}
+}
+
+func TestRedactMap(t *testing.T) {
+	payload := map[string]any{
+		"username":      "alice",
+		"password":      testPassword,
+		"Authorization": testBearer,
+		"profile": map[string]any{
+			"new_password": testPassword,
+			"email":        "alice@example.com",
+		},
+		"attempts": []any{map[string]any{"totp_code": "123456"}},
+	}
+
+	got := RedactMap(payload)
+
+	for _, key := range []string{"password", "Authorization"} {
+		if got[key] != RedactedValue {
+			t.Errorf("%s = %v, want %s", key, got[key], RedactedValue)
+		}
+	}
+	profile := got["profile"].(map[string]any)
+	if profile["new_password"] != RedactedValue {
+		t.Errorf("profile.new_password = %v, want %s", profile["new_password"], RedactedValue)
+	}
+	if profile["email"] != "alice@example.com" || got["username"] != "alice" {
+		t.Errorf("non-sensitive values were changed: %v", got)
+	}
+	attempt := got["attempts"].([]any)[0].(map[string]any)
+	if attempt["totp_code"] != RedactedValue {
+		t.Errorf("attempts[0].totp_code = %v, want %s", attempt["totp_code"], RedactedValue)
+	}
+	if payload["password"] != testPassword {
+		t.Error("RedactMap modified its input")
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", testBearer)
+	h.Set("Cookie", "session=abc")
+	h.Set("User-Agent", "curl/8.0")
+
+	got := RedactHeaders(h)
+
+	if got["Authorization"] != RedactedValue || got["Cookie"] != RedactedValue {
+		t.Errorf("credentials not redacted: %v", got)
+	}
+	if got["User-Agent"] != "curl/8.0" {
+		t.Errorf("User-Agent = %q, want it unchanged", got["User-Agent"])
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"verification token", "token=abc123", "token=%5BREDACTED%5D"},
+		{"mixed", "page=2&reset_code=999", "page=2&reset_code=%5BREDACTED%5D"},
+		{"unparseable", "token=%zz", RedactedValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactQuery(tt.query); got != tt.want {
+				t.Errorf("RedactQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+
+	pkgzerolog "github.com/duynhne/pkg/logger/zerolog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// securityEvents counts security-relevant actions (e.g., forced session rotation)
+// so alerting can fire on unusual spikes. event is a small fixed set - never user input.
+var securityEvents = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "security_events_total",
+		Help: "Number of security events emitted by the service",
+	},
+	[]string{"event"},
+)
+
+// RecordSecurityEvent emits a security event through every observability channel:
+// a warn-level log line (for SIEM ingestion), a span event on the current span,
+// and the security_events_total counter.
+// attrs must not carry secrets; they are logged verbatim.
+func RecordSecurityEvent(ctx context.Context, event string, attrs ...attribute.KeyValue) {
+	securityEvents.WithLabelValues(event).Inc()
+
+	trace.SpanFromContext(ctx).AddEvent("security."+event, trace.WithAttributes(attrs...))
+
+	logEvent := pkgzerolog.FromContext(ctx).Warn().Str("security_event", event)
+	for _, kv := range attrs {
+		logEvent = logEvent.Interface(string(kv.Key), kv.Value.AsInterface())
+	}
+	logEvent.Msg("Security event")
+}